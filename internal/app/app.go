@@ -17,7 +17,10 @@ import (
 
 	"github.com/cxbelka/winter_2025/internal/config"
 	"github.com/cxbelka/winter_2025/internal/handlers"
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/password"
 	"github.com/cxbelka/winter_2025/internal/repo"
+	"github.com/cxbelka/winter_2025/internal/token"
 	"github.com/cxbelka/winter_2025/internal/usecase"
 )
 
@@ -31,6 +34,10 @@ type app struct {
 
 	dbConn *pgxpool.Pool
 	mux    *http.ServeMux
+
+	syncRevoked      func(ctx context.Context) error
+	evaluateWishlist func(ctx context.Context) error
+	sweepWishlist    func(ctx context.Context) error
 }
 
 func New() (*app, error) { //nolint:revive
@@ -67,15 +74,79 @@ func New() (*app, error) { //nolint:revive
 		return nil, err //nolint:wrapcheck
 	}
 
+	// ключи подписи JWT читаются из env/файлов
+	if err = token.Reinit(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	hasher, err := password.New(a.cfg.Auth.PasswordHasher)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	authUC := usecase.NewAuth(
+		repo.NewAuth(a.dbConn),
+		repo.NewRefresh(a.dbConn),
+		repo.NewDenylist(a.dbConn),
+		hasher,
+		usecase.NewThrottle(repo.NewThrottle(a.dbConn), usecase.LockoutPolicy{
+			LoginThreshold: a.cfg.Auth.Lockout.LoginThreshold,
+			IPThreshold:    a.cfg.Auth.Lockout.IPThreshold,
+			Backoff:        a.cfg.Auth.Lockout.Backoff,
+			Max:            a.cfg.Auth.Lockout.Max,
+			Window:         a.cfg.Auth.Lockout.Window,
+		}),
+		usecase.CredentialsPolicy{
+			Explicit:   a.cfg.Auth.Registration.Explicit,
+			InviteOnly: a.cfg.Auth.Registration.InviteOnly,
+			AllowList:  a.cfg.Auth.Registration.AllowList,
+			Password: password.Policy{
+				MinLength: a.cfg.Auth.Registration.PasswordMinLength,
+				MaxLength: a.cfg.Auth.Registration.PasswordMaxLength,
+				Classes:   a.cfg.Auth.Registration.PasswordClasses,
+			},
+			ResetTTL: a.cfg.Auth.PasswordResetTTL,
+		},
+	)
+	// отозванные токены должны быть известны до приёма первого запроса
+	a.syncRevoked = authUC.SyncRevoked
+	if err = a.syncRevoked(context.Background()); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if a.cfg.Auth.BootstrapAdmin != "" {
+		err = authUC.BootstrapAdmin(context.Background(), a.cfg.Auth.BootstrapAdmin, a.cfg.Auth.BootstrapAdminPassword)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	// списки желаний проверяются в фоне после операций, меняющих баланс или остатки
+	wishlistUC := usecase.NewWishlist(repo.NewWishlist(a.dbConn), a.cfg.Shop.WishlistQueue)
+	a.evaluateWishlist = wishlistUC.Evaluate
+	a.sweepWishlist = wishlistUC.Sweep
+
 	// создать слой usecase и транспорта вложенными вызовами
 	a.mux = handlers.New(
 		&a.lg,
-		usecase.NewAuth(repo.NewAuth(a.dbConn)),
+		authUC,
 		usecase.NewAccountant(
 			repo.NewBalance(a.dbConn),
 			repo.NewP2p(a.dbConn),
 			repo.NewShop(a.dbConn),
+			wishlistUC,
+			models.TransferLimits{
+				MaxAmount:         a.cfg.Shop.Transfer.MaxAmount,
+				Daily:             a.cfg.Shop.Transfer.Daily,
+				Monthly:           a.cfg.Shop.Transfer.Monthly,
+				DailyCounterparty: a.cfg.Shop.Transfer.DailyCounterparty,
+				HourlyCount:       a.cfg.Shop.Transfer.HourlyCount,
+			},
 		),
+		usecase.NewCatalog(repo.NewCatalog(a.dbConn), wishlistUC),
+		usecase.NewOrders(repo.NewOrders(a.dbConn), wishlistUC, a.cfg.Shop.CancelWindow),
+		usecase.NewPromos(repo.NewPromos(a.dbConn)),
+		wishlistUC,
+		usecase.NewIdempotency(repo.NewIdempotency(a.dbConn), a.cfg.HTTP.IdempotencyTTL),
 	)
 
 	return a, nil
@@ -100,6 +171,23 @@ func (a *app) Run() error {
 		}
 	}()
 
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.watchRevoked()
+	}()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.watchWishlist()
+	}()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.sweepWishlists()
+	}()
+
 	go func() {
 		<-a.ctx.Done()
 
@@ -122,3 +210,50 @@ func (a *app) Run() error {
 
 	return err
 }
+
+// watchRevoked periodically pulls revocations made by other instances.
+func (a *app) watchRevoked() {
+	ticker := time.NewTicker(a.cfg.Auth.DenylistSync)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.syncRevoked(a.ctx); err != nil && a.ctx.Err() == nil {
+				a.lg.Error().Err(err).Msg("denylist sync failed")
+			}
+		}
+	}
+}
+
+// watchWishlist evaluates queued wishlist changes until shutdown.
+func (a *app) watchWishlist() {
+	for {
+		err := a.evaluateWishlist(a.ctx)
+		if a.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			a.lg.Error().Err(err).Msg("wishlist evaluation failed")
+		}
+	}
+}
+
+// sweepWishlists periodically evaluates all wishlists until shutdown.
+func (a *app) sweepWishlists() {
+	ticker := time.NewTicker(a.cfg.Shop.WishlistSweep)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.sweepWishlist(a.ctx); err != nil && a.ctx.Err() == nil {
+				a.lg.Error().Err(err).Msg("wishlist sweep failed")
+			}
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package config
 
+import "time"
+
 type Config struct {
 	LogLevel string `envconfig:"LOG_LEVEL"`
 
 	DB   *DBcfg   `envconfig:"DATABASE"`
 	HTTP *HTTPcfg `envconfig:"SERVER"`
+	Auth *Authcfg `envconfig:"AUTH"`
+	Shop *Shopcfg `envconfig:"SHOP"`
 }
 
 type DBcfg struct {
@@ -17,4 +21,65 @@ type DBcfg struct {
 
 type HTTPcfg struct {
 	Port int `envconfig:"PORT"`
+	// сколько хранится ответ на запрос с Idempotency-Key
+	IdempotencyTTL time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+}
+
+type Shopcfg struct {
+	// сколько после оформления пользователь может сам отменить заказ; администратор может всегда
+	CancelWindow time.Duration `envconfig:"CANCEL_WINDOW" default:"24h"`
+	// сколько изменений балансов и остатков может ждать проверки списков желаний
+	WishlistQueue int `envconfig:"WISHLIST_QUEUE" default:"1024"`
+	// как часто перепроверяются все списки желаний: отброшенные изменения и плановые цены
+	WishlistSweep time.Duration `envconfig:"WISHLIST_SWEEP" default:"10m"`
+
+	Transfer *TransferLimitscfg `envconfig:"TRANSFER"`
+}
+
+// TransferLimitscfg ограничивает переводы монет, 0 - без ограничения.
+// Администратор может переопределить значения для отдельного пользователя.
+type TransferLimitscfg struct {
+	MaxAmount         int `envconfig:"MAX_AMOUNT" default:"0"`         // за один перевод
+	Daily             int `envconfig:"DAILY" default:"0"`              // за календарный день
+	Monthly           int `envconfig:"MONTHLY" default:"0"`            // за календарный месяц
+	DailyCounterparty int `envconfig:"COUNTERPARTY_DAILY" default:"0"` // одному получателю за день
+	HourlyCount       int `envconfig:"HOURLY_COUNT" default:"0"`       // число переводов за последний час
+}
+
+type Authcfg struct {
+	// алгоритм хеширования новых паролей: argon2id или bcrypt
+	PasswordHasher string `envconfig:"PASSWORD_HASHER" default:"argon2id"`
+	// период загрузки отозванных токенов из БД (отзывы с других инстансов)
+	DenylistSync time.Duration `envconfig:"DENYLIST_SYNC" default:"10s"`
+	// время жизни кода сброса пароля, выданного администратором
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"24h"`
+
+	// первый администратор создаётся при старте, если такого пользователя ещё нет
+	BootstrapAdmin         string `envconfig:"BOOTSTRAP_ADMIN"`
+	BootstrapAdminPassword string `envconfig:"BOOTSTRAP_ADMIN_PASSWORD"`
+
+	Lockout      *Lockoutcfg      `envconfig:"LOCKOUT"`
+	Registration *Registrationcfg `envconfig:"REGISTRATION"`
+}
+
+// Registrationcfg: при Explicit пользователи создаются только через /api/register,
+// вход неизвестного пользователя возвращает 401.
+type Registrationcfg struct {
+	Explicit   bool `envconfig:"EXPLICIT" default:"false"`
+	InviteOnly bool `envconfig:"INVITE_ONLY" default:"false"` // нужен код приглашения
+	AllowList  bool `envconfig:"ALLOW_LIST" default:"false"`  // только логины из списка HR
+
+	PasswordMinLength int `envconfig:"PASSWORD_MIN_LENGTH" default:"8"`
+	PasswordMaxLength int `envconfig:"PASSWORD_MAX_LENGTH" default:"72"`
+	PasswordClasses   int `envconfig:"PASSWORD_CLASSES" default:"2"`
+}
+
+// Lockoutcfg ограничивает подбор пароля: после Threshold неудачных попыток
+// за Window вход блокируется на Backoff, каждая следующая ошибка удваивает блокировку до Max.
+type Lockoutcfg struct {
+	LoginThreshold int           `envconfig:"LOGIN_THRESHOLD" default:"5"`
+	IPThreshold    int           `envconfig:"IP_THRESHOLD" default:"50"`
+	Backoff        time.Duration `envconfig:"BACKOFF" default:"1s"`
+	Max            time.Duration `envconfig:"MAX" default:"15m"`
+	Window         time.Duration `envconfig:"WINDOW" default:"15m"`
 }
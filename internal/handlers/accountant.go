@@ -3,6 +3,8 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cxbelka/winter_2025/internal/logger"
 	"github.com/cxbelka/winter_2025/internal/models"
@@ -15,14 +17,14 @@ func (h *handle) handleBuy(w http.ResponseWriter, r *http.Request) {
 	user := token.UserFromContext(r.Context())
 	logger.AddField(r.Context(), "item", item)
 
-	if err := h.acc.Buy(r.Context(), user, item); err != nil {
+	if err := h.acc.Buy(r.Context(), user, item, r.URL.Query().Get("promo")); err != nil {
 		handleError(r.Context(), w, err)
 	}
 }
 
 func (h *handle) handleTransfer(w http.ResponseWriter, r *http.Request) {
 	from := token.UserFromContext(r.Context())
-	rq := &models.SentTransfer{}
+	rq := &models.SendCoinRequest{}
 	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
 		handleError(r.Context(), w, err)
 
@@ -42,11 +44,79 @@ func (h *handle) handleTransfer(w http.ResponseWriter, r *http.Request) {
 
 		return
 	}
-	if err := h.acc.Transfer(r.Context(), from, rq.To, rq.Amount); err != nil {
+	if err := h.acc.Transfer(r.Context(), from, rq.To, rq.Amount, rq.Memo); err != nil {
 		handleError(r.Context(), w, err)
 	}
 }
 
+func (h *handle) handleTransfers(w http.ResponseWriter, r *http.Request) {
+	f, err := h.historyFilter(r)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	page, err := h.acc.Transfers(r.Context(), token.UserFromContext(r.Context()), f)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err = json.NewEncoder(w).Encode(page); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handlePurchases(w http.ResponseWriter, r *http.Request) {
+	f, err := h.historyFilter(r)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	page, err := h.acc.Purchases(r.Context(), token.UserFromContext(r.Context()), f)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err = json.NewEncoder(w).Encode(page); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+// historyFilter reads ?from=&to=&counterparty=&item=&limit=&cursor=. Dates are RFC 3339
+// or YYYY-MM-DD in UTC, a date in `to` includes the whole day.
+func (h *handle) historyFilter(r *http.Request) (*models.HistoryFilter, error) {
+	q := r.URL.Query()
+	f := &models.HistoryFilter{Counterparty: q.Get("counterparty"), Item: q.Get("item"), Cursor: q.Get("cursor")}
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, models.ErrNoRows
+		}
+		f.Limit = n
+	}
+	for param, v := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		s := q.Get(param)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, s); err != nil {
+				return nil, models.ErrNoRows
+			}
+			if param == "to" {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		*v = &t
+	}
+
+	return f, h.validate.Struct(f) //nolint:wrapcheck
+}
+
 func (h *handle) handleInfo(w http.ResponseWriter, r *http.Request) {
 	user := token.UserFromContext(r.Context())
 	info, err := h.acc.Info(r.Context(), user)
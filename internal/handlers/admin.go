@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func (h *handle) handleGrantRole(w http.ResponseWriter, r *http.Request) {
+	login, role := r.PathValue("login"), r.PathValue("role")
+	logger.AddField(r.Context(), "target", login)
+	logger.AddField(r.Context(), "role", role)
+
+	if err := h.auth.GrantRole(r.Context(), login, role); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRevokeRole(w http.ResponseWriter, r *http.Request) {
+	login, role := r.PathValue("login"), r.PathValue("role")
+	logger.AddField(r.Context(), "target", login)
+	logger.AddField(r.Context(), "role", role)
+
+	if err := h.auth.RevokeRole(r.Context(), login, role); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	if err := h.auth.RevokeSessions(r.Context(), login); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleDeactivate(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	if err := h.auth.Deactivate(r.Context(), login); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleReactivate(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	if err := h.auth.Reactivate(r.Context(), login); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleIssueReset(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	resp, err := h.auth.IssueReset(r.Context(), login)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleTransferLimits(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	limits, err := h.acc.TransferLimits(r.Context(), login)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(limits); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleSetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	rq := &models.TransferLimitsOverride{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	limits, err := h.acc.SetTransferLimits(r.Context(), login, rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(limits); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleResetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	login := r.PathValue("login")
+	logger.AddField(r.Context(), "target", login)
+
+	if err := h.acc.ResetTransferLimits(r.Context(), login); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
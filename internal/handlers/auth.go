@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 
 	"github.com/cxbelka/winter_2025/internal/logger"
 	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/token"
 )
 
 func (h *handle) handleAuth(w http.ResponseWriter, r *http.Request) {
@@ -22,6 +26,7 @@ func (h *handle) handleAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logger.AddField(r.Context(), "login", rq.Username)
+	rq.ClientIP = clientIP(r)
 
 	resp, err := h.auth.Authorize(r.Context(), rq)
 	if err != nil {
@@ -34,3 +39,125 @@ func (h *handle) handleAuth(w http.ResponseWriter, r *http.Request) {
 		logger.AddError(r.Context(), err)
 	}
 }
+
+func (h *handle) handleRegister(w http.ResponseWriter, r *http.Request) {
+	rq := &models.RegisterRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	logger.AddField(r.Context(), "login", rq.Username)
+	rq.ClientIP = clientIP(r)
+
+	resp, err := h.auth.Register(r.Context(), rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	rq := &models.RefreshRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	resp, err := h.auth.Refresh(r.Context(), rq.RefreshToken)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleLogout(w http.ResponseWriter, r *http.Request) {
+	rq := &models.LogoutRequest{}
+	// тело необязательное
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil && !errors.Is(err, io.EOF) {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err := h.auth.Logout(r.Context(), rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	rq := &models.ChangePasswordRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	rq.ClientIP = clientIP(r)
+
+	if err := h.auth.ChangePassword(r.Context(), rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	rq := &models.ResetPasswordRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err := h.auth.ResetPassword(r.Context(), rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(token.PublicKeys()); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+// clientIP returns remote address without port. Requests are expected to come
+// directly or through a proxy that rewrites RemoteAddr, forwarded headers are not trusted.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func (h *handle) handleListItems(w http.ResponseWriter, r *http.Request) {
+	h.listItems(w, r, false)
+}
+
+func (h *handle) handleAdminListItems(w http.ResponseWriter, r *http.Request) {
+	h.listItems(w, r, true)
+}
+
+func (h *handle) listItems(w http.ResponseWriter, r *http.Request, retired bool) {
+	f, err := itemFilter(r)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err = h.validate.Struct(f); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	items, err := h.catalog.ListItems(r.Context(), retired, f)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(items); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+// itemFilter reads ?category=&q=&sort=&limit=&offset=.
+func itemFilter(r *http.Request) (*models.ItemFilter, error) {
+	q := r.URL.Query()
+	f := &models.ItemFilter{Category: q.Get("category"), Query: q.Get("q"), Sort: q.Get("sort")}
+	for param, v := range map[string]*int{"limit": &f.Limit, "offset": &f.Offset} {
+		if s := q.Get(param); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, models.ErrNoRows
+			}
+			*v = n
+		}
+	}
+
+	return f, nil
+}
+
+func (h *handle) handleCreateItem(w http.ResponseWriter, r *http.Request) {
+	rq := &models.CreateItemRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	logger.AddField(r.Context(), "item", rq.Name)
+
+	if err := h.catalog.CreateItem(r.Context(), rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *handle) handleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	product := r.PathValue("product")
+	logger.AddField(r.Context(), "product", product)
+
+	rq := &models.ProductRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err := h.catalog.UpdateProduct(r.Context(), product, rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleSetPrice(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	rq := &models.ItemPriceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err := h.catalog.SetPrice(r.Context(), item, *rq.Price); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleListPrices(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	prices, err := h.catalog.Prices(r.Context(), item)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(prices); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleSchedulePrice(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	rq := &models.SchedulePriceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	price, err := h.catalog.SchedulePrice(r.Context(), item, rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(price); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleCancelPrice(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		handleError(r.Context(), w, models.ErrPriceNotFound)
+
+		return
+	}
+
+	if err = h.catalog.CancelPrice(r.Context(), item, id); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleSetStock(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	rq := &models.ItemStockRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err := h.catalog.SetStock(r.Context(), item, rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRetireItem(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	if err := h.catalog.Retire(r.Context(), item); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRestoreItem(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	if err := h.catalog.Restore(r.Context(), item); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 
@@ -18,11 +20,38 @@ type handlerError struct {
 }
 
 var (
-	errInvalidRequest = handlerError{code: http.StatusBadRequest, Status: "Bad request"}
-	errGeneric        = handlerError{code: http.StatusInternalServerError, Status: "Internal server error"}
-	errUnauthorized   = handlerError{code: http.StatusUnauthorized, Status: "Unauthorized"}
-	errBadRequest     = handlerError{code: http.StatusBadRequest, Status: "Bad request"}
-	errNoEnoughMoney  = handlerError{code: http.StatusBadRequest, Status: "Not enough coins"}
+	errInvalidRequest  = handlerError{code: http.StatusBadRequest, Status: "Bad request"}
+	errGeneric         = handlerError{code: http.StatusInternalServerError, Status: "Internal server error"}
+	errUnauthorized    = handlerError{code: http.StatusUnauthorized, Status: "Unauthorized"}
+	errBadRequest      = handlerError{code: http.StatusBadRequest, Status: "Bad request"}
+	errNoEnoughMoney   = handlerError{code: http.StatusBadRequest, Status: "Not enough coins"}
+	errTooManyRequests = handlerError{code: http.StatusTooManyRequests, Status: "Too many requests"}
+	errWeakPassword    = handlerError{code: http.StatusBadRequest, Status: "Password does not meet policy"}
+	errUserExists      = handlerError{code: http.StatusConflict, Status: "User already exists"}
+	errForbidden       = handlerError{code: http.StatusForbidden, Status: "Forbidden"}
+	errDeactivated     = handlerError{code: http.StatusForbidden, Status: "Account is deactivated"}
+	errInvalidOTP      = handlerError{code: http.StatusUnauthorized, Status: "Invalid code"}
+	errMFAEnabled      = handlerError{code: http.StatusConflict, Status: "Two-factor authentication already enabled"}
+	errItemExists      = handlerError{code: http.StatusConflict, Status: "Item already exists"}
+	errItemNotFound    = handlerError{code: http.StatusBadRequest, Status: "Item not found"}
+	errItemUnavailable = handlerError{code: http.StatusBadRequest, Status: "Item is not available"}
+	errOutOfStock      = handlerError{code: http.StatusConflict, Status: "Item is out of stock"}
+	errPurchaseLimit   = handlerError{code: http.StatusConflict, Status: "Purchase limit reached"}
+	errInvalidSchedule = handlerError{code: http.StatusBadRequest, Status: "Invalid price schedule"}
+	errPriceNotFound   = handlerError{code: http.StatusBadRequest, Status: "Scheduled price not found"}
+	errCartEmpty       = handlerError{code: http.StatusBadRequest, Status: "Cart is empty"}
+	errOrderNotFound   = handlerError{code: http.StatusNotFound, Status: "Order not found"}
+	errOrderStatus     = handlerError{code: http.StatusConflict, Status: "Order status does not allow this"}
+	errCancelExpired   = handlerError{code: http.StatusConflict, Status: "Cancellation window has expired"}
+	errPromoExists     = handlerError{code: http.StatusConflict, Status: "Promo code already exists"}
+	errInvalidPromo    = handlerError{code: http.StatusBadRequest, Status: "Invalid promo code"}
+	errPromoExhausted  = handlerError{code: http.StatusConflict, Status: "Promo code limit reached"}
+	errLimitExceeded   = handlerError{code: http.StatusForbidden, Status: "Transfer limit exceeded"}
+
+	errInvalidCursor       = handlerError{code: http.StatusBadRequest, Status: "Invalid cursor"}
+	errIdempotencyMismatch = handlerError{
+		code: http.StatusUnprocessableEntity, Status: "Idempotency key was used for another request",
+	}
 )
 
 func handleError(ctx context.Context, w http.ResponseWriter, err error) {
@@ -34,17 +63,87 @@ func handleError(ctx context.Context, w http.ResponseWriter, err error) {
 		e = errInvalidRequest
 	case errors.Is(err, models.ErrNoMoney):
 		e = errNoEnoughMoney
+	case errors.Is(err, models.ErrLimitExceeded):
+		e = errLimitExceeded
+	case errors.Is(err, models.ErrWeakPassword):
+		e = errWeakPassword
+	case errors.Is(err, models.ErrUserExists):
+		e = errUserExists
+	case errors.Is(err, models.ErrRegistrationDenied):
+		e = errForbidden
+	case errors.Is(err, models.ErrForbidden):
+		e = errForbidden
+	case errors.Is(err, models.ErrUnknownRole):
+		e = errBadRequest
+	case errors.Is(err, models.ErrUserDeactivated):
+		e = errDeactivated
+	case errors.Is(err, models.ErrInvalidOTP):
+		e = errInvalidOTP
+	case errors.Is(err, models.ErrMFAEnabled):
+		e = errMFAEnabled
+	case errors.Is(err, models.ErrItemExists):
+		e = errItemExists
+	case errors.Is(err, models.ErrItemNotFound):
+		e = errItemNotFound
+	case errors.Is(err, models.ErrItemUnavailable):
+		e = errItemUnavailable
+	case errors.Is(err, models.ErrOutOfStock):
+		e = errOutOfStock
+	case errors.Is(err, models.ErrPurchaseLimit):
+		e = errPurchaseLimit
+	case errors.Is(err, models.ErrInvalidSchedule):
+		e = errInvalidSchedule
+	case errors.Is(err, models.ErrPriceNotFound):
+		e = errPriceNotFound
+	case errors.Is(err, models.ErrCartEmpty):
+		e = errCartEmpty
+	case errors.Is(err, models.ErrOrderNotFound):
+		e = errOrderNotFound
+	case errors.Is(err, models.ErrOrderStatus):
+		e = errOrderStatus
+	case errors.Is(err, models.ErrCancelExpired):
+		e = errCancelExpired
+	case errors.Is(err, models.ErrPromoExists):
+		e = errPromoExists
+	case errors.Is(err, models.ErrInvalidPromo):
+		e = errInvalidPromo
+	case errors.Is(err, models.ErrPromoExhausted):
+		e = errPromoExhausted
+	case errors.Is(err, models.ErrIdempotencyMismatch):
+		e = errIdempotencyMismatch
+	case errors.Is(err, models.ErrInvalidCursor):
+		e = errInvalidCursor
 	case errors.Is(err, models.ErrGeneric):
 		e = errGeneric
 	case errors.Is(err, models.ErrInvalidPassword):
 		e = errUnauthorized
+	case errors.Is(err, models.ErrInvalidToken):
+		e = errUnauthorized
 	case errors.Is(err, models.ErrNoRows):
 		e = errBadRequest
+	case errors.Is(err, models.ErrTooManyAttempts):
+		e = errTooManyRequests
 
 	default:
 		e = errGeneric
 	}
 
+	// в заказе из нескольких строк клиенту нужно знать, какая не прошла
+	var line *models.LineError
+	if errors.As(err, &line) && e != errGeneric {
+		e.Status += ": " + line.Item
+	}
+
+	// клиенту нужно знать, какое ограничение перевода сработало
+	var limit *models.LimitError
+	if errors.As(err, &limit) {
+		e.Status += ": " + limit.Limit
+	}
+
+	var retry *models.RetryAfterError
+	if errors.As(err, &retry) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.After.Seconds()))))
+	}
 	w.WriteHeader(e.code)
 	if err = json.NewEncoder(w).Encode(e); err != nil {
 		logger.AddError(ctx, err)
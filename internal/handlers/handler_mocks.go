@@ -56,6 +56,222 @@ func (mr *MockauthUsecaseMockRecorder) Authorize(ctx, rq any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockauthUsecase)(nil).Authorize), ctx, rq)
 }
 
+// ChangePassword mocks base method.
+func (m *MockauthUsecase) ChangePassword(ctx context.Context, rq *models.ChangePasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockauthUsecaseMockRecorder) ChangePassword(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockauthUsecase)(nil).ChangePassword), ctx, rq)
+}
+
+// ConfirmMFA mocks base method.
+func (m *MockauthUsecase) ConfirmMFA(ctx context.Context, rq *models.MFACodeRequest) (*models.MFARecoveryCodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", ctx, rq)
+	ret0, _ := ret[0].(*models.MFARecoveryCodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockauthUsecaseMockRecorder) ConfirmMFA(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockauthUsecase)(nil).ConfirmMFA), ctx, rq)
+}
+
+// Deactivate mocks base method.
+func (m *MockauthUsecase) Deactivate(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockauthUsecaseMockRecorder) Deactivate(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockauthUsecase)(nil).Deactivate), ctx, login)
+}
+
+// DisableMFA mocks base method.
+func (m *MockauthUsecase) DisableMFA(ctx context.Context, rq *models.MFACodeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableMFA", ctx, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableMFA indicates an expected call of DisableMFA.
+func (mr *MockauthUsecaseMockRecorder) DisableMFA(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableMFA", reflect.TypeOf((*MockauthUsecase)(nil).DisableMFA), ctx, rq)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockauthUsecase) EnrollMFA(ctx context.Context) (*models.MFAEnrollResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", ctx)
+	ret0, _ := ret[0].(*models.MFAEnrollResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockauthUsecaseMockRecorder) EnrollMFA(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockauthUsecase)(nil).EnrollMFA), ctx)
+}
+
+// GrantRole mocks base method.
+func (m *MockauthUsecase) GrantRole(ctx context.Context, login, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockauthUsecaseMockRecorder) GrantRole(ctx, login, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockauthUsecase)(nil).GrantRole), ctx, login, role)
+}
+
+// IssueReset mocks base method.
+func (m *MockauthUsecase) IssueReset(ctx context.Context, login string) (*models.PasswordResetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueReset", ctx, login)
+	ret0, _ := ret[0].(*models.PasswordResetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueReset indicates an expected call of IssueReset.
+func (mr *MockauthUsecaseMockRecorder) IssueReset(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueReset", reflect.TypeOf((*MockauthUsecase)(nil).IssueReset), ctx, login)
+}
+
+// Logout mocks base method.
+func (m *MockauthUsecase) Logout(ctx context.Context, rq *models.LogoutRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockauthUsecaseMockRecorder) Logout(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockauthUsecase)(nil).Logout), ctx, rq)
+}
+
+// Reactivate mocks base method.
+func (m *MockauthUsecase) Reactivate(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockauthUsecaseMockRecorder) Reactivate(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockauthUsecase)(nil).Reactivate), ctx, login)
+}
+
+// Refresh mocks base method.
+func (m *MockauthUsecase) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockauthUsecaseMockRecorder) Refresh(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockauthUsecase)(nil).Refresh), ctx, refreshToken)
+}
+
+// Register mocks base method.
+func (m *MockauthUsecase) Register(ctx context.Context, rq *models.RegisterRequest) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, rq)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockauthUsecaseMockRecorder) Register(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockauthUsecase)(nil).Register), ctx, rq)
+}
+
+// ResetPassword mocks base method.
+func (m *MockauthUsecase) ResetPassword(ctx context.Context, rq *models.ResetPasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockauthUsecaseMockRecorder) ResetPassword(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockauthUsecase)(nil).ResetPassword), ctx, rq)
+}
+
+// RevokeRole mocks base method.
+func (m *MockauthUsecase) RevokeRole(ctx context.Context, login, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockauthUsecaseMockRecorder) RevokeRole(ctx, login, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockauthUsecase)(nil).RevokeRole), ctx, login, role)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockauthUsecase) RevokeSessions(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockauthUsecaseMockRecorder) RevokeSessions(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockauthUsecase)(nil).RevokeSessions), ctx, login)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockauthUsecase) VerifyMFA(ctx context.Context, rq *models.MFAVerifyRequest) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, rq)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockauthUsecaseMockRecorder) VerifyMFA(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockauthUsecase)(nil).VerifyMFA), ctx, rq)
+}
+
 // MockaccountantUsecase is a mock of accountantUsecase interface.
 type MockaccountantUsecase struct {
 	ctrl     *gomock.Controller
@@ -81,17 +297,17 @@ func (m *MockaccountantUsecase) EXPECT() *MockaccountantUsecaseMockRecorder {
 }
 
 // Buy mocks base method.
-func (m *MockaccountantUsecase) Buy(ctx context.Context, user, item string) error {
+func (m *MockaccountantUsecase) Buy(ctx context.Context, user, item, promo string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Buy", ctx, user, item)
+	ret := m.ctrl.Call(m, "Buy", ctx, user, item, promo)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Buy indicates an expected call of Buy.
-func (mr *MockaccountantUsecaseMockRecorder) Buy(ctx, user, item any) *gomock.Call {
+func (mr *MockaccountantUsecaseMockRecorder) Buy(ctx, user, item, promo any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buy", reflect.TypeOf((*MockaccountantUsecase)(nil).Buy), ctx, user, item)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buy", reflect.TypeOf((*MockaccountantUsecase)(nil).Buy), ctx, user, item, promo)
 }
 
 // Info mocks base method.
@@ -109,16 +325,648 @@ func (mr *MockaccountantUsecaseMockRecorder) Info(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockaccountantUsecase)(nil).Info), ctx, user)
 }
 
+// Purchases mocks base method.
+func (m *MockaccountantUsecase) Purchases(ctx context.Context, user string, f *models.HistoryFilter) (*models.PurchasePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purchases", ctx, user, f)
+	ret0, _ := ret[0].(*models.PurchasePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purchases indicates an expected call of Purchases.
+func (mr *MockaccountantUsecaseMockRecorder) Purchases(ctx, user, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purchases", reflect.TypeOf((*MockaccountantUsecase)(nil).Purchases), ctx, user, f)
+}
+
+// ResetTransferLimits mocks base method.
+func (m *MockaccountantUsecase) ResetTransferLimits(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTransferLimits", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetTransferLimits indicates an expected call of ResetTransferLimits.
+func (mr *MockaccountantUsecaseMockRecorder) ResetTransferLimits(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTransferLimits", reflect.TypeOf((*MockaccountantUsecase)(nil).ResetTransferLimits), ctx, login)
+}
+
+// SetTransferLimits mocks base method.
+func (m *MockaccountantUsecase) SetTransferLimits(ctx context.Context, login string, o *models.TransferLimitsOverride) (*models.TransferLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferLimits", ctx, login, o)
+	ret0, _ := ret[0].(*models.TransferLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransferLimits indicates an expected call of SetTransferLimits.
+func (mr *MockaccountantUsecaseMockRecorder) SetTransferLimits(ctx, login, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferLimits", reflect.TypeOf((*MockaccountantUsecase)(nil).SetTransferLimits), ctx, login, o)
+}
+
 // Transfer mocks base method.
-func (m *MockaccountantUsecase) Transfer(ctx context.Context, from, to string, amount int) error {
+func (m *MockaccountantUsecase) Transfer(ctx context.Context, from, to string, amount int, memo string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", ctx, from, to, amount)
+	ret := m.ctrl.Call(m, "Transfer", ctx, from, to, amount, memo)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Transfer indicates an expected call of Transfer.
-func (mr *MockaccountantUsecaseMockRecorder) Transfer(ctx, from, to, amount any) *gomock.Call {
+func (mr *MockaccountantUsecaseMockRecorder) Transfer(ctx, from, to, amount, memo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockaccountantUsecase)(nil).Transfer), ctx, from, to, amount, memo)
+}
+
+// TransferLimits mocks base method.
+func (m *MockaccountantUsecase) TransferLimits(ctx context.Context, login string) (*models.TransferLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferLimits", ctx, login)
+	ret0, _ := ret[0].(*models.TransferLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferLimits indicates an expected call of TransferLimits.
+func (mr *MockaccountantUsecaseMockRecorder) TransferLimits(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLimits", reflect.TypeOf((*MockaccountantUsecase)(nil).TransferLimits), ctx, login)
+}
+
+// Transfers mocks base method.
+func (m *MockaccountantUsecase) Transfers(ctx context.Context, user string, f *models.HistoryFilter) (*models.TransferPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfers", ctx, user, f)
+	ret0, _ := ret[0].(*models.TransferPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfers indicates an expected call of Transfers.
+func (mr *MockaccountantUsecaseMockRecorder) Transfers(ctx, user, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfers", reflect.TypeOf((*MockaccountantUsecase)(nil).Transfers), ctx, user, f)
+}
+
+// MockcatalogUsecase is a mock of catalogUsecase interface.
+type MockcatalogUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockcatalogUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockcatalogUsecaseMockRecorder is the mock recorder for MockcatalogUsecase.
+type MockcatalogUsecaseMockRecorder struct {
+	mock *MockcatalogUsecase
+}
+
+// NewMockcatalogUsecase creates a new mock instance.
+func NewMockcatalogUsecase(ctrl *gomock.Controller) *MockcatalogUsecase {
+	mock := &MockcatalogUsecase{ctrl: ctrl}
+	mock.recorder = &MockcatalogUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcatalogUsecase) EXPECT() *MockcatalogUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CancelPrice mocks base method.
+func (m *MockcatalogUsecase) CancelPrice(ctx context.Context, name string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPrice", ctx, name, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPrice indicates an expected call of CancelPrice.
+func (mr *MockcatalogUsecaseMockRecorder) CancelPrice(ctx, name, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPrice", reflect.TypeOf((*MockcatalogUsecase)(nil).CancelPrice), ctx, name, id)
+}
+
+// CreateItem mocks base method.
+func (m *MockcatalogUsecase) CreateItem(ctx context.Context, rq *models.CreateItemRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItem", ctx, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateItem indicates an expected call of CreateItem.
+func (mr *MockcatalogUsecaseMockRecorder) CreateItem(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockcatalogUsecase)(nil).CreateItem), ctx, rq)
+}
+
+// ListItems mocks base method.
+func (m *MockcatalogUsecase) ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, retired, f)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockcatalogUsecaseMockRecorder) ListItems(ctx, retired, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockcatalogUsecase)(nil).ListItems), ctx, retired, f)
+}
+
+// Prices mocks base method.
+func (m *MockcatalogUsecase) Prices(ctx context.Context, name string) ([]models.ItemPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prices", ctx, name)
+	ret0, _ := ret[0].([]models.ItemPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prices indicates an expected call of Prices.
+func (mr *MockcatalogUsecaseMockRecorder) Prices(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prices", reflect.TypeOf((*MockcatalogUsecase)(nil).Prices), ctx, name)
+}
+
+// Restore mocks base method.
+func (m *MockcatalogUsecase) Restore(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockcatalogUsecaseMockRecorder) Restore(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockcatalogUsecase)(nil).Restore), ctx, name)
+}
+
+// Retire mocks base method.
+func (m *MockcatalogUsecase) Retire(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retire", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retire indicates an expected call of Retire.
+func (mr *MockcatalogUsecaseMockRecorder) Retire(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retire", reflect.TypeOf((*MockcatalogUsecase)(nil).Retire), ctx, name)
+}
+
+// SchedulePrice mocks base method.
+func (m *MockcatalogUsecase) SchedulePrice(ctx context.Context, name string, rq *models.SchedulePriceRequest) (*models.ItemPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePrice", ctx, name, rq)
+	ret0, _ := ret[0].(*models.ItemPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchedulePrice indicates an expected call of SchedulePrice.
+func (mr *MockcatalogUsecaseMockRecorder) SchedulePrice(ctx, name, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePrice", reflect.TypeOf((*MockcatalogUsecase)(nil).SchedulePrice), ctx, name, rq)
+}
+
+// SetPrice mocks base method.
+func (m *MockcatalogUsecase) SetPrice(ctx context.Context, name string, price int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrice", ctx, name, price)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrice indicates an expected call of SetPrice.
+func (mr *MockcatalogUsecaseMockRecorder) SetPrice(ctx, name, price any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrice", reflect.TypeOf((*MockcatalogUsecase)(nil).SetPrice), ctx, name, price)
+}
+
+// SetStock mocks base method.
+func (m *MockcatalogUsecase) SetStock(ctx context.Context, name string, rq *models.ItemStockRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStock", ctx, name, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStock indicates an expected call of SetStock.
+func (mr *MockcatalogUsecaseMockRecorder) SetStock(ctx, name, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStock", reflect.TypeOf((*MockcatalogUsecase)(nil).SetStock), ctx, name, rq)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockcatalogUsecase) UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, name, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockcatalogUsecaseMockRecorder) UpdateProduct(ctx, name, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockcatalogUsecase)(nil).UpdateProduct), ctx, name, rq)
+}
+
+// MockordersUsecase is a mock of ordersUsecase interface.
+type MockordersUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockordersUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockordersUsecaseMockRecorder is the mock recorder for MockordersUsecase.
+type MockordersUsecaseMockRecorder struct {
+	mock *MockordersUsecase
+}
+
+// NewMockordersUsecase creates a new mock instance.
+func NewMockordersUsecase(ctrl *gomock.Controller) *MockordersUsecase {
+	mock := &MockordersUsecase{ctrl: ctrl}
+	mock.recorder = &MockordersUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockordersUsecase) EXPECT() *MockordersUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AddToCart mocks base method.
+func (m *MockordersUsecase) AddToCart(ctx context.Context, user string, line *models.OrderLine) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToCart", ctx, user, line)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToCart indicates an expected call of AddToCart.
+func (mr *MockordersUsecaseMockRecorder) AddToCart(ctx, user, line any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToCart", reflect.TypeOf((*MockordersUsecase)(nil).AddToCart), ctx, user, line)
+}
+
+// AdminCancel mocks base method.
+func (m *MockordersUsecase) AdminCancel(ctx context.Context, id int64) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminCancel", ctx, id)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminCancel indicates an expected call of AdminCancel.
+func (mr *MockordersUsecaseMockRecorder) AdminCancel(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminCancel", reflect.TypeOf((*MockordersUsecase)(nil).AdminCancel), ctx, id)
+}
+
+// AllOrders mocks base method.
+func (m *MockordersUsecase) AllOrders(ctx context.Context, status string) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllOrders", ctx, status)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllOrders indicates an expected call of AllOrders.
+func (mr *MockordersUsecaseMockRecorder) AllOrders(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllOrders", reflect.TypeOf((*MockordersUsecase)(nil).AllOrders), ctx, status)
+}
+
+// Cancel mocks base method.
+func (m *MockordersUsecase) Cancel(ctx context.Context, user string, id int64) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, user, id)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockordersUsecaseMockRecorder) Cancel(ctx, user, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockordersUsecase)(nil).Cancel), ctx, user, id)
+}
+
+// Cart mocks base method.
+func (m *MockordersUsecase) Cart(ctx context.Context, user string) (*models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cart", ctx, user)
+	ret0, _ := ret[0].(*models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cart indicates an expected call of Cart.
+func (mr *MockordersUsecaseMockRecorder) Cart(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cart", reflect.TypeOf((*MockordersUsecase)(nil).Cart), ctx, user)
+}
+
+// Checkout mocks base method.
+func (m *MockordersUsecase) Checkout(ctx context.Context, user, promo string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, user, promo)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockordersUsecaseMockRecorder) Checkout(ctx, user, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockordersUsecase)(nil).Checkout), ctx, user, promo)
+}
+
+// Gift mocks base method.
+func (m *MockordersUsecase) Gift(ctx context.Context, user string, rq *models.GiftRequest) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Gift", ctx, user, rq)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Gift indicates an expected call of Gift.
+func (mr *MockordersUsecaseMockRecorder) Gift(ctx, user, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gift", reflect.TypeOf((*MockordersUsecase)(nil).Gift), ctx, user, rq)
+}
+
+// ListOrders mocks base method.
+func (m *MockordersUsecase) ListOrders(ctx context.Context, user string) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, user)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockordersUsecaseMockRecorder) ListOrders(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockordersUsecase)(nil).ListOrders), ctx, user)
+}
+
+// PlaceOrder mocks base method.
+func (m *MockordersUsecase) PlaceOrder(ctx context.Context, user string, rq *models.OrderRequest) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceOrder", ctx, user, rq)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceOrder indicates an expected call of PlaceOrder.
+func (mr *MockordersUsecaseMockRecorder) PlaceOrder(ctx, user, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceOrder", reflect.TypeOf((*MockordersUsecase)(nil).PlaceOrder), ctx, user, rq)
+}
+
+// RemoveFromCart mocks base method.
+func (m *MockordersUsecase) RemoveFromCart(ctx context.Context, user, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromCart", ctx, user, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromCart indicates an expected call of RemoveFromCart.
+func (mr *MockordersUsecaseMockRecorder) RemoveFromCart(ctx, user, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromCart", reflect.TypeOf((*MockordersUsecase)(nil).RemoveFromCart), ctx, user, item)
+}
+
+// SetStatus mocks base method.
+func (m *MockordersUsecase) SetStatus(ctx context.Context, id int64, status string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, id, status)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockordersUsecaseMockRecorder) SetStatus(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockordersUsecase)(nil).SetStatus), ctx, id, status)
+}
+
+// MockpromosUsecase is a mock of promosUsecase interface.
+type MockpromosUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockpromosUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockpromosUsecaseMockRecorder is the mock recorder for MockpromosUsecase.
+type MockpromosUsecaseMockRecorder struct {
+	mock *MockpromosUsecase
+}
+
+// NewMockpromosUsecase creates a new mock instance.
+func NewMockpromosUsecase(ctrl *gomock.Controller) *MockpromosUsecase {
+	mock := &MockpromosUsecase{ctrl: ctrl}
+	mock.recorder = &MockpromosUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpromosUsecase) EXPECT() *MockpromosUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreatePromo mocks base method.
+func (m *MockpromosUsecase) CreatePromo(ctx context.Context, rq *models.CreatePromoRequest) (*models.Promo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromo", ctx, rq)
+	ret0, _ := ret[0].(*models.Promo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromo indicates an expected call of CreatePromo.
+func (mr *MockpromosUsecaseMockRecorder) CreatePromo(ctx, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromo", reflect.TypeOf((*MockpromosUsecase)(nil).CreatePromo), ctx, rq)
+}
+
+// DisablePromo mocks base method.
+func (m *MockpromosUsecase) DisablePromo(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisablePromo", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisablePromo indicates an expected call of DisablePromo.
+func (mr *MockpromosUsecaseMockRecorder) DisablePromo(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisablePromo", reflect.TypeOf((*MockpromosUsecase)(nil).DisablePromo), ctx, code)
+}
+
+// ListPromos mocks base method.
+func (m *MockpromosUsecase) ListPromos(ctx context.Context) ([]models.Promo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromos", ctx)
+	ret0, _ := ret[0].([]models.Promo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromos indicates an expected call of ListPromos.
+func (mr *MockpromosUsecaseMockRecorder) ListPromos(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromos", reflect.TypeOf((*MockpromosUsecase)(nil).ListPromos), ctx)
+}
+
+// MockwishlistUsecase is a mock of wishlistUsecase interface.
+type MockwishlistUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockwishlistUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockwishlistUsecaseMockRecorder is the mock recorder for MockwishlistUsecase.
+type MockwishlistUsecaseMockRecorder struct {
+	mock *MockwishlistUsecase
+}
+
+// NewMockwishlistUsecase creates a new mock instance.
+func NewMockwishlistUsecase(ctrl *gomock.Controller) *MockwishlistUsecase {
+	mock := &MockwishlistUsecase{ctrl: ctrl}
+	mock.recorder = &MockwishlistUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockwishlistUsecase) EXPECT() *MockwishlistUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockwishlistUsecase) Add(ctx context.Context, user, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, user, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockwishlistUsecaseMockRecorder) Add(ctx, user, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockwishlistUsecase)(nil).Add), ctx, user, item)
+}
+
+// List mocks base method.
+func (m *MockwishlistUsecase) List(ctx context.Context, user string) ([]models.WishlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, user)
+	ret0, _ := ret[0].([]models.WishlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockwishlistUsecaseMockRecorder) List(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockwishlistUsecase)(nil).List), ctx, user)
+}
+
+// Notifications mocks base method.
+func (m *MockwishlistUsecase) Notifications(ctx context.Context, user string) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications", ctx, user)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockwishlistUsecaseMockRecorder) Notifications(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockwishlistUsecase)(nil).Notifications), ctx, user)
+}
+
+// ReadNotifications mocks base method.
+func (m *MockwishlistUsecase) ReadNotifications(ctx context.Context, user string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadNotifications", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadNotifications indicates an expected call of ReadNotifications.
+func (mr *MockwishlistUsecaseMockRecorder) ReadNotifications(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadNotifications", reflect.TypeOf((*MockwishlistUsecase)(nil).ReadNotifications), ctx, user)
+}
+
+// Remove mocks base method.
+func (m *MockwishlistUsecase) Remove(ctx context.Context, user, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, user, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockwishlistUsecaseMockRecorder) Remove(ctx, user, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockwishlistUsecase)(nil).Remove), ctx, user, item)
+}
+
+// MockidempotencyUsecase is a mock of idempotencyUsecase interface.
+type MockidempotencyUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockidempotencyUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockidempotencyUsecaseMockRecorder is the mock recorder for MockidempotencyUsecase.
+type MockidempotencyUsecaseMockRecorder struct {
+	mock *MockidempotencyUsecase
+}
+
+// NewMockidempotencyUsecase creates a new mock instance.
+func NewMockidempotencyUsecase(ctrl *gomock.Controller) *MockidempotencyUsecase {
+	mock := &MockidempotencyUsecase{ctrl: ctrl}
+	mock.recorder = &MockidempotencyUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockidempotencyUsecase) EXPECT() *MockidempotencyUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockidempotencyUsecase) Do(ctx context.Context, rq *models.IdempotentRequest, f func(context.Context) *models.StoredResponse) (*models.StoredResponse, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, rq, f)
+	ret0, _ := ret[0].(*models.StoredResponse)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockidempotencyUsecaseMockRecorder) Do(ctx, rq, f any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockaccountantUsecase)(nil).Transfer), ctx, from, to, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockidempotencyUsecase)(nil).Do), ctx, rq, f)
 }
@@ -5,6 +5,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
@@ -17,28 +18,174 @@ type handle struct {
 
 	auth     authUsecase
 	acc      accountantUsecase
-	validate *validator.Validate
+	catalog  catalogUsecase
+	orders   ordersUsecase
+	promos   promosUsecase
+	wishlist wishlistUsecase
+
+	idempotency idempotencyUsecase
+	validate    *validator.Validate
 }
 
 type authUsecase interface {
 	Authorize(ctx context.Context, rq *models.AuthReqest) (resp *models.AuthResponse, err error)
+	Register(ctx context.Context, rq *models.RegisterRequest) (resp *models.AuthResponse, err error)
+	Refresh(ctx context.Context, refreshToken string) (resp *models.AuthResponse, err error)
+	Logout(ctx context.Context, rq *models.LogoutRequest) error
+	RevokeSessions(ctx context.Context, login string) error
+	GrantRole(ctx context.Context, login string, role string) error
+	RevokeRole(ctx context.Context, login string, role string) error
+	Deactivate(ctx context.Context, login string) error
+	Reactivate(ctx context.Context, login string) error
+	ChangePassword(ctx context.Context, rq *models.ChangePasswordRequest) error
+	IssueReset(ctx context.Context, login string) (*models.PasswordResetResponse, error)
+	ResetPassword(ctx context.Context, rq *models.ResetPasswordRequest) error
+	VerifyMFA(ctx context.Context, rq *models.MFAVerifyRequest) (*models.AuthResponse, error)
+	EnrollMFA(ctx context.Context) (*models.MFAEnrollResponse, error)
+	ConfirmMFA(ctx context.Context, rq *models.MFACodeRequest) (*models.MFARecoveryCodes, error)
+	DisableMFA(ctx context.Context, rq *models.MFACodeRequest) error
 }
 type accountantUsecase interface {
-	Buy(ctx context.Context, user string, item string) error
-	Transfer(ctx context.Context, from string, to string, amount int) error
+	Buy(ctx context.Context, user string, item string, promo string) error
+	Transfer(ctx context.Context, from string, to string, amount int, memo string) error
+	Transfers(ctx context.Context, user string, f *models.HistoryFilter) (*models.TransferPage, error)
+	Purchases(ctx context.Context, user string, f *models.HistoryFilter) (*models.PurchasePage, error)
+	TransferLimits(ctx context.Context, login string) (*models.TransferLimits, error)
+	SetTransferLimits(ctx context.Context, login string, o *models.TransferLimitsOverride) (*models.TransferLimits, error)
+	ResetTransferLimits(ctx context.Context, login string) error
 	Info(ctx context.Context, user string) (*models.InfoResponse, error)
 }
+type catalogUsecase interface {
+	ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error)
+	CreateItem(ctx context.Context, rq *models.CreateItemRequest) error
+	UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error
+	SetPrice(ctx context.Context, name string, price int) error
+	Prices(ctx context.Context, name string) ([]models.ItemPrice, error)
+	SchedulePrice(ctx context.Context, name string, rq *models.SchedulePriceRequest) (*models.ItemPrice, error)
+	CancelPrice(ctx context.Context, name string, id int64) error
+	SetStock(ctx context.Context, name string, rq *models.ItemStockRequest) error
+	Retire(ctx context.Context, name string) error
+	Restore(ctx context.Context, name string) error
+}
+type ordersUsecase interface {
+	PlaceOrder(ctx context.Context, user string, rq *models.OrderRequest) (*models.Order, error)
+	Checkout(ctx context.Context, user string, promo string) (*models.Order, error)
+	Gift(ctx context.Context, user string, rq *models.GiftRequest) (*models.Order, error)
+	Cart(ctx context.Context, user string) (*models.Cart, error)
+	AddToCart(ctx context.Context, user string, line *models.OrderLine) error
+	RemoveFromCart(ctx context.Context, user string, item string) error
+	ListOrders(ctx context.Context, user string) ([]models.Order, error)
+	AllOrders(ctx context.Context, status string) ([]models.Order, error)
+	SetStatus(ctx context.Context, id int64, status string) (*models.Order, error)
+	Cancel(ctx context.Context, user string, id int64) (*models.Order, error)
+	AdminCancel(ctx context.Context, id int64) (*models.Order, error)
+}
+type promosUsecase interface {
+	ListPromos(ctx context.Context) ([]models.Promo, error)
+	CreatePromo(ctx context.Context, rq *models.CreatePromoRequest) (*models.Promo, error)
+	DisablePromo(ctx context.Context, code string) error
+}
+type wishlistUsecase interface {
+	List(ctx context.Context, user string) ([]models.WishlistItem, error)
+	Add(ctx context.Context, user string, item string) error
+	Remove(ctx context.Context, user string, item string) error
+	Notifications(ctx context.Context, user string) ([]models.Notification, error)
+	ReadNotifications(ctx context.Context, user string) error
+}
+type idempotencyUsecase interface {
+	Do(
+		ctx context.Context, rq *models.IdempotentRequest, f func(ctx context.Context) *models.StoredResponse,
+	) (*models.StoredResponse, bool, error)
+}
 
-func New(lg *zerolog.Logger, auth authUsecase, acc accountantUsecase) *http.ServeMux {
+func New( //nolint:revive
+	lg *zerolog.Logger, auth authUsecase, acc accountantUsecase, catalog catalogUsecase, orders ordersUsecase,
+	promos promosUsecase, wishlist wishlistUsecase, idempotency idempotencyUsecase,
+) *http.ServeMux {
 	mx := http.NewServeMux()
-	h := &handle{lg: lg, auth: auth, acc: acc}
+	h := &handle{
+		lg: lg, auth: auth, acc: acc, catalog: catalog, orders: orders, promos: promos, wishlist: wishlist,
+		idempotency: idempotency,
+	}
 	h.validate = validator.New()
 
+	// вход и сессии не повторяются по Idempotency-Key: ответы с токенами и секретами не сохраняются
 	mx.HandleFunc("POST /api/auth", h.loggerMiddleware(h.handleAuth))
-	mx.HandleFunc("GET /api/info", h.loggerMiddleware(h.authMiddleware(h.handleInfo)))
-	mx.HandleFunc("POST /api/sendCoin", h.loggerMiddleware(h.authMiddleware(h.handleTransfer)))
+	mx.HandleFunc("POST /api/register", h.loggerMiddleware(h.handleRegister))
+	mx.HandleFunc("POST /api/auth/refresh", h.loggerMiddleware(h.handleRefresh))
+	mx.HandleFunc("POST /api/auth/logout", h.loggerMiddleware(h.authMiddleware(h.handleLogout)))
+	mx.HandleFunc("POST /api/auth/password", h.loggerMiddleware(h.authMiddleware(h.handleChangePassword)))
+	mx.HandleFunc("POST /api/auth/password/reset", h.loggerMiddleware(h.handleResetPassword))
+	mx.HandleFunc("POST /api/auth/mfa", h.loggerMiddleware(h.handleVerifyMFA))
+	mx.HandleFunc("POST /api/auth/mfa/enroll", h.loggerMiddleware(h.authMiddleware(h.handleEnrollMFA)))
+	mx.HandleFunc("POST /api/auth/mfa/confirm", h.loggerMiddleware(h.authMiddleware(h.handleConfirmMFA)))
+	mx.HandleFunc("POST /api/auth/mfa/disable", h.loggerMiddleware(h.authMiddleware(h.handleDisableMFA)))
+	mx.HandleFunc("GET /.well-known/jwks.json", h.loggerMiddleware(h.handleJWKS))
+
+	// изменяющие запросы через route можно повторять с тем же Idempotency-Key;
+	// маршруты, чьи ответы содержат токены и секреты, регистрируются напрямую
+	route := func(pattern string, f http.HandlerFunc, roles ...string) {
+		if !strings.HasPrefix(pattern, http.MethodGet+" ") {
+			f = h.idempotent(f)
+		}
+		if len(roles) > 0 {
+			f = h.requireRole(f, roles...)
+		}
+		mx.HandleFunc(pattern, h.loggerMiddleware(h.authMiddleware(f)))
+	}
+	route("GET /api/info", h.handleInfo)
+	route("POST /api/sendCoin", h.handleTransfer)
+	route("GET /api/transfers", h.handleTransfers)
+	route("GET /api/purchases", h.handlePurchases)
 	// запрос на изменение данных лучше оформлять как POST, но ТЗ требует GET.
-	mx.HandleFunc("GET /api/buy/{item}", h.loggerMiddleware(h.authMiddleware(h.handleBuy)))
+	route("GET /api/buy/{item}", h.idempotent(h.handleBuy))
+	route("GET /api/items", h.handleListItems)
+	route("POST /api/orders", h.handlePlaceOrder)
+	route("GET /api/orders", h.handleListOrders)
+	route("POST /api/orders/{id}/cancel", h.handleCancelOrder)
+	route("POST /api/gifts", h.handleGift)
+	route("GET /api/wishlist", h.handleWishlist)
+	route("PUT /api/wishlist/{item}", h.handleAddToWishlist)
+	route("DELETE /api/wishlist/{item}", h.handleRemoveFromWishlist)
+	route("GET /api/notifications", h.handleNotifications)
+	route("POST /api/notifications/read", h.handleReadNotifications)
+	route("GET /api/cart", h.handleCart)
+	route("POST /api/cart/items", h.handleAddToCart)
+	route("DELETE /api/cart/items/{item}", h.handleRemoveFromCart)
+	route("POST /api/cart/checkout", h.handleCheckout)
+
+	admin := []string{models.RoleAdmin}
+	route("PUT /api/admin/users/{login}/roles/{role}", h.handleGrantRole, admin...)
+	route("DELETE /api/admin/users/{login}/roles/{role}", h.handleRevokeRole, admin...)
+	route("DELETE /api/admin/users/{login}/sessions", h.handleRevokeSessions, admin...)
+	route("POST /api/admin/users/{login}/deactivate", h.handleDeactivate, admin...)
+	route("POST /api/admin/users/{login}/reactivate", h.handleReactivate, admin...)
+	// одноразовый код в ответе не должен попасть в сохранённые ответы
+	mx.HandleFunc("POST /api/admin/users/{login}/password-reset",
+		h.loggerMiddleware(h.authMiddleware(h.requireRole(h.handleIssueReset, models.RoleAdmin))))
+	route("GET /api/admin/users/{login}/transfer-limits", h.handleTransferLimits, admin...)
+	route("PUT /api/admin/users/{login}/transfer-limits", h.handleSetTransferLimits, admin...)
+	route("DELETE /api/admin/users/{login}/transfer-limits", h.handleResetTransferLimits, admin...)
+
+	// каталогом управляют администраторы и менеджеры
+	manager := []string{models.RoleAdmin, models.RoleManager}
+	route("GET /api/admin/items", h.handleAdminListItems, manager...)
+	route("POST /api/admin/items", h.handleCreateItem, manager...)
+	route("PUT /api/admin/products/{product}", h.handleUpdateProduct, manager...)
+	route("PUT /api/admin/items/{item}/price", h.handleSetPrice, manager...)
+	route("GET /api/admin/items/{item}/prices", h.handleListPrices, manager...)
+	route("POST /api/admin/items/{item}/prices", h.handleSchedulePrice, manager...)
+	route("DELETE /api/admin/items/{item}/prices/{id}", h.handleCancelPrice, manager...)
+	route("PUT /api/admin/items/{item}/stock", h.handleSetStock, manager...)
+	route("POST /api/admin/items/{item}/retire", h.handleRetireItem, manager...)
+	route("POST /api/admin/items/{item}/restore", h.handleRestoreItem, manager...)
+	// заказы выдаёт офис, очередь видят те же роли
+	route("GET /api/admin/orders", h.handleAdminListOrders, manager...)
+	route("PUT /api/admin/orders/{id}/status", h.handleSetOrderStatus, manager...)
+	route("POST /api/admin/orders/{id}/cancel", h.handleAdminCancelOrder, manager...)
+	route("GET /api/admin/promos", h.handleListPromos, manager...)
+	route("POST /api/admin/promos", h.handleCreatePromo, manager...)
+	route("POST /api/admin/promos/{code}/disable", h.handleDisablePromo, manager...)
 
 	return mx
 }
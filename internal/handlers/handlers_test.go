@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cxbelka/winter_2025/internal/models"
 	"github.com/cxbelka/winter_2025/internal/token"
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -20,8 +23,9 @@ func Test_Auth(t *testing.T) {
 	testCases := map[string]struct {
 		rqBody string
 
-		respCode int
-		respBody string
+		respCode   int
+		respBody   string
+		retryAfter string
 
 		init func(*handle)
 	}{
@@ -56,6 +60,22 @@ func Test_Auth(t *testing.T) {
 
 				mock.EXPECT().Authorize(gomock.Any(), &models.AuthReqest{Username: "u2", Password: "p10"}).Return(nil, models.ErrInvalidPassword)
 
+				h.auth = mock
+			},
+		},
+		"locked_auth": {
+			rqBody: `{"username":"u2","password":"p10"}`,
+
+			respCode:   429,
+			respBody:   `{"errors":"Too many requests"}`,
+			retryAfter: "91",
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Authorize(gomock.Any(), &models.AuthReqest{Username: "u2", Password: "p10"}).
+					Return(nil, &models.RetryAfterError{Err: models.ErrTooManyAttempts, After: 90*time.Second + time.Millisecond})
+
 				h.auth = mock
 			},
 		},
@@ -64,7 +84,7 @@ func Test_Auth(t *testing.T) {
 	for name, tc := range testCases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			h := &handle{}
+			h := &handle{validate: validator.New()}
 
 			if tc.init != nil {
 				tc.init(h)
@@ -78,85 +98,153 @@ func Test_Auth(t *testing.T) {
 
 			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
 			require.Equal(t, tc.respCode, resp.Code)
+			require.Equal(t, tc.retryAfter, resp.Header().Get("Retry-After"))
 		})
 	}
 }
 
-func Test_Transfer(t *testing.T) {
+func Test_Register(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	type _tc struct {
-		rqBody   string
-		userName string
+	testCases := map[string]struct {
+		rqBody string
 
 		respCode int
 		respBody string
 
-		init func(*handle, *_tc)
-	}
+		init func(*handle)
+	}{
+		"no_password": {
+			rqBody: `{"username":"u1"}`,
 
-	testCases := map[string]_tc{
-		"unmarshal_err": {
-			rqBody: `....`,
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"registered": {
+			rqBody: `{"username":"u1","password":"correct-horse","inviteCode":"c1"}`,
 
-			respCode: 500,
-			respBody: `{"errors":"Internal server error"}`,
+			respCode: 201,
+			respBody: `{"token":"abc","refreshToken":"r1"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Register(gomock.Any(), &models.RegisterRequest{Username: "u1", Password: "correct-horse", InviteCode: "c1"}).
+					Return(&models.AuthResponse{Token: "abc", RefreshToken: "r1"}, nil)
+
+				h.auth = mock
+			},
 		},
-		"valid_transfer": {
-			rqBody:   `{"toUser":"u1","amount":30}`,
-			userName: "u2",
-			respCode: 200,
-			respBody: ``,
+		"weak_password": {
+			rqBody: `{"username":"u1","password":"p1"}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 400,
+			respBody: `{"errors":"Password does not meet policy"}`,
 
-				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30).Return(nil)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				h.acc = mock
+				mock.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, models.ErrWeakPassword)
+
+				h.auth = mock
 			},
 		},
-		"invalid_transfer": {
-			rqBody:   `{"toUser":"u1","amount":30}`,
-			userName: "u2",
-			respCode: 500,
-			respBody: `{"errors":"Internal server error"}`,
+		"user_exists": {
+			rqBody: `{"username":"u1","password":"correct-horse"}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 409,
+			respBody: `{"errors":"User already exists"}`,
 
-				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30).Return(models.ErrGeneric)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				h.acc = mock
+				mock.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, models.ErrUserExists)
+
+				h.auth = mock
 			},
 		},
-		"not_enough_money": {
-			rqBody:   `{"toUser":"u1","amount":30}`,
-			userName: "u2",
-			respCode: 400,
-			respBody: `{"errors":"Not enough coins"}`,
+		"denied": {
+			rqBody: `{"username":"u1","password":"correct-horse","inviteCode":"bad"}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 403,
+			respBody: `{"errors":"Forbidden"}`,
 
-				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30).Return(models.ErrNoMoney)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				h.acc = mock
+				mock.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, models.ErrRegistrationDenied)
+
+				h.auth = mock
 			},
 		},
-		"invalid_toUser": {
-			rqBody:   `{"toUser":"u50","amount":30}`,
-			userName: "u2",
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodPost, `/api/register`, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+
+			h.handleRegister(resp, rq)
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := map[string]struct {
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"no_token": {
+			rqBody: `{}`,
+
 			respCode: 400,
 			respBody: `{"errors":"Bad request"}`,
+		},
+		"valid_refresh": {
+			rqBody: `{"refreshToken":"r1"}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 200,
+			respBody: `{"token":"abc","refreshToken":"r2"}`,
 
-				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u50", 30).Return(models.ErrNoRows)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				h.acc = mock
+				mock.EXPECT().Refresh(gomock.Any(), "r1").Return(&models.AuthResponse{Token: "abc", RefreshToken: "r2"}, nil)
+
+				h.auth = mock
+			},
+		},
+		"invalid_refresh": {
+			rqBody: `{"refreshToken":"r1"}`,
+
+			respCode: 401,
+			respBody: `{"errors":"Unauthorized"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Refresh(gomock.Any(), "r1").Return(nil, models.ErrInvalidToken)
+
+				h.auth = mock
 			},
 		},
 	}
@@ -164,17 +252,17 @@ func Test_Transfer(t *testing.T) {
 	for name, tc := range testCases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			h := &handle{}
+			h := &handle{validate: validator.New()}
 
 			if tc.init != nil {
-				tc.init(h, &tc)
+				tc.init(h)
 			}
 
 			resp := httptest.NewRecorder()
-			rq, err := http.NewRequest(http.MethodPost, `/api/sendCoin`, bytes.NewBufferString(tc.rqBody))
+			rq, err := http.NewRequest(http.MethodPost, `/api/auth/refresh`, bytes.NewBufferString(tc.rqBody))
 			require.NoError(t, err)
 
-			h.handleTransfer(resp, rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName)))
+			h.handleRefresh(resp, rq)
 
 			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
 			require.Equal(t, tc.respCode, resp.Code)
@@ -182,75 +270,78 @@ func Test_Transfer(t *testing.T) {
 	}
 }
 
-func Test_Buy(t *testing.T) {
+func Test_Logout(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	type _tc struct {
-		userName string
-		item     string
+	testCases := map[string]struct {
+		rqBody string
 
 		respCode int
 		respBody string
 
-		init func(*handle, *_tc)
-	}
+		init func(*handle)
+	}{
+		"no_body": {
+			rqBody: ``,
 
-	testCases := map[string]_tc{
-		"valid_buy": {
-			userName: "u2",
-			item:     "hoody",
 			respCode: 200,
 			respBody: ``,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item).Return(nil)
+				mock.EXPECT().Logout(gomock.Any(), &models.LogoutRequest{}).Return(nil)
 
-				h.acc = mock
+				h.auth = mock
 			},
 		},
-		"invalid_buy": {
-			userName: "u2",
-			item:     "hoody",
-			respCode: 500,
-			respBody: `{"errors":"Internal server error"}`,
+		"with_refresh": {
+			rqBody: `{"refreshToken":"r1"}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 200,
+			respBody: ``,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item).Return(models.ErrGeneric)
+				mock.EXPECT().Logout(gomock.Any(), &models.LogoutRequest{RefreshToken: "r1"}).Return(nil)
 
-				h.acc = mock
+				h.auth = mock
 			},
 		},
-		"not_enough_money": {
-			userName: "u2",
-			item:     "hoody",
-			respCode: 400,
-			respBody: `{"errors":"Not enough coins"}`,
+		"all_sessions": {
+			rqBody: `{"all":true}`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 200,
+			respBody: ``,
 
-				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item).Return(models.ErrNoMoney)
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
 
-				h.acc = mock
+				mock.EXPECT().Logout(gomock.Any(), &models.LogoutRequest{All: true}).Return(nil)
+
+				h.auth = mock
 			},
 		},
-		"invalid_items": {
-			item:     "....",
-			userName: "u2",
-			respCode: 400,
-			respBody: `{"errors":"Bad request"}`,
+		"unmarshal_err": {
+			rqBody: `....`,
 
-			init: func(h *handle, tc *_tc) {
-				mock := NewMockaccountantUsecase(ctrl)
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+		},
+		"db_issue": {
+			rqBody: ``,
 
-				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item).Return(models.ErrNoRows)
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
 
-				h.acc = mock
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Logout(gomock.Any(), &models.LogoutRequest{}).Return(models.ErrGeneric)
+
+				h.auth = mock
 			},
 		},
 	}
@@ -258,19 +349,17 @@ func Test_Buy(t *testing.T) {
 	for name, tc := range testCases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			h := &handle{}
+			h := &handle{validate: validator.New()}
 
 			if tc.init != nil {
-				tc.init(h, &tc)
+				tc.init(h)
 			}
 
 			resp := httptest.NewRecorder()
-			rq, err := http.NewRequest(http.MethodGet, `/api/buy/`, nil)
+			rq, err := http.NewRequest(http.MethodPost, `/api/auth/logout`, bytes.NewBufferString(tc.rqBody))
 			require.NoError(t, err)
 
-			rq.SetPathValue("item", tc.item)
-
-			h.handleBuy(resp, rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName)))
+			h.handleLogout(resp, rq)
 
 			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
 			require.Equal(t, tc.respCode, resp.Code)
@@ -278,12 +367,28 @@ func Test_Buy(t *testing.T) {
 	}
 }
 
-func Test_Info(t *testing.T) {
+func Test_JWKS(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test")
+	require.NoError(t, token.Reinit())
+
+	h := &handle{}
+	resp := httptest.NewRecorder()
+	rq, err := http.NewRequest(http.MethodGet, `/.well-known/jwks.json`, nil)
+	require.NoError(t, err)
 
+	h.handleJWKS(resp, rq)
+
+	// симметричный ключ не публикуется
+	require.Equal(t, `{"keys":[]}`, strings.Trim(resp.Body.String(), "\n"))
+	require.Equal(t, 200, resp.Code)
+}
+
+func Test_Transfer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	type _tc struct {
+		rqBody   string
 		userName string
 
 		respCode int
@@ -293,66 +398,2000 @@ func Test_Info(t *testing.T) {
 	}
 
 	testCases := map[string]_tc{
+		"unmarshal_err": {
+			rqBody: `....`,
 
-		"valid_info1": {
-
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+		},
+		"valid_transfer": {
+			rqBody:   `{"toUser":"u1","amount":30}`,
 			userName: "u2",
 			respCode: 200,
-			respBody: `{"coins":400,"inventory":null,"coinHistory":{"received":null,"sent":null}}`,
+			respBody: ``,
 
 			init: func(h *handle, tc *_tc) {
 				mock := NewMockaccountantUsecase(ctrl)
 
-				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(&models.InfoResponse{Balance: 400}, nil)
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "").Return(nil)
 
 				h.acc = mock
 			},
 		},
-		"invalid_info": {
-
+		"with_memo": {
+			rqBody:   `{"toUser":"u1","amount":30,"memo":"thanks for the review!"}`,
 			userName: "u2",
-			respCode: 500,
-			respBody: `{"errors":"Internal server error"}`,
+			respCode: 200,
+			respBody: ``,
 
 			init: func(h *handle, tc *_tc) {
 				mock := NewMockaccountantUsecase(ctrl)
 
-				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(nil, models.ErrGeneric)
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "thanks for the review!").Return(nil)
 
 				h.acc = mock
 			},
 		},
-		"valid_balance": {
-
+		"memo_too_long": {
+			rqBody:   `{"toUser":"u1","amount":30,"memo":"` + strings.Repeat("a", 201) + `"}`,
 			userName: "u2",
-			respCode: 200,
-			respBody: `{"coins":400,"inventory":null,"coinHistory":{"received":null,"sent":null}}`,
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"limit_exceeded": {
+			rqBody:   `{"toUser":"u1","amount":30}`,
+			userName: "u2",
+			respCode: 403,
+			respBody: `{"errors":"Transfer limit exceeded: counterparty"}`,
 
 			init: func(h *handle, tc *_tc) {
 				mock := NewMockaccountantUsecase(ctrl)
 
-				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(&models.InfoResponse{Balance: 400, Inventory: nil, Transfers: models.InfoResponseTransfers{Received: nil, Sent: nil}}, nil)
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "").
+					Return(errors.Join(models.ErrGeneric, &models.LimitError{Limit: models.LimitCounterparty}))
 
 				h.acc = mock
 			},
 		},
-	}
+		"invalid_transfer": {
+			rqBody:   `{"toUser":"u1","amount":30}`,
+			userName: "u2",
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
 
-	for name, tc := range testCases {
-		tc := tc
-		t.Run(name, func(t *testing.T) {
-			h := &handle{}
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
 
-			if tc.init != nil {
-				tc.init(h, &tc)
-			}
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "").Return(models.ErrGeneric)
 
-			resp := httptest.NewRecorder()
-			rq, err := http.NewRequest(http.MethodGet, `/api/info`, nil)
+				h.acc = mock
+			},
+		},
+		"not_enough_money": {
+			rqBody:   `{"toUser":"u1","amount":30}`,
+			userName: "u2",
+			respCode: 400,
+			respBody: `{"errors":"Not enough coins"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "").Return(models.ErrNoMoney)
+
+				h.acc = mock
+			},
+		},
+		"deactivated_toUser": {
+			rqBody:   `{"toUser":"u1","amount":30}`,
+			userName: "u2",
+			respCode: 403,
+			respBody: `{"errors":"Account is deactivated"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u1", 30, "").Return(errors.Join(models.ErrGeneric, models.ErrUserDeactivated))
+
+				h.acc = mock
+			},
+		},
+		"invalid_toUser": {
+			rqBody:   `{"toUser":"u50","amount":30}`,
+			userName: "u2",
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfer(gomock.Any(), tc.userName, "u50", 30, "").Return(models.ErrNoRows)
+
+				h.acc = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h, &tc)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodPost, `/api/sendCoin`, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+
+			h.handleTransfer(resp, rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName)))
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Buy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type _tc struct {
+		userName string
+		item     string
+		promo    string
+
+		respCode int
+		respBody string
+
+		init func(*handle, *_tc)
+	}
+
+	testCases := map[string]_tc{
+		"valid_buy": {
+			userName: "u2",
+			item:     "hoody",
+			respCode: 200,
+			respBody: ``,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(nil)
+
+				h.acc = mock
+			},
+		},
+		"invalid_buy": {
+			userName: "u2",
+			item:     "hoody",
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(models.ErrGeneric)
+
+				h.acc = mock
+			},
+		},
+		"not_enough_money": {
+			userName: "u2",
+			item:     "hoody",
+			respCode: 400,
+			respBody: `{"errors":"Not enough coins"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(models.ErrNoMoney)
+
+				h.acc = mock
+			},
+		},
+		"unknown_item": {
+			userName: "u2",
+			item:     "unicorn",
+			respCode: 400,
+			respBody: `{"errors":"Item not found"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrItemNotFound))
+
+				h.acc = mock
+			},
+		},
+		"retired_item": {
+			userName: "u2",
+			item:     "old-hoody",
+			respCode: 400,
+			respBody: `{"errors":"Item is not available"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrItemUnavailable))
+
+				h.acc = mock
+			},
+		},
+		"out_of_stock": {
+			userName: "u2",
+			item:     "pink-hoody",
+			respCode: 409,
+			respBody: `{"errors":"Item is out of stock"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrOutOfStock))
+
+				h.acc = mock
+			},
+		},
+		"purchase_limit": {
+			userName: "u2",
+			item:     "pink-hoody",
+			respCode: 409,
+			respBody: `{"errors":"Purchase limit reached"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrPurchaseLimit))
+
+				h.acc = mock
+			},
+		},
+		"promo": {
+			userName: "u2",
+			item:     "hoody",
+			promo:    "HOODY20",
+			respCode: 200,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(nil)
+
+				h.acc = mock
+			},
+		},
+		"promo_invalid": {
+			userName: "u2",
+			item:     "cup",
+			promo:    "HOODY20",
+			respCode: 400,
+			respBody: `{"errors":"Invalid promo code"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrInvalidPromo))
+
+				h.acc = mock
+			},
+		},
+		"promo_exhausted": {
+			userName: "u2",
+			item:     "hoody",
+			promo:    "HOODY20",
+			respCode: 409,
+			respBody: `{"errors":"Promo code limit reached"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(errors.Join(models.ErrGeneric, models.ErrPromoExhausted))
+
+				h.acc = mock
+			},
+		},
+		"invalid_items": {
+			item:     "....",
+			userName: "u2",
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Buy(gomock.Any(), tc.userName, tc.item, tc.promo).Return(models.ErrNoRows)
+
+				h.acc = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h, &tc)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodGet, `/api/buy/?promo=`+tc.promo, nil)
+			require.NoError(t, err)
+
+			rq.SetPathValue("item", tc.item)
+
+			h.handleBuy(resp, rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName)))
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Info(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type _tc struct {
+		userName string
+
+		respCode int
+		respBody string
+
+		init func(*handle, *_tc)
+	}
+
+	testCases := map[string]_tc{
+
+		"valid_info1": {
+
+			userName: "u2",
+			respCode: 200,
+			respBody: `{"coins":400,"inventory":null,"coinHistory":{"received":null,"sent":null},"gifts":{"received":null,"sent":null}}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(&models.InfoResponse{Balance: 400}, nil)
+
+				h.acc = mock
+			},
+		},
+		"invalid_info": {
+
+			userName: "u2",
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(nil, models.ErrGeneric)
+
+				h.acc = mock
+			},
+		},
+		"valid_balance": {
+
+			userName: "u2",
+			respCode: 200,
+			respBody: `{"coins":400,"inventory":null,"coinHistory":{"received":null,"sent":null},"gifts":{"received":null,"sent":null}}`,
+
+			init: func(h *handle, tc *_tc) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Info(gomock.Any(), tc.userName).Return(&models.InfoResponse{Balance: 400, Inventory: nil, Transfers: models.InfoResponseTransfers{Received: nil, Sent: nil}}, nil)
+
+				h.acc = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h, &tc)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodGet, `/api/info`, nil)
+			require.NoError(t, err)
+
+			rq = rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName))
+			h.handleInfo(resp, rq)
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	testCases := map[string]struct {
+		path string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"transfers": {
+			path: "/api/transfers?limit=2&counterparty=u2",
+
+			respCode: 200,
+			respBody: `{"transfers":[` +
+				`{"id":2,"direction":"in","counterparty":"u2","amount":30,"memo":"thanks","date":"2025-02-01T10:00:00Z"},` +
+				`{"id":1,"direction":"out","counterparty":"u2","amount":10,"date":"2025-02-01T10:00:00Z"}],"nextCursor":"abc"}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfers(gomock.Any(), "u1", &models.HistoryFilter{Counterparty: "u2", Limit: 2}).
+					Return(&models.TransferPage{Transfers: []models.Transfer{
+						{ID: 2, Direction: models.TransferIn, Counterparty: "u2", Amount: 30, Memo: "thanks", Date: at},
+						{ID: 1, Direction: models.TransferOut, Counterparty: "u2", Amount: 10, Date: at},
+					}, NextCursor: "abc"}, nil)
+
+				h.acc = mock
+			},
+		},
+		"transfers_dates": {
+			// дата в to включает весь день
+			path: "/api/transfers?from=2025-02-01T00:00:00Z&to=2025-02-01",
+
+			respCode: 200,
+			respBody: `{"transfers":[]}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfers(gomock.Any(), "u1", &models.HistoryFilter{From: &day, To: &next}).
+					Return(&models.TransferPage{Transfers: []models.Transfer{}}, nil)
+
+				h.acc = mock
+			},
+		},
+		"transfers_bad_date": {
+			path: "/api/transfers?from=yesterday",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"transfers_big_limit": {
+			path: "/api/transfers?limit=1000",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"transfers_bad_cursor": {
+			path: "/api/transfers?cursor=zzz",
+
+			respCode: 400,
+			respBody: `{"errors":"Invalid cursor"}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfers(gomock.Any(), "u1", &models.HistoryFilter{Cursor: "zzz"}).
+					Return(nil, models.ErrInvalidCursor)
+
+				h.acc = mock
+			},
+		},
+		"transfers_db_issue": {
+			path: "/api/transfers",
+
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Transfers(gomock.Any(), "u1", &models.HistoryFilter{}).Return(nil, models.ErrGeneric)
+
+				h.acc = mock
+			},
+		},
+		"purchases": {
+			path: "/api/purchases?item=cup",
+
+			respCode: 200,
+			respBody: `{"purchases":[{"id":7,"item":"cup","sum":15,"discount":5,"promo":"WINTER","fromUser":"u2",` +
+				`"refunded":false,"date":"2025-02-01T10:00:00Z"}]}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().Purchases(gomock.Any(), "u1", &models.HistoryFilter{Item: "cup"}).
+					Return(&models.PurchasePage{Purchases: []models.Purchase{
+						{ID: 7, Item: "cup", Sum: 15, Discount: 5, Promo: "WINTER", From: "u2", Date: at},
+					}}, nil)
+
+				h.acc = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			rq = rq.WithContext(token.ContextWithUser(rq.Context(), "u1"))
+
+			if rq.URL.Path == "/api/purchases" {
+				h.handlePurchases(resp, rq)
+			} else {
+				h.handleTransfers(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+/*
+HTTP/1.1 200 OK
+Date: Thu, 13 Feb 2025 16:35:46 GMT
+Content-Length: 102
+Content-Type: text/plain; charset=utf-8
+Connection: close
+
+{
+  "coins": 400,
+  "inventory": [
+    {
+      "type": "hoody",
+      "quantity": 2
+    }
+  ],
+  "coinHistory": {
+    "received": null,
+    "sent": null
+  }
+}
+  ---------------
+{
+  "coins": 360,
+  "inventory": [
+    {
+      "type": "hoody",
+      "quantity": 2
+    }
+  ],
+  "coinHistory": {
+    "received": null,
+    "sent": [
+      {
+        "toUser": "u1",
+        "amount": 40
+      }
+    ]
+  }
+}
+*/
+
+func Test_Admin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := map[string]struct {
+		method string
+		login  string
+		role   string
+		action string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"grant": {
+			method: http.MethodPut,
+			login:  "u1",
+			role:   models.RoleManager,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().GrantRole(gomock.Any(), "u1", models.RoleManager).Return(nil)
+
+				h.auth = mock
+			},
+		},
+		"grant_unknown_role": {
+			method: http.MethodPut,
+			login:  "u1",
+			role:   "root",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().GrantRole(gomock.Any(), "u1", "root").Return(models.ErrUnknownRole)
+
+				h.auth = mock
+			},
+		},
+		"revoke": {
+			method: http.MethodDelete,
+			login:  "u1",
+			role:   models.RoleAdmin,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().RevokeRole(gomock.Any(), "u1", models.RoleAdmin).Return(nil)
+
+				h.auth = mock
+			},
+		},
+		"deactivate": {
+			method: http.MethodPost,
+			login:  "u1",
+			action: "deactivate",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Deactivate(gomock.Any(), "u1").Return(nil)
+
+				h.auth = mock
+			},
+		},
+		"reactivate_unknown": {
+			method: http.MethodPost,
+			login:  "u404",
+			action: "reactivate",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Reactivate(gomock.Any(), "u404").Return(models.ErrNoRows)
+
+				h.auth = mock
+			},
+		},
+		"revoke_sessions": {
+			method: http.MethodDelete,
+			login:  "u1",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().RevokeSessions(gomock.Any(), "u1").Return(nil)
+
+				h.auth = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, ``, nil)
+			require.NoError(t, err)
+			rq.SetPathValue("login", tc.login)
+			rq.SetPathValue("role", tc.role)
+
+			switch {
+			case tc.action == "deactivate":
+				h.handleDeactivate(resp, rq)
+			case tc.action == "reactivate":
+				h.handleReactivate(resp, rq)
+			case tc.role == "":
+				h.handleRevokeSessions(resp, rq)
+			case tc.method == http.MethodPut:
+				h.handleGrantRole(resp, rq)
+			default:
+				h.handleRevokeRole(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_TransferLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limits := &models.TransferLimits{MaxAmount: 100, Daily: 500}
+	daily := 500
+
+	testCases := map[string]struct {
+		method string
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"get": {
+			method: http.MethodGet,
+
+			respCode: 200,
+			respBody: `{"maxAmount":100,"daily":500,"monthly":0,"dailyPerCounterparty":0,"hourlyCount":0}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().TransferLimits(gomock.Any(), "u1").Return(limits, nil)
+
+				h.acc = mock
+			},
+		},
+		"get_unknown": {
+			method: http.MethodGet,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().TransferLimits(gomock.Any(), "u1").Return(nil, models.ErrNoRows)
+
+				h.acc = mock
+			},
+		},
+		"set": {
+			method: http.MethodPut,
+			rqBody: `{"daily":500}`,
+
+			respCode: 200,
+			respBody: `{"maxAmount":100,"daily":500,"monthly":0,"dailyPerCounterparty":0,"hourlyCount":0}`,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().SetTransferLimits(gomock.Any(), "u1", &models.TransferLimitsOverride{Daily: &daily}).
+					Return(limits, nil)
+
+				h.acc = mock
+			},
+		},
+		"set_negative": {
+			method: http.MethodPut,
+			rqBody: `{"daily":-1}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"reset": {
+			method: http.MethodDelete,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockaccountantUsecase(ctrl)
+
+				mock.EXPECT().ResetTransferLimits(gomock.Any(), "u1").Return(nil)
+
+				h.acc = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, `/api/admin/users/u1/transfer-limits`, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+			rq.SetPathValue("login", "u1")
+
+			switch tc.method {
+			case http.MethodGet:
+				h.handleTransferLimits(resp, rq)
+			case http.MethodPut:
+				h.handleSetTransferLimits(resp, rq)
+			default:
+				h.handleResetTransferLimits(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Password(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := map[string]struct {
+		path   string
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"change": {
+			path:   "/api/auth/password",
+			rqBody: `{"currentPassword":"p1","newPassword":"correct-horse"}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ChangePassword(gomock.Any(), &models.ChangePasswordRequest{CurrentPassword: "p1", NewPassword: "correct-horse"}).Return(nil)
+
+				h.auth = mock
+			},
+		},
+		"change_no_current": {
+			path:   "/api/auth/password",
+			rqBody: `{"newPassword":"correct-horse"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"change_weak": {
+			path:   "/api/auth/password",
+			rqBody: `{"currentPassword":"p1","newPassword":"p2"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Password does not meet policy"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ChangePassword(gomock.Any(), gomock.Any()).Return(models.ErrWeakPassword)
+
+				h.auth = mock
+			},
+		},
+		"reset": {
+			path:   "/api/auth/password/reset",
+			rqBody: `{"resetCode":"c1","newPassword":"correct-horse"}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ResetPassword(gomock.Any(), &models.ResetPasswordRequest{Code: "c1", NewPassword: "correct-horse"}).Return(nil)
+
+				h.auth = mock
+			},
+		},
+		"reset_invalid_code": {
+			path:   "/api/auth/password/reset",
+			rqBody: `{"resetCode":"c1","newPassword":"correct-horse"}`,
+
+			respCode: 401,
+			respBody: `{"errors":"Unauthorized"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(models.ErrInvalidToken)
+
+				h.auth = mock
+			},
+		},
+		"issue_reset": {
+			path: "/api/admin/users/u1/password-reset",
+
+			respCode: 200,
+			respBody: `{"resetCode":"c1","expiresAt":"2025-02-01T00:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().IssueReset(gomock.Any(), "u1").
+					Return(&models.PasswordResetResponse{Code: "c1", ExpiresAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}, nil)
+
+				h.auth = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+
+			switch tc.path {
+			case "/api/auth/password":
+				h.handleChangePassword(resp, rq)
+			case "/api/auth/password/reset":
+				h.handleResetPassword(resp, rq)
+			default:
+				rq.SetPathValue("login", "u1")
+				h.handleIssueReset(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_MFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := map[string]struct {
+		path   string
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"auth_pending": { // после пароля выдаётся только pending токен
+			path:   "/api/auth",
+			rqBody: `{"username":"u1","password":"p1"}`,
+
+			respCode: 200,
+			respBody: `{"mfaToken":"m1"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&models.AuthResponse{MFAToken: "m1"}, nil)
+
+				h.auth = mock
+			},
+		},
+		"verify": {
+			path:   "/api/auth/mfa",
+			rqBody: `{"mfaToken":"m1","code":"123456"}`,
+
+			respCode: 200,
+			respBody: `{"token":"abc","refreshToken":"r1"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().VerifyMFA(gomock.Any(), &models.MFAVerifyRequest{MFAToken: "m1", Code: "123456"}).
+					Return(&models.AuthResponse{Token: "abc", RefreshToken: "r1"}, nil)
+
+				h.auth = mock
+			},
+		},
+		"verify_no_code": {
+			path:   "/api/auth/mfa",
+			rqBody: `{"mfaToken":"m1"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"verify_invalid_code": {
+			path:   "/api/auth/mfa",
+			rqBody: `{"mfaToken":"m1","code":"000000"}`,
+
+			respCode: 401,
+			respBody: `{"errors":"Invalid code"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().VerifyMFA(gomock.Any(), gomock.Any()).Return(nil, models.ErrInvalidOTP)
+
+				h.auth = mock
+			},
+		},
+		"enroll": {
+			path: "/api/auth/mfa/enroll",
+
+			respCode: 200,
+			respBody: `{"secret":"S1","otpauthUri":"otpauth://totp/shop:u1?secret=S1"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().EnrollMFA(gomock.Any()).
+					Return(&models.MFAEnrollResponse{Secret: "S1", URI: "otpauth://totp/shop:u1?secret=S1"}, nil)
+
+				h.auth = mock
+			},
+		},
+		"confirm": {
+			path:   "/api/auth/mfa/confirm",
+			rqBody: `{"code":"123456"}`,
+
+			respCode: 200,
+			respBody: `{"recoveryCodes":["aaaaa-bbbbb"]}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ConfirmMFA(gomock.Any(), &models.MFACodeRequest{Code: "123456"}).
+					Return(&models.MFARecoveryCodes{Codes: []string{"aaaaa-bbbbb"}}, nil)
+
+				h.auth = mock
+			},
+		},
+		"confirm_enabled": {
+			path:   "/api/auth/mfa/confirm",
+			rqBody: `{"code":"123456"}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Two-factor authentication already enabled"}`,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().ConfirmMFA(gomock.Any(), gomock.Any()).Return(nil, models.ErrMFAEnabled)
+
+				h.auth = mock
+			},
+		},
+		"disable": {
+			path:   "/api/auth/mfa/disable",
+			rqBody: `{"code":"aaaaa-bbbbb"}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockauthUsecase(ctrl)
+
+				mock.EXPECT().DisableMFA(gomock.Any(), &models.MFACodeRequest{Code: "aaaaa-bbbbb"}).Return(nil)
+
+				h.auth = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+
+			switch tc.path {
+			case "/api/auth":
+				h.handleAuth(resp, rq)
+			case "/api/auth/mfa":
+				h.handleVerifyMFA(resp, rq)
+			case "/api/auth/mfa/enroll":
+				h.handleEnrollMFA(resp, rq)
+			case "/api/auth/mfa/confirm":
+				h.handleConfirmMFA(resp, rq)
+			default:
+				h.handleDisableMFA(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Catalog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	price := 120
+
+	testCases := map[string]struct {
+		method string
+		path   string
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"list": {
+			method: http.MethodGet,
+			path:   "/api/items",
+
+			respCode: 200,
+			respBody: `[{"name":"cup","price":20,"product":"cup"},{"name":"pink-hoody","price":500,"stock":3,"maxPerUser":1,"product":"pink-hoody"}]`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				stock, limit := 3, 1
+				mock.EXPECT().ListItems(gomock.Any(), false, &models.ItemFilter{}).
+					Return([]models.Item{
+						{Name: "cup", Price: 20, Product: "cup"},
+						{Name: "pink-hoody", Price: 500, Stock: &stock, MaxPerUser: &limit, Product: "pink-hoody"},
+					}, nil)
+
+				h.catalog = mock
+			},
+		},
+		"admin_list": { // снятые с продажи видны только в админском списке
+			method: http.MethodGet,
+			path:   "/api/admin/items",
+
+			respCode: 200,
+			respBody: `[{"name":"cup","price":20,"product":"cup"},{"name":"pen","price":10,"retired":true,"product":"pen"}]`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().ListItems(gomock.Any(), true, &models.ItemFilter{}).
+					Return([]models.Item{{Name: "cup", Price: 20, Product: "cup"}, {Name: "pen", Price: 10, Retired: true, Product: "pen"}}, nil)
+
+				h.catalog = mock
+			},
+		},
+		"search": {
+			method: http.MethodGet,
+			path:   "/api/items?category=clothing&q=hoody&sort=-price&limit=2&offset=2",
+
+			respCode: 200,
+			respBody: `[{"name":"hoody-m","price":300,"stock":5,"product":"hoody","size":"M","category":"clothing","description":"Warm","tags":["winter"]}]`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				stock := 5
+				mock.EXPECT().ListItems(gomock.Any(), false,
+					&models.ItemFilter{Category: "clothing", Query: "hoody", Sort: "-price", Limit: 2, Offset: 2}).
+					Return([]models.Item{{
+						Name: "hoody-m", Price: 300, Stock: &stock, Product: "hoody", Size: "M",
+						Category: "clothing", Description: "Warm", Tags: []string{"winter"},
+					}}, nil)
+
+				h.catalog = mock
+			},
+		},
+		"search_bad_sort": {
+			method: http.MethodGet,
+			path:   "/api/items?sort=popular",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"search_bad_limit": {
+			method: http.MethodGet,
+			path:   "/api/items?limit=many",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"search_limit_too_big": {
+			method: http.MethodGet,
+			path:   "/api/items?limit=1000",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"create_variant": {
+			method: http.MethodPost,
+			path:   "/api/admin/items",
+			rqBody: `{"name":"hoody-xl","price":120,"product":"hoody","size":"XL"}`,
+
+			respCode: 201,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CreateItem(gomock.Any(),
+					&models.CreateItemRequest{Name: "hoody-xl", Price: &price, Product: "hoody", Size: "XL"}).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"create_variant_exists": {
+			method: http.MethodPost,
+			path:   "/api/admin/items",
+			rqBody: `{"name":"hoody-xl2","price":120,"product":"hoody","size":"XL"}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Item already exists"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CreateItem(gomock.Any(), gomock.Any()).Return(models.ErrItemExists)
+
+				h.catalog = mock
+			},
+		},
+		"update_product": {
+			method: http.MethodPut,
+			path:   "/api/admin/products/{product}",
+			rqBody: `{"category":"clothing","description":"Warm","tags":["winter","cotton"]}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().UpdateProduct(gomock.Any(), "hoody",
+					&models.ProductRequest{Category: "clothing", Description: "Warm", Tags: []string{"winter", "cotton"}}).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"update_product_empty_tag": {
+			method: http.MethodPut,
+			path:   "/api/admin/products/{product}",
+			rqBody: `{"tags":[""]}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"update_product_unknown": {
+			method: http.MethodPut,
+			path:   "/api/admin/products/{product}",
+			rqBody: `{}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Item not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().UpdateProduct(gomock.Any(), "hoody", &models.ProductRequest{}).Return(models.ErrItemNotFound)
+
+				h.catalog = mock
+			},
+		},
+		"create": {
+			method: http.MethodPost,
+			path:   "/api/admin/items",
+			rqBody: `{"name":"sticker","price":120}`,
+
+			respCode: 201,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CreateItem(gomock.Any(), &models.CreateItemRequest{Name: "sticker", Price: &price}).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"create_no_price": {
+			method: http.MethodPost,
+			path:   "/api/admin/items",
+			rqBody: `{"name":"sticker"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"create_exists": {
+			method: http.MethodPost,
+			path:   "/api/admin/items",
+			rqBody: `{"name":"cup","price":120}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Item already exists"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CreateItem(gomock.Any(), gomock.Any()).Return(models.ErrItemExists)
+
+				h.catalog = mock
+			},
+		},
+		"price": {
+			method: http.MethodPut,
+			path:   "/api/admin/items/{item}/price",
+			rqBody: `{"price":120}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().SetPrice(gomock.Any(), "cup", 120).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"price_negative": {
+			method: http.MethodPut,
+			path:   "/api/admin/items/{item}/price",
+			rqBody: `{"price":-1}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"stock": {
+			method: http.MethodPut,
+			path:   "/api/admin/items/{item}/stock",
+			rqBody: `{"stock":120}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().SetStock(gomock.Any(), "cup", &models.ItemStockRequest{Stock: &price}).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"stock_zero_limit": {
+			method: http.MethodPut,
+			path:   "/api/admin/items/{item}/stock",
+			rqBody: `{"stock":10,"maxPerUser":0}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"retire_unknown": {
+			method: http.MethodPost,
+			path:   "/api/admin/items/{item}/retire",
+
+			respCode: 400,
+			respBody: `{"errors":"Item not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().Retire(gomock.Any(), "cup").Return(models.ErrItemNotFound)
+
+				h.catalog = mock
+			},
+		},
+		"restore": {
+			method: http.MethodPost,
+			path:   "/api/admin/items/{item}/restore",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().Restore(gomock.Any(), "cup").Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"prices": {
+			method: http.MethodGet,
+			path:   "/api/admin/items/{item}/prices",
+
+			respCode: 200,
+			respBody: `[{"id":2,"price":10,"from":"2025-02-01T00:00:00Z","until":"2025-02-02T00:00:00Z","createdAt":"2025-01-31T00:00:00Z"},` +
+				`{"id":1,"price":20,"from":"2025-01-01T00:00:00Z","createdAt":"2025-01-01T00:00:00Z"}]`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+				until := day(2, 2)
+				mock.EXPECT().Prices(gomock.Any(), "cup").Return([]models.ItemPrice{
+					{ID: 2, Price: 10, From: day(2, 1), Until: &until, CreatedAt: day(1, 31)},
+					{ID: 1, Price: 20, From: day(1, 1), CreatedAt: day(1, 1)},
+				}, nil)
+
+				h.catalog = mock
+			},
+		},
+		"schedule_sale": {
+			method: http.MethodPost,
+			path:   "/api/admin/items/{item}/prices",
+			rqBody: `{"price":10,"from":"2025-02-01T00:00:00Z","until":"2025-02-02T00:00:00Z"}`,
+
+			respCode: 201,
+			respBody: `{"id":2,"price":10,"from":"2025-02-01T00:00:00Z","until":"2025-02-02T00:00:00Z","createdAt":"2025-01-31T00:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				from, until := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+				sale := 10
+				mock.EXPECT().SchedulePrice(gomock.Any(), "cup", &models.SchedulePriceRequest{Price: &sale, From: &from, Until: &until}).
+					Return(&models.ItemPrice{ID: 2, Price: 10, From: from, Until: &until, CreatedAt: from.Add(-24 * time.Hour)}, nil)
+
+				h.catalog = mock
+			},
+		},
+		"schedule_in_past": {
+			method: http.MethodPost,
+			path:   "/api/admin/items/{item}/prices",
+			rqBody: `{"price":10,"from":"2020-01-01T00:00:00Z"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Invalid price schedule"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().SchedulePrice(gomock.Any(), "cup", gomock.Any()).Return(nil, models.ErrInvalidSchedule)
+
+				h.catalog = mock
+			},
+		},
+		"schedule_no_price": {
+			method: http.MethodPost,
+			path:   "/api/admin/items/{item}/prices",
+			rqBody: `{"from":"2030-01-01T00:00:00Z"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"cancel_price": {
+			method: http.MethodDelete,
+			path:   "/api/admin/items/{item}/prices/{id}",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CancelPrice(gomock.Any(), "cup", int64(3)).Return(nil)
+
+				h.catalog = mock
+			},
+		},
+		"cancel_effective_price": {
+			method: http.MethodDelete,
+			path:   "/api/admin/items/{item}/prices/{id}",
+
+			respCode: 400,
+			respBody: `{"errors":"Scheduled price not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockcatalogUsecase(ctrl)
+
+				mock.EXPECT().CancelPrice(gomock.Any(), "cup", int64(3)).Return(models.ErrPriceNotFound)
+
+				h.catalog = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.rqBody))
 			require.NoError(t, err)
+			rq.SetPathValue("item", "cup")
+			rq.SetPathValue("id", "3")
+			rq.SetPathValue("product", "hoody")
+
+			switch rq.URL.Path {
+			case "/api/items":
+				h.handleListItems(resp, rq)
+			case "/api/admin/items":
+				if tc.method == http.MethodGet {
+					h.handleAdminListItems(resp, rq)
+				} else {
+					h.handleCreateItem(resp, rq)
+				}
+			case "/api/admin/products/{product}":
+				h.handleUpdateProduct(resp, rq)
+			case "/api/admin/items/{item}/price":
+				h.handleSetPrice(resp, rq)
+			case "/api/admin/items/{item}/prices":
+				if tc.method == http.MethodGet {
+					h.handleListPrices(resp, rq)
+				} else {
+					h.handleSchedulePrice(resp, rq)
+				}
+			case "/api/admin/items/{item}/prices/{id}":
+				h.handleCancelPrice(resp, rq)
+			case "/api/admin/items/{item}/stock":
+				h.handleSetStock(resp, rq)
+			case "/api/admin/items/{item}/retire":
+				h.handleRetireItem(resp, rq)
+			default:
+				h.handleRestoreItem(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
+func Test_Orders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	placedAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		method string
+		path   string
+		rqBody string
+		id     string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"order": {
+			method: http.MethodPost,
+			path:   "/api/orders",
+			rqBody: `{"lines":[{"item":"socks","quantity":10}]}`,
+
+			respCode: 201,
+			respBody: `{"id":1,"user":"u1","status":"placed","lines":[{"item":"socks","quantity":10,"price":10}],"total":100,"createdAt":"2025-02-01T10:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().PlaceOrder(gomock.Any(), "u1", &models.OrderRequest{Lines: []models.OrderLine{{Item: "socks", Quantity: 10}}}).
+					Return(&models.Order{
+						ID: 1, User: "u1", Status: models.OrderPlaced, CreatedAt: placedAt,
+						Lines: []models.OrderLine{{Item: "socks", Quantity: 10, Price: 10}}, Total: 100,
+					}, nil)
+
+				h.orders = mock
+			},
+		},
+		"order_empty": {
+			method: http.MethodPost,
+			path:   "/api/orders",
+			rqBody: `{"lines":[]}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"order_zero_quantity": {
+			method: http.MethodPost,
+			path:   "/api/orders",
+			rqBody: `{"lines":[{"item":"socks","quantity":0}]}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"order_line_failed": { // указывается первая невыполнимая строка
+			method: http.MethodPost,
+			path:   "/api/orders",
+			rqBody: `{"lines":[{"item":"socks","quantity":1},{"item":"pink-hoody","quantity":1}]}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Item is out of stock: pink-hoody"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().PlaceOrder(gomock.Any(), "u1", gomock.Any()).
+					Return(nil, &models.LineError{Item: "pink-hoody", Err: models.ErrOutOfStock})
+
+				h.orders = mock
+			},
+		},
+		"order_no_money": {
+			method: http.MethodPost,
+			path:   "/api/orders",
+			rqBody: `{"lines":[{"item":"socks","quantity":100}]}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Not enough coins: socks"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().PlaceOrder(gomock.Any(), "u1", gomock.Any()).
+					Return(nil, &models.LineError{Item: "socks", Err: errors.Join(models.ErrNoMoney, errors.New("constraint"))})
+
+				h.orders = mock
+			},
+		},
+		"gift": {
+			method: http.MethodPost,
+			path:   "/api/gifts",
+			rqBody: `{"toUser":"u2","item":"cup","message":"happy birthday"}`,
+
+			respCode: 201,
+			respBody: `{"id":2,"user":"u1","status":"placed","lines":[{"item":"cup","quantity":1,"price":20}],"total":20,"recipient":"u2","message":"happy birthday","createdAt":"2025-02-01T10:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Gift(gomock.Any(), "u1", &models.GiftRequest{To: "u2", Item: "cup", Message: "happy birthday"}).
+					Return(&models.Order{
+						ID: 2, User: "u1", Status: models.OrderPlaced, CreatedAt: placedAt, Recipient: "u2", Message: "happy birthday",
+						Lines: []models.OrderLine{{Item: "cup", Quantity: 1, Price: 20}}, Total: 20,
+					}, nil)
+
+				h.orders = mock
+			},
+		},
+		"gift_self": {
+			method: http.MethodPost,
+			path:   "/api/gifts",
+			rqBody: `{"toUser":"u1","item":"cup"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"gift_deactivated": {
+			method: http.MethodPost,
+			path:   "/api/gifts",
+			rqBody: `{"toUser":"u3","item":"cup"}`,
+
+			respCode: 403,
+			respBody: `{"errors":"Account is deactivated"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Gift(gomock.Any(), "u1", gomock.Any()).Return(nil, models.ErrUserDeactivated)
+
+				h.orders = mock
+			},
+		},
+		"cart": {
+			method: http.MethodGet,
+			path:   "/api/cart",
+
+			respCode: 200,
+			respBody: `{"lines":[{"item":"cup","quantity":2,"price":20}],"total":40}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Cart(gomock.Any(), "u1").
+					Return(&models.Cart{Lines: []models.OrderLine{{Item: "cup", Quantity: 2, Price: 20}}, Total: 40}, nil)
+
+				h.orders = mock
+			},
+		},
+		"cart_add": {
+			method: http.MethodPost,
+			path:   "/api/cart/items",
+			rqBody: `{"item":"cup","quantity":2}`,
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().AddToCart(gomock.Any(), "u1", &models.OrderLine{Item: "cup", Quantity: 2}).Return(nil)
+
+				h.orders = mock
+			},
+		},
+		"cart_add_unknown": {
+			method: http.MethodPost,
+			path:   "/api/cart/items",
+			rqBody: `{"item":"unicorn","quantity":1}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Item not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().AddToCart(gomock.Any(), "u1", gomock.Any()).Return(models.ErrItemNotFound)
+
+				h.orders = mock
+			},
+		},
+		"cart_remove": {
+			method: http.MethodDelete,
+			path:   "/api/cart/items/{item}",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().RemoveFromCart(gomock.Any(), "u1", "cup").Return(nil)
+
+				h.orders = mock
+			},
+		},
+		"checkout_empty": {
+			method: http.MethodPost,
+			path:   "/api/cart/checkout",
+
+			respCode: 400,
+			respBody: `{"errors":"Cart is empty"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Checkout(gomock.Any(), "u1", "").Return(nil, models.ErrCartEmpty)
+
+				h.orders = mock
+			},
+		},
+		"checkout": {
+			method: http.MethodPost,
+			path:   "/api/cart/checkout?promo=CUP10",
+
+			respCode: 201,
+			respBody: `{"id":2,"user":"u1","status":"placed","lines":[{"item":"cup","quantity":2,"price":18,"discount":2}],"total":36,"promo":"CUP10","createdAt":"2025-02-01T10:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Checkout(gomock.Any(), "u1", "CUP10").
+					Return(&models.Order{
+						ID: 2, User: "u1", Status: models.OrderPlaced, CreatedAt: placedAt, Promo: "CUP10",
+						Lines: []models.OrderLine{{Item: "cup", Quantity: 2, Price: 18, Discount: 2}}, Total: 36,
+					}, nil)
+
+				h.orders = mock
+			},
+		},
+		"list": {
+			method: http.MethodGet,
+			path:   "/api/orders",
+
+			respCode: 200,
+			respBody: `[{"id":2,"user":"u1","status":"ready","lines":[{"item":"cup","quantity":2,"price":20}],"total":40,"createdAt":"2025-02-01T10:00:00Z"}]`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().ListOrders(gomock.Any(), "u1").Return([]models.Order{{
+					ID: 2, User: "u1", Status: models.OrderReady, CreatedAt: placedAt,
+					Lines: []models.OrderLine{{Item: "cup", Quantity: 2, Price: 20}}, Total: 40,
+				}}, nil)
+
+				h.orders = mock
+			},
+		},
+		"list_empty": {
+			method: http.MethodGet,
+			path:   "/api/orders",
+
+			respCode: 200,
+			respBody: `[]`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().ListOrders(gomock.Any(), "u1").Return([]models.Order{}, nil)
+
+				h.orders = mock
+			},
+		},
+		"admin_list": {
+			method: http.MethodGet,
+			path:   "/api/admin/orders?status=placed",
 
-			rq = rq.WithContext(token.ContextWithUser(rq.Context(), tc.userName))
-			h.handleInfo(resp, rq)
+			respCode: 200,
+			respBody: `[]`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().AllOrders(gomock.Any(), "placed").Return([]models.Order{}, nil)
+
+				h.orders = mock
+			},
+		},
+		"admin_list_unknown_status": {
+			method: http.MethodGet,
+			path:   "/api/admin/orders?status=lost",
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"set_status": {
+			method: http.MethodPut,
+			path:   "/api/admin/orders/{id}/status",
+			rqBody: `{"status":"delivered"}`,
+
+			respCode: 200,
+			respBody: `{"id":7,"user":"u2","status":"delivered","lines":[],"total":0,"createdAt":"2025-02-01T10:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().SetStatus(gomock.Any(), int64(7), models.OrderDelivered).Return(&models.Order{
+					ID: 7, User: "u2", Status: models.OrderDelivered, CreatedAt: placedAt, Lines: []models.OrderLine{},
+				}, nil)
+
+				h.orders = mock
+			},
+		},
+		"set_status_cancel": { // отмена требует возврата монет, обычной сменой статуса недоступна
+			method: http.MethodPut,
+			path:   "/api/admin/orders/{id}/status",
+			rqBody: `{"status":"cancelled"}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"set_status_bad_id": {
+			method: http.MethodPut,
+			path:   "/api/admin/orders/{id}/status",
+			rqBody: `{"status":"ready"}`,
+			id:     "seven",
+
+			respCode: 404,
+			respBody: `{"errors":"Order not found"}`,
+		},
+		"cancel": {
+			method: http.MethodPost,
+			path:   "/api/orders/{id}/cancel",
+
+			respCode: 200,
+			respBody: `{"id":7,"user":"u1","status":"cancelled","lines":[{"item":"cup","quantity":1,"price":20}],"total":20,"createdAt":"2025-02-01T10:00:00Z"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Cancel(gomock.Any(), "u1", int64(7)).Return(&models.Order{
+					ID: 7, User: "u1", Status: models.OrderCancelled, CreatedAt: placedAt,
+					Lines: []models.OrderLine{{Item: "cup", Quantity: 1, Price: 20}}, Total: 20,
+				}, nil)
+
+				h.orders = mock
+			},
+		},
+		"cancel_expired": {
+			method: http.MethodPost,
+			path:   "/api/orders/{id}/cancel",
+
+			respCode: 409,
+			respBody: `{"errors":"Cancellation window has expired"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Cancel(gomock.Any(), "u1", int64(7)).Return(nil, models.ErrCancelExpired)
+
+				h.orders = mock
+			},
+		},
+		"cancel_foreign": { // чужой заказ не отличается от несуществующего
+			method: http.MethodPost,
+			path:   "/api/orders/{id}/cancel",
+
+			respCode: 404,
+			respBody: `{"errors":"Order not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().Cancel(gomock.Any(), "u1", int64(7)).Return(nil, models.ErrOrderNotFound)
+
+				h.orders = mock
+			},
+		},
+		"admin_cancel_cancelled": {
+			method: http.MethodPost,
+			path:   "/api/admin/orders/{id}/cancel",
+
+			respCode: 409,
+			respBody: `{"errors":"Order status does not allow this"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().AdminCancel(gomock.Any(), int64(7)).Return(nil, models.ErrOrderStatus)
+
+				h.orders = mock
+			},
+		},
+		"set_status_conflict": {
+			method: http.MethodPut,
+			path:   "/api/admin/orders/{id}/status",
+			rqBody: `{"status":"ready"}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Order status does not allow this"}`,
+
+			init: func(h *handle) {
+				mock := NewMockordersUsecase(ctrl)
+
+				mock.EXPECT().SetStatus(gomock.Any(), int64(7), models.OrderReady).Return(nil, models.ErrOrderStatus)
+
+				h.orders = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+			rq.SetPathValue("item", "cup")
+			if tc.id == "" {
+				tc.id = "7"
+			}
+			rq.SetPathValue("id", tc.id)
+			rq = rq.WithContext(token.ContextWithUser(rq.Context(), "u1"))
+
+			switch tc.method + " " + rq.URL.Path {
+			case "POST /api/orders":
+				h.handlePlaceOrder(resp, rq)
+			case "GET /api/orders":
+				h.handleListOrders(resp, rq)
+			case "GET /api/admin/orders":
+				h.handleAdminListOrders(resp, rq)
+			case "PUT /api/admin/orders/{id}/status":
+				h.handleSetOrderStatus(resp, rq)
+			case "POST /api/orders/{id}/cancel":
+				h.handleCancelOrder(resp, rq)
+			case "POST /api/admin/orders/{id}/cancel":
+				h.handleAdminCancelOrder(resp, rq)
+			case "POST /api/gifts":
+				h.handleGift(resp, rq)
+			case "GET /api/cart":
+				h.handleCart(resp, rq)
+			case "POST /api/cart/items":
+				h.handleAddToCart(resp, rq)
+			case "DELETE /api/cart/items/{item}":
+				h.handleRemoveFromCart(resp, rq)
+			default:
+				h.handleCheckout(resp, rq)
+			}
 
 			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
 			require.Equal(t, tc.respCode, resp.Code)
@@ -360,43 +2399,267 @@ func Test_Info(t *testing.T) {
 	}
 }
 
-/*
-HTTP/1.1 200 OK
-Date: Thu, 13 Feb 2025 16:35:46 GMT
-Content-Length: 102
-Content-Type: text/plain; charset=utf-8
-Connection: close
+func Test_Promos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-{
-  "coins": 400,
-  "inventory": [
-    {
-      "type": "hoody",
-      "quantity": 2
-    }
-  ],
-  "coinHistory": {
-    "received": null,
-    "sent": null
-  }
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		method string
+		path   string
+		rqBody string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"list": {
+			method: http.MethodGet,
+			path:   "/api/admin/promos",
+
+			respCode: 200,
+			respBody: `[{"code":"HOODY20","kind":"percent","amount":20,"items":["hoody"],"from":"2025-02-01T00:00:00Z","uses":3}]`,
+
+			init: func(h *handle) {
+				mock := NewMockpromosUsecase(ctrl)
+
+				mock.EXPECT().ListPromos(gomock.Any()).Return([]models.Promo{{
+					Code: "HOODY20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, From: from, Uses: 3,
+				}}, nil)
+
+				h.promos = mock
+			},
+		},
+		"create": {
+			method: http.MethodPost,
+			path:   "/api/admin/promos",
+			rqBody: `{"code":"hoody20","kind":"percent","amount":20,"items":["hoody"],"maxUsesPerUser":1}`,
+
+			respCode: 201,
+			respBody: `{"code":"HOODY20","kind":"percent","amount":20,"items":["hoody"],"from":"2025-02-01T00:00:00Z","maxUsesPerUser":1,"uses":0}`,
+
+			init: func(h *handle) {
+				mock := NewMockpromosUsecase(ctrl)
+
+				one := 1
+				mock.EXPECT().CreatePromo(gomock.Any(), &models.CreatePromoRequest{
+					Code: "hoody20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, MaxUsesPerUser: &one,
+				}).Return(&models.Promo{
+					Code: "HOODY20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, From: from, MaxUsesPerUser: &one,
+				}, nil)
+
+				h.promos = mock
+			},
+		},
+		"create_unknown_kind": {
+			method: http.MethodPost,
+			path:   "/api/admin/promos",
+			rqBody: `{"code":"x","kind":"bogo","amount":1}`,
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"create_exists": {
+			method: http.MethodPost,
+			path:   "/api/admin/promos",
+			rqBody: `{"code":"x","kind":"fixed","amount":1}`,
+
+			respCode: 409,
+			respBody: `{"errors":"Promo code already exists"}`,
+
+			init: func(h *handle) {
+				mock := NewMockpromosUsecase(ctrl)
+
+				mock.EXPECT().CreatePromo(gomock.Any(), gomock.Any()).Return(nil, models.ErrPromoExists)
+
+				h.promos = mock
+			},
+		},
+		"disable_unknown": {
+			method: http.MethodPost,
+			path:   "/api/admin/promos/{code}/disable",
+
+			respCode: 400,
+			respBody: `{"errors":"Invalid promo code"}`,
+
+			init: func(h *handle) {
+				mock := NewMockpromosUsecase(ctrl)
+
+				mock.EXPECT().DisablePromo(gomock.Any(), "HOODY20").Return(models.ErrInvalidPromo)
+
+				h.promos = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.rqBody))
+			require.NoError(t, err)
+			rq.SetPathValue("code", "HOODY20")
+
+			switch tc.method + " " + tc.path {
+			case "GET /api/admin/promos":
+				h.handleListPromos(resp, rq)
+			case "POST /api/admin/promos":
+				h.handleCreatePromo(resp, rq)
+			default:
+				h.handleDisablePromo(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
 }
-  ---------------
-{
-  "coins": 360,
-  "inventory": [
-    {
-      "type": "hoody",
-      "quantity": 2
-    }
-  ],
-  "coinHistory": {
-    "received": null,
-    "sent": [
-      {
-        "toUser": "u1",
-        "amount": 40
-      }
-    ]
-  }
+
+func Test_Wishlist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		method string
+		path   string
+
+		respCode int
+		respBody string
+
+		init func(*handle)
+	}{
+		"list": {
+			method: http.MethodGet,
+			path:   "/api/wishlist",
+
+			respCode: 200,
+			respBody: `[{"item":"pink-hoody","price":500,"affordable":false,"available":true,"addedAt":"2025-02-01T10:00:00Z"}]`,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().List(gomock.Any(), "u1").
+					Return([]models.WishlistItem{{Item: "pink-hoody", Price: 500, Available: true, AddedAt: at}}, nil)
+
+				h.wishlist = mock
+			},
+		},
+		"add": {
+			method: http.MethodPut,
+			path:   "/api/wishlist/{item}",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().Add(gomock.Any(), "u1", "pink-hoody").Return(nil)
+
+				h.wishlist = mock
+			},
+		},
+		"add_unknown": {
+			method: http.MethodPut,
+			path:   "/api/wishlist/{item}",
+
+			respCode: 400,
+			respBody: `{"errors":"Item not found"}`,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().Add(gomock.Any(), "u1", "pink-hoody").Return(models.ErrItemNotFound)
+
+				h.wishlist = mock
+			},
+		},
+		"remove": {
+			method: http.MethodDelete,
+			path:   "/api/wishlist/{item}",
+
+			respCode: 200,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().Remove(gomock.Any(), "u1", "pink-hoody").Return(nil)
+
+				h.wishlist = mock
+			},
+		},
+		"notifications": {
+			method: http.MethodGet,
+			path:   "/api/notifications",
+
+			respCode: 200,
+			respBody: `[{"id":3,"kind":"affordable","item":"pink-hoody","createdAt":"2025-02-01T10:00:00Z","read":false}]`,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().Notifications(gomock.Any(), "u1").
+					Return([]models.Notification{{ID: 3, Kind: models.NotifyAffordable, Item: "pink-hoody", CreatedAt: at}}, nil)
+
+				h.wishlist = mock
+			},
+		},
+		"read": {
+			method: http.MethodPost,
+			path:   "/api/notifications/read",
+
+			respCode: 500,
+			respBody: `{"errors":"Internal server error"}`,
+
+			init: func(h *handle) {
+				mock := NewMockwishlistUsecase(ctrl)
+
+				mock.EXPECT().ReadNotifications(gomock.Any(), "u1").Return(models.ErrGeneric)
+
+				h.wishlist = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{validate: validator.New()}
+
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			rq.SetPathValue("item", "pink-hoody")
+			rq = rq.WithContext(token.ContextWithUser(rq.Context(), "u1"))
+
+			switch tc.method + " " + tc.path {
+			case "GET /api/wishlist":
+				h.handleWishlist(resp, rq)
+			case "PUT /api/wishlist/{item}":
+				h.handleAddToWishlist(resp, rq)
+			case "DELETE /api/wishlist/{item}":
+				h.handleRemoveFromWishlist(resp, rq)
+			case "GET /api/notifications":
+				h.handleNotifications(resp, rq)
+			default:
+				h.handleReadNotifications(resp, rq)
+			}
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
 }
-*/
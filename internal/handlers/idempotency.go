@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/token"
+)
+
+const maxIdempotencyKey = 255
+
+// idempotent must be wrapped by authMiddleware. Requests with Idempotency-Key header
+// run once per user key, repeats get the stored response with Idempotent-Replayed header.
+func (h *handle) idempotent(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			f(w, r)
+
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			handleError(r.Context(), w, models.ErrNoRows)
+
+			return
+		}
+		logger.AddField(r.Context(), "idempotency_key", key)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(r.Context(), w, err)
+
+			return
+		}
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(body)
+
+		rq := &models.IdempotentRequest{User: token.UserFromContext(r.Context()), Key: key, Hash: hash.Sum(nil)}
+		resp, replayed, err := h.idempotency.Do(r.Context(), rq, func(ctx context.Context) *models.StoredResponse {
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			f(rec, r.WithContext(ctx))
+
+			return &models.StoredResponse{Status: rec.status, Body: rec.body.Bytes()}
+		})
+		if err != nil {
+			handleError(r.Context(), w, err)
+
+			return
+		}
+
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		w.WriteHeader(resp.Status)
+		if _, err = w.Write(resp.Body); err != nil {
+			logger.AddError(r.Context(), err)
+		}
+	}
+}
+
+// recorder holds the response until it is stored, headers go to the client as is.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	r.status = statusCode
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b) //nolint:wrapcheck
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func (h *handle) handleVerifyMFA(w http.ResponseWriter, r *http.Request) {
+	rq := &models.MFAVerifyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	rq.ClientIP = clientIP(r)
+
+	resp, err := h.auth.VerifyMFA(r.Context(), rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleEnrollMFA(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.auth.EnrollMFA(r.Context())
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	rq := &models.MFACodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	rq.ClientIP = clientIP(r)
+
+	resp, err := h.auth.ConfirmMFA(r.Context(), rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleDisableMFA(w http.ResponseWriter, r *http.Request) {
+	rq := &models.MFACodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	rq.ClientIP = clientIP(r)
+
+	if err := h.auth.DisableMFA(r.Context(), rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
@@ -17,7 +17,7 @@ func (h *handle) authMiddleware(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// достать header, раскодировать, проверить
 		tokn := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
-		name, err := token.Check(tokn)
+		claims, err := token.Check(tokn)
 		if err != nil {
 			logger.AddError(r.Context(), err)
 
@@ -26,9 +26,24 @@ func (h *handle) authMiddleware(f http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		logger.AddField(r.Context(), "user", name)
+		logger.AddField(r.Context(), "user", claims.Subject)
 
-		r = r.WithContext(token.ContextWithUser(r.Context(), name))
+		ctx := token.ContextWithUser(r.Context(), claims.Subject)
+		r = r.WithContext(token.ContextWithClaims(ctx, claims))
+
+		f(w, r)
+	}
+}
+
+// requireRole must be wrapped by authMiddleware. Any of the roles is enough.
+func (h *handle) requireRole(f http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := token.ClaimsFromContext(r.Context())
+		if claims == nil || !claims.HasRole(roles...) {
+			handleError(r.Context(), w, models.ErrForbidden)
+
+			return
+		}
 
 		f(w, r)
 	}
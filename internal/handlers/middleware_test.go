@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,9 +11,11 @@ import (
 	"time"
 
 	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
 	"github.com/cxbelka/winter_2025/internal/token"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func Test_authMdw(t *testing.T) {
@@ -24,7 +28,8 @@ func Test_authMdw(t *testing.T) {
 		respCode int
 		respBody string
 
-		sleep time.Duration
+		sleep  time.Duration
+		revoke func(c *token.Claims)
 	}{
 		"no_header": {
 			respCode: 401,
@@ -44,6 +49,52 @@ func Test_authMdw(t *testing.T) {
 
 			sleep: 2 * time.Second,
 		},
+		"token_revoked": {
+			userName: "test",
+
+			respCode: 401,
+			respBody: `{"errors":"Unauthorized"}`,
+
+			revoke: func(c *token.Claims) {
+				token.Revoke(c.ID, c.ExpiresAt.Time)
+			},
+		},
+		"sessions_revoked": {
+			userName: "test2",
+
+			respCode: 401,
+			respBody: `{"errors":"Unauthorized"}`,
+
+			revoke: func(c *token.Claims) {
+				token.RevokeSubject(models.RevokedSession{
+					Login: c.Subject, RevokedAt: time.UnixMicro(c.IssuedAtMicro), ExpiresAt: time.Now().Add(time.Minute),
+				})
+			},
+		},
+		"relogin_after_revoke": { // вход сразу после отзыва сессий, в ту же секунду
+			userName: "test5",
+
+			respCode: 200,
+			respBody: "test5",
+
+			revoke: func(c *token.Claims) {
+				token.RevokeSubject(models.RevokedSession{
+					Login: c.Subject, RevokedAt: time.UnixMicro(c.IssuedAtMicro - 1), ExpiresAt: time.Now().Add(time.Minute),
+				})
+			},
+		},
+		"other_user_revoked": {
+			userName: "test3",
+
+			respCode: 200,
+			respBody: "test3",
+
+			revoke: func(c *token.Claims) {
+				token.RevokeSubject(models.RevokedSession{
+					Login: "test4", RevokedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute),
+				})
+			},
+		},
 	}
 
 	t.Setenv("JWT_TTL", "1s")
@@ -59,9 +110,15 @@ func Test_authMdw(t *testing.T) {
 			rq, err := http.NewRequest(http.MethodGet, ``, nil)
 			require.NoError(t, err)
 			if tc.userName != "" {
-				token, err := token.Create(tc.userName)
+				tokn, err := token.Create(tc.userName)
 				require.NoError(t, err)
-				rq.Header.Add("Authorization", "Bearer "+token)
+				rq.Header.Add("Authorization", "Bearer "+tokn)
+
+				if tc.revoke != nil {
+					claims, err := token.Check(tokn)
+					require.NoError(t, err)
+					tc.revoke(claims)
+				}
 			}
 
 			if tc.sleep > 0 {
@@ -77,6 +134,56 @@ func Test_authMdw(t *testing.T) {
 	}
 
 }
+func Test_requireRoleMdw(t *testing.T) {
+	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(token.UserFromContext(r.Context())))
+	}
+
+	testCases := map[string]struct {
+		roles    []string
+		respCode int
+		respBody string
+	}{
+		"no_roles": {
+			respCode: 403,
+			respBody: `{"errors":"Forbidden"}`,
+		},
+		"other_role": {
+			roles:    []string{models.RoleManager},
+			respCode: 403,
+			respBody: `{"errors":"Forbidden"}`,
+		},
+		"admin": {
+			roles:    []string{models.RoleManager, models.RoleAdmin},
+			respCode: 200,
+			respBody: "boss",
+		},
+	}
+
+	t.Setenv("JWT_SECRET", "test")
+	token.Reinit()
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodGet, ``, nil)
+			require.NoError(t, err)
+			tokn, err := token.Create("boss", tc.roles...)
+			require.NoError(t, err)
+			rq.Header.Add("Authorization", "Bearer "+tokn)
+
+			f := h.authMiddleware(h.requireRole(handlerFunc, models.RoleAdmin))
+			f(resp, rq)
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+		})
+	}
+}
+
 func Test_logMdw(t *testing.T) {
 	testCases := map[string]struct {
 		handler http.HandlerFunc
@@ -133,3 +240,174 @@ func Test_logMdw(t *testing.T) {
 	}
 
 }
+
+func Test_idempotentMdw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(b)
+	}
+
+	testCases := map[string]struct {
+		key string
+
+		respCode     int
+		respBody     string
+		respReplayed string
+
+		init func(*handle)
+	}{
+		"no_key": {
+			respCode: 201,
+			respBody: "body",
+		},
+		"long_key": {
+			key: strings.Repeat("k", 256),
+
+			respCode: 400,
+			respBody: `{"errors":"Bad request"}`,
+		},
+		"first": {
+			key: "k1",
+
+			respCode: 201,
+			respBody: "body",
+
+			init: func(h *handle) {
+				mock := NewMockidempotencyUsecase(ctrl)
+
+				mock.EXPECT().Do(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, rq *models.IdempotentRequest, f func(context.Context) *models.StoredResponse,
+					) (*models.StoredResponse, bool, error) {
+						require.Equal(t, "u1", rq.User)
+						require.Equal(t, "k1", rq.Key)
+						// ответ не уходит клиенту, пока не сохранён
+						resp := f(ctx)
+						require.Equal(t, &models.StoredResponse{Status: 201, Body: []byte("body")}, resp)
+
+						return resp, false, nil
+					})
+
+				h.idempotency = mock
+			},
+		},
+		"replayed": {
+			key: "k1",
+
+			respCode:     201,
+			respBody:     "stored",
+			respReplayed: "true",
+
+			init: func(h *handle) {
+				mock := NewMockidempotencyUsecase(ctrl)
+
+				mock.EXPECT().Do(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&models.StoredResponse{Status: 201, Body: []byte("stored")}, true, nil)
+
+				h.idempotency = mock
+			},
+		},
+		"other_request": {
+			key: "k1",
+
+			respCode: 422,
+			respBody: `{"errors":"Idempotency key was used for another request"}`,
+
+			init: func(h *handle) {
+				mock := NewMockidempotencyUsecase(ctrl)
+
+				mock.EXPECT().Do(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, false, models.ErrIdempotencyMismatch)
+
+				h.idempotency = mock
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h := &handle{}
+			if tc.init != nil {
+				tc.init(h)
+			}
+
+			resp := httptest.NewRecorder()
+			rq, err := http.NewRequest(http.MethodPost, `/api/sendCoin`, bytes.NewBufferString("body"))
+			require.NoError(t, err)
+			if tc.key != "" {
+				rq.Header.Set("Idempotency-Key", tc.key)
+			}
+
+			f := h.idempotent(handlerFunc)
+			f(resp, rq.WithContext(token.ContextWithUser(rq.Context(), "u1")))
+
+			require.Equal(t, tc.respBody, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, tc.respCode, resp.Code)
+			require.Equal(t, tc.respReplayed, resp.Header().Get("Idempotent-Replayed"))
+		})
+	}
+}
+
+func Test_routesIdempotent(t *testing.T) {
+	t.Setenv("JWT_TTL", "1m")
+	t.Setenv("JWT_SECRET", "test")
+	token.Reinit()
+
+	tokn, err := token.Create("u1", models.RoleAdmin)
+	require.NoError(t, err)
+	lg := zerolog.Nop()
+	// юзкейсы не заданы: до обработчика запрос со слишком длинным ключом не доходит
+	mx := New(&lg, nil, nil, nil, nil, nil, nil, nil)
+
+	routes := []string{
+		"POST /api/sendCoin",
+		"GET /api/buy/cup",
+		"POST /api/orders",
+		"POST /api/orders/1/cancel",
+		"POST /api/gifts",
+		"PUT /api/wishlist/cup",
+		"DELETE /api/wishlist/cup",
+		"POST /api/notifications/read",
+		"POST /api/cart/items",
+		"DELETE /api/cart/items/cup",
+		"POST /api/cart/checkout",
+		"PUT /api/admin/users/u2/roles/manager",
+		"DELETE /api/admin/users/u2/roles/manager",
+		"DELETE /api/admin/users/u2/sessions",
+		"POST /api/admin/users/u2/deactivate",
+		"POST /api/admin/users/u2/reactivate",
+		"PUT /api/admin/users/u2/transfer-limits",
+		"DELETE /api/admin/users/u2/transfer-limits",
+		"POST /api/admin/items",
+		"PUT /api/admin/products/hoody",
+		"PUT /api/admin/items/cup/price",
+		"POST /api/admin/items/cup/prices",
+		"DELETE /api/admin/items/cup/prices/1",
+		"PUT /api/admin/items/cup/stock",
+		"POST /api/admin/items/cup/retire",
+		"POST /api/admin/items/cup/restore",
+		"PUT /api/admin/orders/1/status",
+		"POST /api/admin/orders/1/cancel",
+		"POST /api/admin/promos",
+		"POST /api/admin/promos/SALE/disable",
+	}
+	for _, route := range routes {
+		t.Run(route, func(t *testing.T) {
+			method, path, _ := strings.Cut(route, " ")
+			rq, err := http.NewRequest(method, path, bytes.NewBufferString("{}"))
+			require.NoError(t, err)
+			rq.Header.Set("Authorization", "Bearer "+tokn)
+			rq.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+
+			resp := httptest.NewRecorder()
+			mx.ServeHTTP(resp, rq)
+
+			require.Equal(t, `{"errors":"Bad request"}`, strings.Trim(resp.Body.String(), "\n"))
+			require.Equal(t, 400, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/token"
+)
+
+func (h *handle) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	user := token.UserFromContext(r.Context())
+	rq := &models.OrderRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	order, err := h.orders.PlaceOrder(r.Context(), user, rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(order); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleGift(w http.ResponseWriter, r *http.Request) {
+	user := token.UserFromContext(r.Context())
+	rq := &models.GiftRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	logger.AddField(r.Context(), "to", rq.To)
+	logger.AddField(r.Context(), "item", rq.Item)
+
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if user == rq.To {
+		handleError(r.Context(), w, models.ErrNoRows)
+
+		return
+	}
+
+	order, err := h.orders.Gift(r.Context(), user, rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(order); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleCart(w http.ResponseWriter, r *http.Request) {
+	cart, err := h.orders.Cart(r.Context(), token.UserFromContext(r.Context()))
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(cart); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleAddToCart(w http.ResponseWriter, r *http.Request) {
+	user := token.UserFromContext(r.Context())
+	rq := &models.OrderLine{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	logger.AddField(r.Context(), "item", rq.Item)
+
+	if err := h.orders.AddToCart(r.Context(), user, rq); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRemoveFromCart(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	if err := h.orders.RemoveFromCart(r.Context(), token.UserFromContext(r.Context()), item); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	order, err := h.orders.Checkout(r.Context(), token.UserFromContext(r.Context()), r.URL.Query().Get("promo"))
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(order); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	list, err := h.orders.ListOrders(r.Context(), token.UserFromContext(r.Context()))
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleAdminListOrders(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if err := h.validate.Var(status, "omitempty,oneof=placed ready delivered cancelled"); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	list, err := h.orders.AllOrders(r.Context(), status)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleSetOrderStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := orderID(r)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	rq := &models.OrderStatusRequest{}
+	if err = json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err = h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	order, err := h.orders.SetStatus(r.Context(), id, rq.Status)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(order); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.cancelOrder(w, r, func(id int64) (*models.Order, error) {
+		return h.orders.Cancel(r.Context(), token.UserFromContext(r.Context()), id)
+	})
+}
+
+func (h *handle) handleAdminCancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.cancelOrder(w, r, func(id int64) (*models.Order, error) {
+		return h.orders.AdminCancel(r.Context(), id)
+	})
+}
+
+func (h *handle) cancelOrder(w http.ResponseWriter, r *http.Request, cancel func(id int64) (*models.Order, error)) {
+	id, err := orderID(r)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	order, err := cancel(id)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(order); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func orderID(r *http.Request) (int64, error) {
+	logger.AddField(r.Context(), "order", r.PathValue("id"))
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, models.ErrOrderNotFound
+	}
+
+	return id, nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func (h *handle) handleListPromos(w http.ResponseWriter, r *http.Request) {
+	list, err := h.promos.ListPromos(r.Context())
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleCreatePromo(w http.ResponseWriter, r *http.Request) {
+	rq := &models.CreatePromoRequest{}
+	if err := json.NewDecoder(r.Body).Decode(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	if err := h.validate.Struct(rq); err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+	logger.AddField(r.Context(), "promo", rq.Code)
+
+	promo, err := h.promos.CreatePromo(r.Context(), rq)
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(promo); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleDisablePromo(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	logger.AddField(r.Context(), "promo", code)
+
+	if err := h.promos.DisablePromo(r.Context(), code); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/token"
+)
+
+func (h *handle) handleWishlist(w http.ResponseWriter, r *http.Request) {
+	list, err := h.wishlist.List(r.Context(), token.UserFromContext(r.Context()))
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleAddToWishlist(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	if err := h.wishlist.Add(r.Context(), token.UserFromContext(r.Context()), item); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleRemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	item := r.PathValue("item")
+	logger.AddField(r.Context(), "item", item)
+
+	if err := h.wishlist.Remove(r.Context(), token.UserFromContext(r.Context()), item); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
+
+func (h *handle) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	list, err := h.wishlist.Notifications(r.Context(), token.UserFromContext(r.Context()))
+	if err != nil {
+		handleError(r.Context(), w, err)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		logger.AddError(r.Context(), err)
+	}
+}
+
+func (h *handle) handleReadNotifications(w http.ResponseWriter, r *http.Request) {
+	if err := h.wishlist.ReadNotifications(r.Context(), token.UserFromContext(r.Context())); err != nil {
+		handleError(r.Context(), w, err)
+	}
+}
@@ -1,10 +1,115 @@
 package models
 
+import "time"
+
+// роли пользователей, передаются в JWT.
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+)
+
+var Roles = []string{RoleAdmin, RoleManager}
+
+// CompanyPool is the service account receiving balance of deactivated users.
+const CompanyPool = "company-pool"
+
 type AuthReqest struct {
 	Username string `json:"username" validate:"required,alphanum"`
-	Password string `json:"password" validate:"required,alphanum"`
+	Password string `json:"password" validate:"required,max=128"`
+	ClientIP string `json:"-"` // адрес клиента для ограничения подбора пароля
 }
 
+type RegisterRequest struct {
+	Username   string `json:"username" validate:"required,alphanum"`
+	Password   string `json:"password" validate:"required,max=128"`
+	InviteCode string `json:"inviteCode"`
+	ClientIP   string `json:"-"`
+}
+
+// Registration is a new user to be stored. Empty InviteCode means
+// no invite is required, CheckAllowList restricts logins to the HR list.
+type Registration struct {
+	Login          string
+	PassHash       string
+	InviteCode     string
+	CheckAllowList bool
+}
+
+// AuthResponse carries only MFAToken if the second factor is required.
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	MFAToken     string `json:"mfaToken,omitempty"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"` // TOTP или код восстановления
+	ClientIP string `json:"-"`
+}
+
+type MFACodeRequest struct {
+	Code     string `json:"code" validate:"required,max=32"`
+	ClientIP string `json:"-"`
+}
+
+type MFAEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+}
+
+type MFARecoveryCodes struct {
+	Codes []string `json:"recoveryCodes"`
+}
+
+type TOTP struct {
+	Secret    string
+	Confirmed bool
+	LastStep  int64
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required,max=128"`
+	NewPassword     string `json:"newPassword" validate:"required,max=128"`
+	ClientIP        string `json:"-"`
+}
+
+type PasswordResetResponse struct {
+	Code      string    `json:"resetCode"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type ResetPasswordRequest struct {
+	Code        string `json:"resetCode" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,max=128"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// RefreshToken is a stored refresh token state. Used or revoked token being
+// presented again means it was stolen.
+type RefreshToken struct {
+	Login   string
+	Family  string
+	Used    bool
+	Revoked bool
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+	All          bool   `json:"all"` // завершить все сессии пользователя
+}
+
+type RevokedToken struct {
+	ID        string
+	Login     string
+	ExpiresAt time.Time
+}
+
+type RevokedSession struct {
+	Login     string
+	RevokedAt time.Time
+	ExpiresAt time.Time
 }
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Item is a variant of a product that is sold and stocked on its own,
+// e.g. hoody-m of hoody. nil Stock and MaxPerUser mean unlimited.
+type Item struct {
+	Name       string `json:"name"`
+	Price      int    `json:"price"`
+	Stock      *int   `json:"stock,omitempty"`
+	MaxPerUser *int   `json:"maxPerUser,omitempty"`
+	Retired    bool   `json:"retired,omitempty"` // только в админском списке
+
+	Product     string   `json:"product"`
+	Size        string   `json:"size,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// CreateItemRequest: omitted Product makes the item a product of its own.
+type CreateItemRequest struct {
+	Name       string `json:"name" validate:"required,max=64,printascii,excludesall=/?#%"`
+	Price      *int   `json:"price" validate:"required,gte=0"`
+	Stock      *int   `json:"stock" validate:"omitempty,gte=0"`
+	MaxPerUser *int   `json:"maxPerUser" validate:"omitempty,gt=0"`
+	Product    string `json:"product" validate:"max=64,excludesall=/?#%"`
+	Size       string `json:"size" validate:"max=16"`
+	Color      string `json:"color" validate:"max=32"`
+}
+
+// ItemFilter selects catalog page. Query matches name, description and tags.
+type ItemFilter struct {
+	Category string `validate:"max=64"`
+	Query    string `validate:"max=100"`
+	Sort     string `validate:"omitempty,oneof=name price -price"`
+	Limit    int    `validate:"gte=0,lte=100"` // 0 - по умолчанию
+	Offset   int    `validate:"gte=0"`
+}
+
+// ProductRequest replaces description of all product variants.
+type ProductRequest struct {
+	Category    string   `json:"category" validate:"max=64"`
+	Description string   `json:"description" validate:"max=2000"`
+	Tags        []string `json:"tags" validate:"max=20,dive,required,max=32"`
+}
+
+// ItemStockRequest replaces both limits, omitted field removes the limit.
+type ItemStockRequest struct {
+	Stock      *int `json:"stock" validate:"omitempty,gte=0"`
+	MaxPerUser *int `json:"maxPerUser" validate:"omitempty,gt=0"`
+}
+
+type ItemPriceRequest struct {
+	Price *int `json:"price" validate:"required,gte=0"`
+}
+
+// ItemPrice is a price history entry. Without Until the price stays in effect
+// until the next one, with Until it is a sale over the regular price.
+type ItemPrice struct {
+	ID        int64      `json:"id"`
+	Price     int        `json:"price"`
+	From      time.Time  `json:"from"`
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// SchedulePriceRequest: omitted From means now.
+type SchedulePriceRequest struct {
+	Price *int       `json:"price" validate:"required,gte=0"`
+	From  *time.Time `json:"from"`
+	Until *time.Time `json:"until"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // internal errors.
 var (
@@ -8,4 +11,48 @@ var (
 	ErrNoRows          = errors.New("no data")
 	ErrInvalidPassword = errors.New("wrong password")
 	ErrNoMoney         = errors.New("not enough coins")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTooManyAttempts = errors.New("too many attempts")
+
+	ErrWeakPassword       = errors.New("password does not meet policy")
+	ErrUserExists         = errors.New("user already exists")
+	ErrRegistrationDenied = errors.New("registration is not allowed")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnknownRole        = errors.New("unknown role")
+	ErrUserDeactivated    = errors.New("account is deactivated")
+	ErrInvalidOTP         = errors.New("invalid one-time code")
+	ErrMFAEnabled         = errors.New("two-factor authentication already enabled")
+
+	ErrItemExists      = errors.New("item already exists")
+	ErrItemNotFound    = errors.New("item not found")
+	ErrItemUnavailable = errors.New("item is not available")
+	ErrOutOfStock      = errors.New("item is out of stock")
+	ErrPurchaseLimit   = errors.New("purchase limit per user reached")
+	ErrInvalidSchedule = errors.New("invalid price schedule")
+	ErrPriceNotFound   = errors.New("scheduled price not found")
+	ErrCartEmpty       = errors.New("cart is empty")
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrOrderStatus     = errors.New("order status does not allow this")
+	ErrCancelExpired   = errors.New("cancellation window has expired")
+	ErrPromoExists     = errors.New("promo code already exists")
+	ErrInvalidPromo    = errors.New("promo code is not valid")
+	ErrPromoExhausted  = errors.New("promo code limit reached")
+	ErrLimitExceeded   = errors.New("transfer limit exceeded")
+
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with different request")
+	ErrInvalidCursor       = errors.New("invalid page cursor")
 )
+
+// RetryAfterError tells the client when the request may be repeated.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// HistoryFilter selects a page of history, newest first. From is inclusive, To is exclusive,
+// Cursor is NextCursor of the previous page.
+type HistoryFilter struct {
+	From         *time.Time
+	To           *time.Time
+	Counterparty string `validate:"omitempty,alphanum"` // только переводы
+	Item         string `validate:"max=64"`             // только покупки
+	Limit        int    `validate:"gte=0,lte=100"`      // 0 - по умолчанию
+	Cursor       string `validate:"max=100"`
+}
+
+// HistoryKey is the position of the last row of a page, encoded into the cursor.
+type HistoryKey struct {
+	Date time.Time
+	ID   int64
+}
+
+type TransferPage struct {
+	Transfers  []Transfer `json:"transfers"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// Purchase is a single unit in the inventory history. Gifts have From set, refunded ones are kept.
+type Purchase struct {
+	ID       int64     `json:"id"`
+	Item     string    `json:"item"`
+	Sum      int       `json:"sum"`
+	Discount int       `json:"discount,omitempty"`
+	Promo    string    `json:"promo,omitempty"`
+	OrderID  *int64    `json:"orderId,omitempty"`
+	From     string    `json:"fromUser,omitempty"`
+	Refunded bool      `json:"refunded"`
+	Date     time.Time `json:"date"`
+}
+
+type PurchasePage struct {
+	Purchases  []Purchase `json:"purchases"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// IdempotentRequest identifies a mutating request the client may repeat.
+// Key is unique per user, Hash covers method, URL and body.
+type IdempotentRequest struct {
+	User string
+	Key  string
+	Hash []byte
+}
+
+// StoredResponse is replayed to repeated requests.
+type StoredResponse struct {
+	Status int
+	Body   []byte
+}
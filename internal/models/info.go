@@ -1,18 +1,32 @@
 package models
 
+import "time"
+
 type InfoResponse struct {
 	Balance   int                   `json:"coins"`
 	Inventory []InventoryItem       `json:"inventory"`
 	Transfers InfoResponseTransfers `json:"coinHistory"`
+	Gifts     InfoResponseGifts     `json:"gifts"`
+}
+
+// InfoResponseGifts lists gifts, received ones are also counted in the inventory.
+type InfoResponseGifts struct {
+	Received []ReceivedGift `json:"received"`
+	Sent     []SentGift     `json:"sent"`
 }
 
 type InfoResponseTransfers struct {
 	Received []ReceivedTransfer `json:"received"`
 	Sent     []SentTransfer     `json:"sent"`
 }
+
+// InventoryItem: Type is the bought variant, Product groups variants.
 type InventoryItem struct {
-	Type string `json:"type"`
-	Qty  int    `json:"quantity"`
+	Type    string `json:"type"`
+	Qty     int    `json:"quantity"`
+	Product string `json:"product,omitempty"`
+	Size    string `json:"size,omitempty"`
+	Color   string `json:"color,omitempty"`
 }
 
 type ReceivedTransfer struct {
@@ -21,6 +35,48 @@ type ReceivedTransfer struct {
 }
 
 type SentTransfer struct {
+	To     string `json:"toUser"`
+	Amount int    `json:"amount"`
+}
+
+type SendCoinRequest struct {
 	To     string `json:"toUser" validate:"required,alphanum"`
 	Amount int    `json:"amount" validate:"required,gt=0"`
+	Memo   string `json:"memo,omitempty" validate:"max=200"`
+}
+
+const (
+	TransferIn  = "in"
+	TransferOut = "out"
+)
+
+// Transfer is a single transfer as seen by the user, Direction is TransferIn or TransferOut.
+type Transfer struct {
+	ID           int64     `json:"id"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       int       `json:"amount"`
+	Memo         string    `json:"memo,omitempty"`
+	Date         time.Time `json:"date"`
+}
+
+type ReceivedGift struct {
+	From    string    `json:"fromUser"`
+	Item    string    `json:"item"`
+	Message string    `json:"message,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+type SentGift struct {
+	To      string    `json:"toUser"`
+	Item    string    `json:"item"`
+	Message string    `json:"message,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+type GiftRequest struct {
+	To      string `json:"toUser" validate:"required,alphanum"`
+	Item    string `json:"item" validate:"required,max=64"`
+	Message string `json:"message" validate:"max=500"`
+	Promo   string `json:"promo,omitempty" validate:"max=64"`
 }
@@ -0,0 +1,42 @@
+package models
+
+// Names of transfer limits reported by LimitError.
+const (
+	LimitAmount       = "amount"
+	LimitDaily        = "daily"
+	LimitMonthly      = "monthly"
+	LimitCounterparty = "counterparty"
+	LimitHourly       = "hourly"
+)
+
+// TransferLimits: amounts are in coins, HourlyCount is the number of transfers, zero means no limit.
+// Daily and monthly limits are calendar periods in UTC, the hourly one is the last 60 minutes.
+type TransferLimits struct {
+	MaxAmount         int `json:"maxAmount"`
+	Daily             int `json:"daily"`
+	Monthly           int `json:"monthly"`
+	DailyCounterparty int `json:"dailyPerCounterparty"`
+	HourlyCount       int `json:"hourlyCount"`
+}
+
+// TransferLimitsOverride replaces configured limits for one user: nil keeps the configured one.
+type TransferLimitsOverride struct {
+	MaxAmount         *int `json:"maxAmount,omitempty" validate:"omitempty,gte=0"`
+	Daily             *int `json:"daily,omitempty" validate:"omitempty,gte=0"`
+	Monthly           *int `json:"monthly,omitempty" validate:"omitempty,gte=0"`
+	DailyCounterparty *int `json:"dailyPerCounterparty,omitempty" validate:"omitempty,gte=0"`
+	HourlyCount       *int `json:"hourlyCount,omitempty" validate:"omitempty,gte=0"`
+}
+
+// LimitError tells which transfer limit was exceeded.
+type LimitError struct {
+	Limit string
+}
+
+func (e *LimitError) Error() string {
+	return ErrLimitExceeded.Error() + ": " + e.Limit
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
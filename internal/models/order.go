@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// статусы заказа. placed -> ready -> delivered, отмена возможна до выдачи.
+const (
+	OrderPlaced    = "placed"
+	OrderReady     = "ready"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+)
+
+// OrderTransitions lists statuses the order may be moved from by the office team.
+var OrderTransitions = map[string][]string{
+	OrderReady:     {OrderPlaced},
+	OrderDelivered: {OrderPlaced, OrderReady},
+}
+
+// статусы, из которых заказ отменяется с возвратом монет. выданный товар возвращается только через офис.
+var (
+	UserCancellable  = []string{OrderPlaced, OrderReady}
+	AdminCancellable = []string{OrderPlaced, OrderReady, OrderDelivered}
+)
+
+type OrderLine struct {
+	Item     string `json:"item" validate:"required,max=64"`
+	Quantity int    `json:"quantity" validate:"required,gt=0,lte=100"`
+	Price    int    `json:"price"`              // списано за штуку, от клиента не принимается
+	Discount int    `json:"discount,omitempty"` // скидка за штуку по промокоду
+}
+
+type OrderRequest struct {
+	Lines []OrderLine `json:"lines" validate:"required,min=1,max=50,dive"`
+	Promo string      `json:"promo,omitempty" validate:"max=64"`
+}
+
+type Order struct {
+	ID        int64       `json:"id"`
+	User      string      `json:"user"`
+	Status    string      `json:"status"`
+	Lines     []OrderLine `json:"lines"`
+	Total     int         `json:"total"`
+	Promo     string      `json:"promo,omitempty"`
+	Recipient string      `json:"recipient,omitempty"` // подарок: товар получает другой сотрудник
+	Message   string      `json:"message,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=ready delivered"`
+}
+
+// Cart prices are current ones and may change before checkout.
+type Cart struct {
+	Lines []OrderLine `json:"lines"`
+	Total int         `json:"total"`
+}
+
+// LineError tells which order line could not be satisfied.
+type LineError struct {
+	Item string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return e.Item + ": " + e.Err.Error()
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
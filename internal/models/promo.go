@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// Promo: Amount is percent or coins off each unit, empty Items means any item.
+// Limits count orders the code was applied to.
+type Promo struct {
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Amount         int        `json:"amount"`
+	Items          []string   `json:"items,omitempty"`
+	From           time.Time  `json:"from"`
+	Until          *time.Time `json:"until,omitempty"`
+	MaxUses        *int       `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser,omitempty"`
+	Uses           int        `json:"uses"`
+	Disabled       bool       `json:"disabled,omitempty"`
+}
+
+type CreatePromoRequest struct {
+	Code           string     `json:"code" validate:"required,max=64,printascii,excludesall=/?#% "`
+	Kind           string     `json:"kind" validate:"required,oneof=percent fixed"`
+	Amount         int        `json:"amount" validate:"required,gt=0"`
+	Items          []string   `json:"items" validate:"omitempty,max=100,dive,required,max=64"`
+	From           *time.Time `json:"from"`
+	Until          *time.Time `json:"until"`
+	MaxUses        *int       `json:"maxUses" validate:"omitempty,gt=0"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser" validate:"omitempty,gt=0"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	NotifyAffordable = "affordable"
+	NotifyRestocked  = "restocked"
+)
+
+// WishlistItem: Affordable and Available are evaluated at request time.
+type WishlistItem struct {
+	Item       string    `json:"item"`
+	Price      int       `json:"price"`
+	Affordable bool      `json:"affordable"`
+	Available  bool      `json:"available"`
+	AddedAt    time.Time `json:"addedAt"`
+}
+
+// Notification tells that a wishlist item became affordable or is back on sale.
+type Notification struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Item      string    `json:"item"`
+	CreatedAt time.Time `json:"createdAt"`
+	Read      bool      `json:"read"`
+}
@@ -0,0 +1,101 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type Argon2Params struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultArgon2Params follows the OWASP minimal recommendation.
+var DefaultArgon2Params = Argon2Params{
+	Memory:  19 * 1024, //nolint:mnd
+	Time:    2,         //nolint:mnd
+	Threads: 1,
+	SaltLen: 16, //nolint:mnd
+	KeyLen:  32, //nolint:mnd
+}
+
+type argon2idScheme struct {
+	params Argon2Params
+}
+
+func (s *argon2idScheme) hash(password string) (string, error) {
+	salt := make([]byte, s.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.params.Time, s.params.Memory, s.params.Threads, s.params.KeyLen)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		Argon2id, argon2.Version,
+		s.params.Memory, s.params.Time, s.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (s *argon2idScheme) verify(password string, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (s *argon2idScheme) outdated(encoded string) bool {
+	p, salt, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return p.Memory < s.params.Memory || p.Time < s.params.Time || p.Threads < s.params.Threads ||
+		p.KeyLen < s.params.KeyLen || uint32(len(salt)) < s.params.SaltLen //nolint:gosec
+}
+
+// decodeArgon2id parses `$argon2id$v=19$m=...,t=...,p=...$<salt>$<key>`.
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != Argon2id { //nolint:mnd
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, errors.Join(ErrMalformedHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errors.Join(ErrMalformedHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, errors.Join(ErrMalformedHash, err)
+	}
+	p.SaltLen = uint32(len(salt)) //nolint:gosec
+	p.KeyLen = uint32(len(key))   //nolint:gosec
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,40 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const DefaultBcryptCost = 12
+
+type bcryptScheme struct {
+	cost int
+}
+
+func (s *bcryptScheme) hash(password string) (string, error) {
+	h, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return string(h), nil
+}
+
+func (s *bcryptScheme) verify(password string, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, errors.Join(ErrMalformedHash, err)
+	}
+}
+
+func (s *bcryptScheme) outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+
+	return err != nil || cost < s.cost
+}
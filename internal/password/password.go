@@ -0,0 +1,88 @@
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+
+	legacySHA512 = "sha512"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+)
+
+// scheme is a single hashing algorithm producing self-describing strings
+// in the `$<id>$<params>$...` form.
+type scheme interface {
+	hash(password string) (string, error)
+	verify(password string, encoded string) (bool, error)
+	outdated(encoded string) bool
+}
+
+// Hasher creates new hashes with the configured algorithm and verifies
+// hashes made by any known one, including legacy unsalted SHA512.
+type Hasher struct {
+	current   scheme
+	currentID string
+}
+
+// New returns a hasher for the algorithm (argon2id if empty).
+func New(algorithm string) (*Hasher, error) {
+	switch algorithm {
+	case Argon2id, "":
+		return &Hasher{current: &argon2idScheme{params: DefaultArgon2Params}, currentID: Argon2id}, nil
+	case Bcrypt:
+		return &Hasher{current: &bcryptScheme{cost: DefaultBcryptCost}, currentID: Bcrypt}, nil
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.hash(password)
+}
+
+func (h *Hasher) Verify(password string, encoded string) (bool, error) {
+	s, _, err := lookup(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	return s.verify(password, encoded)
+}
+
+// NeedsRehash reports whether the hash was made by another algorithm
+// or with weaker parameters than the current ones.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	_, id, err := lookup(encoded)
+	if err != nil || id != h.currentID {
+		return true
+	}
+
+	return h.current.outdated(encoded)
+}
+
+// lookup picks verifier by the hash prefix. Parameters are always read from the hash itself.
+func lookup(encoded string) (scheme, string, error) {
+	parts := strings.SplitN(encoded, "$", 3) //nolint:mnd
+	if len(parts) < 3 || parts[0] != "" {    //nolint:mnd
+		return nil, "", ErrMalformedHash
+	}
+
+	switch parts[1] {
+	case Argon2id:
+		return &argon2idScheme{}, Argon2id, nil
+	case "2a", "2b", "2y":
+		return &bcryptScheme{}, Bcrypt, nil
+	case legacySHA512:
+		return &sha512Scheme{}, legacySHA512, nil
+	default:
+		return nil, "", ErrUnknownAlgorithm
+	}
+}
@@ -0,0 +1,167 @@
+package password
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Hasher(t *testing.T) {
+	legacy := sha512.Sum512([]byte("p1"))
+
+	testCases := map[string]struct {
+		algorithm string
+		encoded   string // empty means hash "p1" with the algorithm
+
+		password string
+		ok       bool
+		err      error
+		rehash   bool
+	}{
+		"argon2id_valid": {
+			algorithm: Argon2id,
+			password:  "p1",
+			ok:        true,
+		},
+		"argon2id_wrong": {
+			algorithm: Argon2id,
+			password:  "p2",
+			ok:        false,
+		},
+		"bcrypt_valid": {
+			algorithm: Bcrypt,
+			password:  "p1",
+			ok:        true,
+		},
+		"bcrypt_wrong": {
+			algorithm: Bcrypt,
+			password:  "p2",
+			ok:        false,
+		},
+		"legacy_sha512": {
+			algorithm: Argon2id,
+			encoded:   "$sha512$" + hex.EncodeToString(legacy[:]),
+			password:  "p1",
+			ok:        true,
+			rehash:    true,
+		},
+		"legacy_sha512_wrong": {
+			algorithm: Argon2id,
+			encoded:   "$sha512$" + hex.EncodeToString(legacy[:]),
+			password:  "p2",
+			ok:        false,
+			rehash:    true,
+		},
+		"weak_argon2id": {
+			algorithm: Argon2id,
+			encoded:   "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$kFTYJNvoX0prmaSRWIP4Zq9eOGxDRQ9x2z3FfU4KK2w",
+			password:  "p2",
+			ok:        false,
+			rehash:    true,
+		},
+		"other_algorithm": {
+			algorithm: Bcrypt,
+			encoded:   "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$kFTYJNvoX0prmaSRWIP4Zq9eOGxDRQ9x2z3FfU4KK2w",
+			password:  "p2",
+			ok:        false,
+			rehash:    true,
+		},
+		"unknown": {
+			algorithm: Argon2id,
+			encoded:   "$md5$abc",
+			password:  "p1",
+			err:       ErrUnknownAlgorithm,
+			rehash:    true,
+		},
+		"malformed": {
+			algorithm: Argon2id,
+			encoded:   "plain",
+			password:  "p1",
+			err:       ErrMalformedHash,
+			rehash:    true,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			h, err := New(tc.algorithm)
+			require.NoError(t, err)
+
+			encoded := tc.encoded
+			if encoded == "" {
+				encoded, err = h.Hash("p1")
+				require.NoError(t, err)
+			}
+
+			ok, err := h.Verify(tc.password, encoded)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.rehash, h.NeedsRehash(encoded))
+		})
+	}
+}
+
+func Test_HashSalted(t *testing.T) {
+	h, err := New("")
+	require.NoError(t, err)
+
+	h1, err := h.Hash("p1")
+	require.NoError(t, err)
+	h2, err := h.Hash("p1")
+	require.NoError(t, err)
+
+	require.NotEqual(t, h1, h2)
+}
+
+func Test_UnknownAlgorithm(t *testing.T) {
+	_, err := New("md5")
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
+
+func Test_Policy(t *testing.T) {
+	testCases := map[string]struct {
+		login    string
+		password string
+		err      error
+	}{
+		"valid": {
+			login:    "u1",
+			password: "correct-horse",
+		},
+		"valid_unicode": {
+			login:    "u1",
+			password: "пароль-123",
+		},
+		"too_short": {
+			login:    "u1",
+			password: "Ab1!",
+			err:      ErrTooShort,
+		},
+		"too_long": {
+			login:    "u1",
+			password: strings.Repeat("Ab1", 25),
+			err:      ErrTooLong,
+		},
+		"one_class": {
+			login:    "u1",
+			password: "onlylowercase",
+			err:      ErrTooSimple,
+		},
+		"contains_login": {
+			login:    "Ivan",
+			password: "ivan-2025-ivan",
+			err:      ErrContainsLogin,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, DefaultPolicy.Validate(tc.login, tc.password), tc.err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrTooShort      = errors.New("password is too short")
+	ErrTooLong       = errors.New("password is too long")
+	ErrTooSimple     = errors.New("password must mix lower case, upper case, digits or symbols")
+	ErrContainsLogin = errors.New("password must not contain the username")
+)
+
+// Policy is checked for passwords chosen by users. MaxLength is in bytes
+// because bcrypt silently ignores everything after 72 bytes.
+type Policy struct {
+	MinLength int // в символах
+	MaxLength int // в байтах
+	Classes   int // сколько разных классов символов (строчные, заглавные, цифры, прочие) требуется
+}
+
+var DefaultPolicy = Policy{MinLength: 8, MaxLength: 72, Classes: 2} //nolint:mnd
+
+func (p Policy) Validate(login string, password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return ErrTooShort
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return ErrTooLong
+	}
+	if classes(password) < p.Classes {
+		return ErrTooSimple
+	}
+	if login != "" && strings.Contains(strings.ToLower(password), strings.ToLower(login)) {
+		return ErrContainsLogin
+	}
+
+	return nil
+}
+
+func classes(password string) int {
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
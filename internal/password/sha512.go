@@ -0,0 +1,32 @@
+package password
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// sha512Scheme verifies hashes created before salted hashing was introduced.
+// Migration stores them as `$sha512$<hex digest>`. New hashes are never produced.
+type sha512Scheme struct{}
+
+func (s *sha512Scheme) hash(_ string) (string, error) {
+	return "", ErrUnknownAlgorithm
+}
+
+func (s *sha512Scheme) verify(password string, encoded string) (bool, error) {
+	digest, err := hex.DecodeString(strings.TrimPrefix(encoded, "$"+legacySHA512+"$"))
+	if err != nil {
+		return false, errors.Join(ErrMalformedHash, err)
+	}
+
+	sum := sha512.Sum512([]byte(password))
+
+	return subtle.ConstantTimeCompare(digest, sum[:]) == 1, nil
+}
+
+func (s *sha512Scheme) outdated(_ string) bool {
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/cxbelka/winter_2025/internal/models"
@@ -18,27 +19,171 @@ func NewAuth(db *pgxpool.Pool) *auth { //nolint:revive
 	return &auth{db: db}
 }
 
-func (a *auth) CheckLogin(ctx context.Context, login string) ([]byte, error) {
-	var passwd []byte
-	err := a.db.QueryRow(ctx, `SELECT password FROM merch_shop.auth WHERE login = $1`, login).Scan(&passwd)
+// CheckLogin returns the password hash. For a deactivated user the hash comes along with
+// models.ErrUserDeactivated: deactivation is reported only to those who know the password.
+func (a *auth) CheckLogin(ctx context.Context, login string) (string, error) {
+	var (
+		passwd  string
+		deleted bool
+	)
+	err := conn(ctx, a.db).QueryRow(ctx,
+		`SELECT password, deleted_at IS NOT NULL FROM merch_shop.auth WHERE login = $1`,
+		login).Scan(&passwd, &deleted)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, models.ErrNoRows
+			return "", models.ErrNoRows
 		}
 
-		return nil, errors.Join(models.ErrGeneric, err)
+		return "", errors.Join(models.ErrGeneric, err)
+	}
+	if deleted {
+		return passwd, models.ErrUserDeactivated
 	}
 
 	return passwd, nil
 }
 
-func (a *auth) CreateUser(ctx context.Context, login string, pass string) error {
-	_, err := a.db.Exec(ctx,
-		`INSERT INTO merch_shop.auth (login, password, balance) VALUES ($1, SHA512($2), 1000)`,
-		login, pass)
+// RegisterUser creates user with the starting balance. Invite is consumed and
+// allow-list is checked in the same statement, so a failed insert keeps the invite.
+func (a *auth) RegisterUser(ctx context.Context, reg *models.Registration) error {
+	tag, err := conn(ctx, a.db).Exec(ctx, `
+		WITH
+			allowed AS (
+				SELECT NOT $4::bool OR EXISTS (
+					SELECT 1 FROM merch_shop.registration_allowlist WHERE login = $1
+				) AS ok
+			),
+			invite AS (
+				UPDATE merch_shop.invites SET used_by = $1, used_at = CURRENT_TIMESTAMP
+				WHERE code_hash = encode(sha256(convert_to($3::text, 'UTF8')), 'hex')
+					AND used_at IS NULL
+					AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+					AND (SELECT ok FROM allowed)
+				RETURNING 1
+			)
+		INSERT INTO merch_shop.auth (login, password, balance)
+		SELECT $1, $2, 1000
+		WHERE (SELECT ok FROM allowed) AND ($3 = '' OR EXISTS (SELECT 1 FROM invite))
+		`, reg.Login, reg.PassHash, reg.InviteCode, reg.CheckAllowList)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.ConstraintName == "auth_pkey" {
+				return errors.Join(models.ErrUserExists, err)
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrRegistrationDenied
+	}
+
+	return nil
+}
+
+func (a *auth) UpdatePassword(ctx context.Context, login string, passHash string) error {
+	tag, err := conn(ctx, a.db).Exec(ctx,
+		`UPDATE merch_shop.auth SET password = $2 WHERE login = $1`,
+		login, passHash)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNoRows
+	}
+
+	return nil
+}
+
+func (a *auth) ListRoles(ctx context.Context, login string) ([]string, error) {
+	rows, err := conn(ctx, a.db).Query(ctx, `SELECT role FROM merch_shop.user_roles WHERE login = $1 ORDER BY role`, login)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	roles, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return roles, nil
+}
+
+func (a *auth) GrantRole(ctx context.Context, login string, role string) error {
+	_, err := conn(ctx, a.db).Exec(ctx, `
+		INSERT INTO merch_shop.user_roles (login, role) VALUES ($1, $2)
+		ON CONFLICT (login, role) DO NOTHING
+		`, login, role)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			switch pgerr.ConstraintName {
+			case "user_roles_login_fkey":
+				return errors.Join(models.ErrNoRows, err)
+			case "known_role":
+				return errors.Join(models.ErrUnknownRole, err)
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+func (a *auth) RevokeRole(ctx context.Context, login string, role string) error {
+	_, err := conn(ctx, a.db).Exec(ctx, `DELETE FROM merch_shop.user_roles WHERE login = $1 AND role = $2`, login, role)
 	if err != nil {
 		return errors.Join(models.ErrGeneric, err)
 	}
 
 	return nil
 }
+
+// Deactivate marks user deleted and moves the remaining balance to the company pool
+// as a regular transfer, so histories of both sides stay consistent.
+// Deactivating already deactivated user is a no-op.
+func (a *auth) Deactivate(ctx context.Context, login string) error {
+	var found, updated int
+	err := conn(ctx, a.db).QueryRow(ctx, `
+		WITH
+			u AS (
+				SELECT login, balance, deleted_at FROM merch_shop.auth WHERE login = $1 FOR UPDATE
+			),
+			d AS (
+				UPDATE merch_shop.auth SET deleted_at = CURRENT_TIMESTAMP, balance = 0
+				WHERE login = $1 AND (SELECT deleted_at FROM u) IS NULL
+				RETURNING login
+			),
+			p AS (
+				UPDATE merch_shop.auth SET balance = balance + (SELECT balance FROM u)
+				WHERE login = $2 AND EXISTS (SELECT 1 FROM d)
+			),
+			t AS (
+				INSERT INTO merch_shop.transfers (src, dst, sum)
+				SELECT login, $2, balance FROM u WHERE balance > 0 AND EXISTS (SELECT 1 FROM d)
+			)
+		SELECT (SELECT count(*) FROM u), (SELECT count(*) FROM d)
+		`, login, models.CompanyPool).Scan(&found, &updated)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if found == 0 {
+		return models.ErrNoRows
+	}
+
+	return nil
+}
+
+// Reactivate restores access. Balance returned to the pool is not given back.
+func (a *auth) Reactivate(ctx context.Context, login string) error {
+	tag, err := conn(ctx, a.db).Exec(ctx, `UPDATE merch_shop.auth SET deleted_at = NULL WHERE login = $1`, login)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNoRows
+	}
+
+	return nil
+}
@@ -19,8 +19,13 @@ func NewBalance(db *pgxpool.Pool) *balance { //nolint:revive
 }
 
 func (b *balance) GetBalance(ctx context.Context, name string) (int, error) {
-	var amount int
-	err := b.db.QueryRow(ctx, `SELECT balance FROM merch_shop.auth WHERE login = $1`, name).Scan(&amount)
+	var (
+		amount  int
+		deleted bool
+	)
+	err := conn(ctx, b.db).QueryRow(ctx,
+		`SELECT balance, deleted_at IS NOT NULL FROM merch_shop.auth WHERE login = $1`,
+		name).Scan(&amount, &deleted)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, models.ErrNoRows
@@ -28,6 +33,9 @@ func (b *balance) GetBalance(ctx context.Context, name string) (int, error) {
 
 		return 0, errors.Join(models.ErrGeneric, err)
 	}
+	if deleted {
+		return 0, models.ErrUserDeactivated
+	}
 
 	return amount, nil
 }
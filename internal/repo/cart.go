@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+// GetCart returns cart lines with current prices in the order they were added.
+func (o *orders) GetCart(ctx context.Context, login string) (*models.Cart, error) {
+	rows, err := conn(ctx, o.db).Query(ctx, `
+		SELECT c.item, c.quantity, merch_shop.item_price(c.item, CURRENT_TIMESTAMP)
+		FROM merch_shop.carts AS c
+		WHERE c.login = $1
+		ORDER BY c.added_at, c.item
+		`, login)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	lines, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OrderLine, error) {
+		var l models.OrderLine
+		err := row.Scan(&l.Item, &l.Quantity, &l.Price)
+
+		return l, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	cart := &models.Cart{Lines: lines}
+	if cart.Lines == nil {
+		cart.Lines = []models.OrderLine{}
+	}
+	for _, l := range lines {
+		cart.Total += l.Price * l.Quantity
+	}
+
+	return cart, nil
+}
+
+// AddToCart increases quantity of the line, adding it if needed.
+func (o *orders) AddToCart(ctx context.Context, login string, item string, qty int) error {
+	_, err := conn(ctx, o.db).Exec(ctx, `
+		INSERT INTO merch_shop.carts (login, item, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (login, item) DO UPDATE SET quantity = merch_shop.carts.quantity + EXCLUDED.quantity
+		`, login, item, qty)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.ConstraintName == "carts_item_fkey" {
+				return errors.Join(models.ErrItemNotFound, err)
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+func (o *orders) RemoveFromCart(ctx context.Context, login string, item string) error {
+	tag, err := conn(ctx, o.db).Exec(ctx, `DELETE FROM merch_shop.carts WHERE login = $1 AND item = $2`, login, item)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrItemNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type catalog struct {
+	db *pgxpool.Pool
+}
+
+func NewCatalog(db *pgxpool.Pool) *catalog { //nolint:revive
+	return &catalog{db: db}
+}
+
+// ListItems returns a page of items with current prices and product details,
+// retired ones only if requested. Items of a product go together when sorted by name.
+func (c *catalog) ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error) {
+	rows, err := conn(ctx, c.db).Query(ctx, `
+		SELECT name, price, stock, max_per_user, retired, product, size, color, category, description, tags
+		FROM (
+			SELECT i.name, merch_shop.item_price(i.name, CURRENT_TIMESTAMP) AS price, i.stock, i.max_per_user,
+				i.deleted_at IS NOT NULL AS retired, i.product, COALESCE(i.size, '') AS size,
+				COALESCE(i.color, '') AS color, COALESCE(p.category, '') AS category, p.description, p.tags
+			FROM merch_shop.items AS i
+			JOIN merch_shop.products AS p ON p.name = i.product
+			WHERE ($1 OR i.deleted_at IS NULL)
+				AND ($2 = '' OR p.category = $2)
+				AND ($3 = '' OR strpos(lower(i.name || ' ' || p.name || ' ' || p.description), lower($3)) > 0
+					OR lower($3) = ANY(SELECT lower(t) FROM unnest(p.tags) AS t))
+		) AS i
+		ORDER BY
+			CASE WHEN $4 = 'price' THEN price END,
+			CASE WHEN $4 = '-price' THEN price END DESC,
+			product, name
+		LIMIT $5 OFFSET $6
+		`, retired, f.Category, f.Query, f.Sort, f.Limit, f.Offset)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Item, error) {
+		var v models.Item
+		err := row.Scan(&v.Name, &v.Price, &v.Stock, &v.MaxPerUser, &v.Retired,
+			&v.Product, &v.Size, &v.Color, &v.Category, &v.Description, &v.Tags)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return items, nil
+}
+
+// CreateItem adds a variant to the product, the first variant creates the product.
+func (c *catalog) CreateItem(ctx context.Context, item *models.Item) error {
+	_, err := conn(ctx, c.db).Exec(ctx, `
+		WITH i AS (
+			INSERT INTO merch_shop.items (name, price, stock, max_per_user, product, size, color)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''))
+			RETURNING name, price
+		)
+		INSERT INTO merch_shop.item_prices (item, price) SELECT name, price FROM i
+		`, item.Name, item.Price, item.Stock, item.MaxPerUser, item.Product, item.Size, item.Color)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.ConstraintName == "items_pkey" || pgerr.ConstraintName == "items_variant" {
+				return errors.Join(models.ErrItemExists, err)
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// UpdateProduct replaces category, description and tags shared by product variants.
+func (c *catalog) UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error {
+	tags := rq.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return c.exec(ctx, `
+		UPDATE merch_shop.products SET category = NULLIF($2, ''), description = $3, tags = $4 WHERE name = $1
+		`, name, rq.Category, rq.Description, tags)
+}
+
+// UpdatePrice changes price of future purchases immediately, past ones keep the paid sum.
+// A running sale still overrides the new price until it ends.
+func (c *catalog) UpdatePrice(ctx context.Context, name string, price int) error {
+	return c.exec(ctx, `
+		WITH i AS (
+			UPDATE merch_shop.items SET price = $2 WHERE name = $1 RETURNING name
+		)
+		INSERT INTO merch_shop.item_prices (item, price) SELECT name, $2 FROM i
+		`, name, price)
+}
+
+// ListPrices returns price history with scheduled changes, latest first.
+func (c *catalog) ListPrices(ctx context.Context, name string) ([]models.ItemPrice, error) {
+	rows, err := conn(ctx, c.db).Query(ctx, `
+		SELECT id, price, valid_from, valid_to, created_at
+		FROM merch_shop.item_prices
+		WHERE item = $1
+		ORDER BY valid_from DESC, id DESC
+		`, name)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	prices, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ItemPrice, error) {
+		var v models.ItemPrice
+		err := row.Scan(&v.ID, &v.Price, &v.From, &v.Until, &v.CreatedAt)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	// у каждого товара есть хотя бы начальная цена
+	if len(prices) == 0 {
+		return nil, models.ErrItemNotFound
+	}
+
+	return prices, nil
+}
+
+// SchedulePrice adds price effective from the given moment, until limits it to a sale.
+func (c *catalog) SchedulePrice(
+	ctx context.Context, name string, price int, from time.Time, until *time.Time,
+) (*models.ItemPrice, error) {
+	v := models.ItemPrice{}
+	err := conn(ctx, c.db).QueryRow(ctx, `
+		INSERT INTO merch_shop.item_prices (item, price, valid_from, valid_to)
+		SELECT name, $2, $3, $4 FROM merch_shop.items WHERE name = $1
+		RETURNING id, price, valid_from, valid_to, created_at
+		`, name, price, from, until).Scan(&v.ID, &v.Price, &v.From, &v.Until, &v.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrItemNotFound
+	}
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.ConstraintName == "valid_period" {
+				return nil, errors.Join(models.ErrInvalidSchedule, err)
+			}
+		}
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return &v, nil
+}
+
+// CancelPrice removes scheduled price that is not in effect yet, history is immutable.
+func (c *catalog) CancelPrice(ctx context.Context, name string, id int64) error {
+	tag, err := conn(ctx, c.db).Exec(ctx, `
+		DELETE FROM merch_shop.item_prices
+		WHERE id = $2 AND item = $1 AND valid_from > CURRENT_TIMESTAMP
+		`, name, id)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrPriceNotFound
+	}
+
+	return nil
+}
+
+// UpdateStock sets remaining quantity and per-user limit, nil removes the limit.
+func (c *catalog) UpdateStock(ctx context.Context, name string, stock *int, maxPerUser *int) error {
+	return c.exec(ctx,
+		`UPDATE merch_shop.items SET stock = $2, max_per_user = $3 WHERE name = $1`,
+		name, stock, maxPerUser)
+}
+
+func (c *catalog) RetireItem(ctx context.Context, name string) error {
+	return c.exec(ctx,
+		`UPDATE merch_shop.items SET deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP) WHERE name = $1`,
+		name)
+}
+
+func (c *catalog) RestoreItem(ctx context.Context, name string) error {
+	return c.exec(ctx, `UPDATE merch_shop.items SET deleted_at = NULL WHERE name = $1`, name)
+}
+
+// exec runs single item update, unknown item gives ErrItemNotFound.
+func (c *catalog) exec(ctx context.Context, query string, args ...any) error {
+	tag, err := conn(ctx, c.db).Exec(ctx, query, args...)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrItemNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_ItemPrice(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 1000)`)
+	require.NoError(t, err)
+
+	c := NewCatalog(db)
+	s := NewShop(db)
+	paid := func() int {
+		var sum int
+		err := db.QueryRow(ctx, `
+			SELECT COALESCE(sum(sum), 0) FROM merch_shop.purchases WHERE name = 'buyer'
+			`).Scan(&sum)
+		require.NoError(t, err)
+
+		return sum
+	}
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	// распродажа уже идёт, смена обычной цены запланирована на будущее
+	_, err = c.SchedulePrice(ctx, "cup", 15, now.Add(-time.Minute), &later)
+	require.NoError(t, err)
+	_, err = c.SchedulePrice(ctx, "cup", 30, later, nil)
+	require.NoError(t, err)
+	_, err = c.SchedulePrice(ctx, "cup", 30, later, &later)
+	require.ErrorIs(t, err, models.ErrInvalidSchedule)
+	_, err = c.SchedulePrice(ctx, "unicorn", 30, later, nil)
+	require.ErrorIs(t, err, models.ErrItemNotFound)
+
+	require.NoError(t, s.BuyItem(ctx, "buyer", "cup", ""))
+	require.Equal(t, 15, paid())
+
+	// распродажа перекрывает и немедленную смену цены
+	require.NoError(t, c.UpdatePrice(ctx, "cup", 25))
+	require.NoError(t, s.BuyItem(ctx, "buyer", "cup", ""))
+	require.Equal(t, 30, paid())
+
+	prices, err := c.ListPrices(ctx, "cup")
+	require.NoError(t, err)
+	require.Len(t, prices, 4) // начальная, распродажа, немедленная, запланированная
+	require.Equal(t, 30, prices[0].Price)
+
+	require.ErrorIs(t, c.CancelPrice(ctx, "cup", prices[len(prices)-1].ID), models.ErrPriceNotFound)
+	require.NoError(t, c.CancelPrice(ctx, "cup", prices[0].ID))
+
+	_, err = c.ListPrices(ctx, "unicorn")
+	require.ErrorIs(t, err, models.ErrItemNotFound)
+}
+
+func Test_Variants(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 1000)`)
+	require.NoError(t, err)
+
+	c := NewCatalog(db)
+	stock := 1
+	require.NoError(t, c.CreateItem(ctx, &models.Item{Name: "hoody-m", Price: 300, Stock: &stock, Product: "hoody", Size: "M"}))
+	require.NoError(t, c.CreateItem(ctx, &models.Item{Name: "hoody-xl", Price: 320, Product: "hoody", Size: "XL"}))
+	require.ErrorIs(t, c.CreateItem(ctx, &models.Item{Name: "hoody-xl2", Price: 320, Product: "hoody", Size: "XL"}),
+		models.ErrItemExists)
+	// первый вариант заводит товар
+	require.NoError(t, c.CreateItem(ctx, &models.Item{Name: "cap-red", Price: 40, Product: "cap", Color: "red"}))
+
+	require.NoError(t, c.UpdateProduct(ctx, "hoody", &models.ProductRequest{
+		Category: "clothing", Description: "Warm hoody", Tags: []string{"Winter"},
+	}))
+	require.ErrorIs(t, c.UpdateProduct(ctx, "unicorn", &models.ProductRequest{}), models.ErrItemNotFound)
+
+	names := func(f *models.ItemFilter) []string {
+		f.Limit = 100
+		items, err := c.ListItems(ctx, false, f)
+		require.NoError(t, err)
+		var names []string
+		for _, i := range items {
+			names = append(names, i.Name)
+		}
+
+		return names
+	}
+	require.Equal(t, []string{"hoody", "hoody-m", "hoody-xl", "pink-hoody"},
+		names(&models.ItemFilter{Query: "HOODY", Category: "clothing"}))
+	require.Equal(t, []string{"hoody", "hoody-m", "hoody-xl"}, names(&models.ItemFilter{Query: "winter"}))
+	require.Equal(t, []string{"cap-red"}, names(&models.ItemFilter{Query: "cap"}))
+	require.Equal(t, []string{"hoody-xl", "hoody", "hoody-m"}, names(&models.ItemFilter{Query: "warm", Sort: "-price"}))
+
+	page, err := c.ListItems(ctx, false, &models.ItemFilter{Sort: "price", Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"socks", "cup"}, []string{page[0].Name, page[1].Name}) // pen и socks по 10, затем cup
+
+	// у каждого варианта свой остаток, в инвентаре виден конкретный вариант
+	s := NewShop(db)
+	require.NoError(t, s.BuyItem(ctx, "buyer", "hoody-m", ""))
+	require.ErrorIs(t, s.BuyItem(ctx, "buyer", "hoody-m", ""), models.ErrOutOfStock)
+	require.NoError(t, s.BuyItem(ctx, "buyer", "hoody-xl", ""))
+
+	inv, err := s.ListPurchases(ctx, "buyer")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.InventoryItem{
+		{Type: "hoody-m", Qty: 1, Product: "hoody", Size: "M"},
+		{Type: "hoody-xl", Qty: 1, Product: "hoody", Size: "XL"},
+	}, inv)
+}
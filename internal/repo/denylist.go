@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type denylist struct {
+	db *pgxpool.Pool
+}
+
+func NewDenylist(db *pgxpool.Pool) *denylist { //nolint:revive
+	return &denylist{db: db}
+}
+
+func (d *denylist) RevokeToken(ctx context.Context, rt models.RevokedToken) error {
+	_, err := conn(ctx, d.db).Exec(ctx, `
+		INSERT INTO merch_shop.revoked_tokens (jti, login, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+		`, rt.ID, rt.Login, rt.ExpiresAt)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+func (d *denylist) RevokeSessions(ctx context.Context, rs models.RevokedSession) error {
+	_, err := conn(ctx, d.db).Exec(ctx, `
+		INSERT INTO merch_shop.revoked_sessions (login, revoked_at, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (login) DO UPDATE SET revoked_at = excluded.revoked_at, expires_at = excluded.expires_at
+		`, rs.Login, rs.RevokedAt, rs.ExpiresAt)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// ListRevoked returns active revocations and removes expired ones.
+func (d *denylist) ListRevoked(ctx context.Context) ([]models.RevokedToken, []models.RevokedSession, error) {
+	var (
+		tokens   []models.RevokedToken
+		sessions []models.RevokedSession
+	)
+
+	if err := d.purge(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := conn(ctx, d.db).Query(ctx, `
+		SELECT jti::text, login, expires_at FROM merch_shop.revoked_tokens
+		`)
+	if err != nil {
+		return nil, nil, errors.Join(models.ErrGeneric, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v models.RevokedToken
+		if err := rows.Scan(&v.ID, &v.Login, &v.ExpiresAt); err != nil {
+			return nil, nil, errors.Join(models.ErrGeneric, err)
+		}
+		tokens = append(tokens, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	rows, err = conn(ctx, d.db).Query(ctx, `
+		SELECT login, revoked_at, expires_at FROM merch_shop.revoked_sessions
+		`)
+	if err != nil {
+		return nil, nil, errors.Join(models.ErrGeneric, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v models.RevokedSession
+		if err := rows.Scan(&v.Login, &v.RevokedAt, &v.ExpiresAt); err != nil {
+			return nil, nil, errors.Join(models.ErrGeneric, err)
+		}
+		sessions = append(sessions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return tokens, sessions, nil
+}
+
+func (d *denylist) purge(ctx context.Context) error {
+	now := time.Now()
+	_, err := conn(ctx, d.db).Exec(ctx, `
+		WITH t AS (DELETE FROM merch_shop.revoked_tokens WHERE expires_at <= $1)
+		DELETE FROM merch_shop.revoked_sessions WHERE expires_at <= $1
+		`, now)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type idempotency struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotency(db *pgxpool.Pool) *idempotency { //nolint:revive
+	return &idempotency{db: db}
+}
+
+// Do runs f once per user key and returns its response, or the stored one with replayed set.
+// The response is stored in the transaction f runs in, repos take it from ctx.
+// f changes are kept for successful responses only; server errors are not stored,
+// so such request may be retried. A concurrent request with the same key waits
+// on the key row until the first one is done. User keys created before expired are forgotten.
+func (i *idempotency) Do(
+	ctx context.Context, rq *models.IdempotentRequest, expired time.Time,
+	f func(ctx context.Context) *models.StoredResponse,
+) (resp *models.StoredResponse, replayed bool, err error) {
+	tx, err := i.db.Begin(ctx)
+	if err != nil {
+		return nil, false, errors.Join(models.ErrGeneric, err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM merch_shop.idempotency_keys WHERE (login, key) IN (
+			SELECT login, key FROM merch_shop.idempotency_keys
+			WHERE login = $1 AND created_at < $2
+			FOR UPDATE SKIP LOCKED
+		)
+		`, rq.User, expired)
+	if err != nil {
+		return nil, false, errors.Join(models.ErrGeneric, err)
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO merch_shop.idempotency_keys (login, key, request_hash) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`, rq.User, rq.Key, rq.Hash)
+	if err != nil {
+		return nil, false, errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		resp, err = stored(ctx, tx, rq)
+
+		return resp, err == nil, err
+	}
+
+	resp, err = run(ctx, tx, f)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Status >= http.StatusInternalServerError {
+		return resp, false, nil
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE merch_shop.idempotency_keys SET status = $3, body = $4 WHERE login = $1 AND key = $2
+		`, rq.User, rq.Key, resp.Status, resp.Body)
+	if err != nil {
+		return nil, false, errors.Join(models.ErrGeneric, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, false, errors.Join(models.ErrGeneric, err)
+	}
+
+	return resp, false, nil
+}
+
+// run calls f in a savepoint: a failed operation may have aborted it,
+// the key row must survive to store the error response.
+func run(
+	ctx context.Context, tx pgx.Tx, f func(ctx context.Context) *models.StoredResponse,
+) (*models.StoredResponse, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	defer sp.Rollback(ctx) //nolint:errcheck
+
+	resp := f(contextWithTx(ctx, sp))
+	if resp.Status < http.StatusBadRequest {
+		if err = sp.Commit(ctx); err != nil {
+			return nil, errors.Join(models.ErrGeneric, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// stored returns the response of the committed request with the same key.
+func stored(ctx context.Context, tx pgx.Tx, rq *models.IdempotentRequest) (*models.StoredResponse, error) {
+	var hash []byte
+	resp := &models.StoredResponse{}
+	err := tx.QueryRow(ctx, `
+		SELECT request_hash, status, body FROM merch_shop.idempotency_keys WHERE login = $1 AND key = $2
+		`, rq.User, rq.Key).Scan(&hash, &resp.Status, &resp.Body)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if !bytes.Equal(hash, rq.Hash) {
+		return nil, models.ErrIdempotencyMismatch
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Idempotency(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `INSERT INTO merch_shop.auth (login, password, balance) VALUES ('payer', '!', 100), ('payee', '!', 0)`)
+	require.NoError(t, err)
+
+	i := NewIdempotency(db)
+	p := NewP2p(db)
+	balance := func(login string) int {
+		var b int
+		require.NoError(t, db.QueryRow(ctx, `SELECT balance FROM merch_shop.auth WHERE login = $1`, login).Scan(&b))
+
+		return b
+	}
+	transfer := func(amount int) func(ctx context.Context) *models.StoredResponse {
+		return func(ctx context.Context) *models.StoredResponse {
+			if err := p.Transfer(ctx, "payer", "payee", amount, "", &models.TransferLimits{}); err != nil {
+				return &models.StoredResponse{Status: http.StatusBadRequest, Body: []byte("no")}
+			}
+
+			return &models.StoredResponse{Status: http.StatusOK, Body: []byte("ok")}
+		}
+	}
+	expired := time.Now().Add(-time.Hour)
+	rq := &models.IdempotentRequest{User: "payer", Key: "k1", Hash: []byte{1}}
+
+	resp, replayed, err := i.Do(ctx, rq, expired, transfer(30))
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, []byte("ok"), resp.Body)
+
+	// повтор не переводит монеты второй раз
+	resp, replayed, err = i.Do(ctx, rq, expired, transfer(30))
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, http.StatusOK, resp.Status)
+	require.Equal(t, 70, balance("payer"))
+
+	_, _, err = i.Do(ctx, &models.IdempotentRequest{User: "payer", Key: "k1", Hash: []byte{2}}, expired, transfer(30))
+	require.ErrorIs(t, err, models.ErrIdempotencyMismatch)
+
+	// ошибка клиента сохраняется, ошибка сервера - нет
+	rq = &models.IdempotentRequest{User: "payer", Key: "k2", Hash: []byte{1}}
+	resp, _, err = i.Do(ctx, rq, expired, transfer(1000))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.Status)
+	_, replayed, err = i.Do(ctx, rq, expired, transfer(10))
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, 70, balance("payer"))
+
+	rq = &models.IdempotentRequest{User: "payer", Key: "k3", Hash: []byte{1}}
+	_, _, err = i.Do(ctx, rq, expired, func(context.Context) *models.StoredResponse {
+		return &models.StoredResponse{Status: http.StatusInternalServerError}
+	})
+	require.NoError(t, err)
+	_, replayed, err = i.Do(ctx, rq, expired, transfer(10))
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, 60, balance("payer"))
+
+	// по истечении срока ключ можно использовать снова
+	_, replayed, err = i.Do(ctx, &models.IdempotentRequest{User: "payer", Key: "k1", Hash: []byte{2}},
+		time.Now().Add(time.Hour), transfer(10))
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, 50, balance("payer"))
+}
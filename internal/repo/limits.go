@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+// limitsQuery selects effective limits of user $1, configured ones are $2..$6.
+// Unknown user gives no rows.
+const limitsQuery = `
+	SELECT COALESCE(l.max_amount, $2), COALESCE(l.daily, $3), COALESCE(l.monthly, $4),
+		COALESCE(l.counterparty_daily, $5), COALESCE(l.hourly_count, $6)
+	FROM merch_shop.auth AS a
+	LEFT JOIN merch_shop.transfer_limits AS l ON l.login = a.login
+	WHERE a.login = $1`
+
+func limitsArgs(login string, def *models.TransferLimits) []any {
+	return []any{login, def.MaxAmount, def.Daily, def.Monthly, def.DailyCounterparty, def.HourlyCount}
+}
+
+func scanLimits(row pgx.Row) (*models.TransferLimits, error) {
+	l := &models.TransferLimits{}
+	err := row.Scan(&l.MaxAmount, &l.Daily, &l.Monthly, &l.DailyCounterparty, &l.HourlyCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrNoRows
+	}
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return l, nil
+}
+
+// checkLimits must run after the sender row is locked: the statement snapshot then
+// includes all committed transfers of the sender. Days and months start in UTC.
+func checkLimits(ctx context.Context, q dbtx, from string, to string, amount int, def *models.TransferLimits) error {
+	l, err := scanLimits(q.QueryRow(ctx, limitsQuery, limitsArgs(from, def)...))
+	if errors.Is(err, models.ErrNoRows) {
+		return nil // отправителя нет, перевод объяснит inactiveParty
+	}
+	if err != nil {
+		return err
+	}
+	if l.MaxAmount > 0 && amount > l.MaxAmount {
+		return &models.LimitError{Limit: models.LimitAmount}
+	}
+	if l.Daily == 0 && l.Monthly == 0 && l.DailyCounterparty == 0 && l.HourlyCount == 0 {
+		return nil
+	}
+
+	var daily, monthly, counterparty, hourly int
+	err = q.QueryRow(ctx, `
+		WITH b AS (
+			SELECT
+				date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS day,
+				date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS month,
+				now() - interval '1 hour' AS hour
+		)
+		SELECT
+			COALESCE(sum(t.sum) FILTER (WHERE t.dt >= b.day), 0),
+			COALESCE(sum(t.sum) FILTER (WHERE t.dt >= b.month), 0),
+			COALESCE(sum(t.sum) FILTER (WHERE t.dst = $2 AND t.dt >= b.day), 0),
+			count(*) FILTER (WHERE t.dt > b.hour)
+		FROM merch_shop.transfers AS t, b
+		WHERE t.src = $1 AND t.dt >= LEAST(b.month, b.hour)
+		`, from, to).Scan(&daily, &monthly, &counterparty, &hourly)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	switch {
+	case l.Daily > 0 && daily+amount > l.Daily:
+		return &models.LimitError{Limit: models.LimitDaily}
+	case l.Monthly > 0 && monthly+amount > l.Monthly:
+		return &models.LimitError{Limit: models.LimitMonthly}
+	case l.DailyCounterparty > 0 && counterparty+amount > l.DailyCounterparty:
+		return &models.LimitError{Limit: models.LimitCounterparty}
+	case l.HourlyCount > 0 && hourly >= l.HourlyCount:
+		return &models.LimitError{Limit: models.LimitHourly}
+	}
+
+	return nil
+}
+
+// GetTransferLimits returns limits in effect for the user.
+func (p *p2p) GetTransferLimits(ctx context.Context, login string, def *models.TransferLimits) (*models.TransferLimits, error) {
+	return scanLimits(conn(ctx, p.db).QueryRow(ctx, limitsQuery, limitsArgs(login, def)...))
+}
+
+// SetTransferLimits replaces the user override.
+func (p *p2p) SetTransferLimits(ctx context.Context, login string, o *models.TransferLimitsOverride) error {
+	_, err := conn(ctx, p.db).Exec(ctx, `
+		INSERT INTO merch_shop.transfer_limits (login, max_amount, daily, monthly, counterparty_daily, hourly_count)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (login) DO UPDATE SET
+			max_amount = EXCLUDED.max_amount, daily = EXCLUDED.daily, monthly = EXCLUDED.monthly,
+			counterparty_daily = EXCLUDED.counterparty_daily, hourly_count = EXCLUDED.hourly_count,
+			updated_at = CURRENT_TIMESTAMP
+		`, login, o.MaxAmount, o.Daily, o.Monthly, o.DailyCounterparty, o.HourlyCount)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.ConstraintName == "transfer_limits_login_fkey" {
+				return models.ErrNoRows
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// ResetTransferLimits removes the user override, configured limits apply again.
+func (p *p2p) ResetTransferLimits(ctx context.Context, login string) error {
+	_, err := conn(ctx, p.db).Exec(ctx, `DELETE FROM merch_shop.transfer_limits WHERE login = $1`, login)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
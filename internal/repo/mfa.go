@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func (a *auth) GetTOTP(ctx context.Context, login string) (*models.TOTP, error) {
+	t := &models.TOTP{}
+	err := a.db.QueryRow(ctx, `
+		SELECT secret, confirmed_at IS NOT NULL, last_step
+		FROM merch_shop.totp_secrets WHERE login = $1
+		`, login).Scan(&t.Secret, &t.Confirmed, &t.LastStep)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNoRows
+		}
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return t, nil
+}
+
+// SaveTOTP stores a new unconfirmed secret. Repeated enrollment replaces
+// unconfirmed secret, confirmed one has to be disabled first.
+func (a *auth) SaveTOTP(ctx context.Context, login string, secret string) error {
+	tag, err := a.db.Exec(ctx, `
+		INSERT INTO merch_shop.totp_secrets (login, secret) VALUES ($1, $2)
+		ON CONFLICT (login) DO UPDATE SET secret = EXCLUDED.secret, created_at = CURRENT_TIMESTAMP
+			WHERE merch_shop.totp_secrets.confirmed_at IS NULL
+		`, login, secret)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrMFAEnabled
+	}
+
+	return nil
+}
+
+// ConfirmTOTP enables the secret that was checked and replaces recovery codes.
+// Secret changed by parallel enrollment is not confirmed.
+func (a *auth) ConfirmTOTP(ctx context.Context, login string, secret string, step int64, recovery []string) error {
+	tag, err := a.db.Exec(ctx, `
+		WITH
+			t AS (
+				UPDATE merch_shop.totp_secrets SET confirmed_at = CURRENT_TIMESTAMP, last_step = $3
+				WHERE login = $1 AND secret = $2 AND confirmed_at IS NULL
+				RETURNING login
+			),
+			old AS (
+				DELETE FROM merch_shop.mfa_recovery_codes WHERE login IN (SELECT login FROM t)
+			)
+		INSERT INTO merch_shop.mfa_recovery_codes (login, code_hash)
+		SELECT t.login, h FROM t, unnest($4::text[]) AS h
+		`, login, secret, step, recovery)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+// UseTOTPStep remembers accepted step. Step not greater than the last one is a replay.
+func (a *auth) UseTOTPStep(ctx context.Context, login string, step int64) error {
+	tag, err := a.db.Exec(ctx, `
+		UPDATE merch_shop.totp_secrets SET last_step = $2
+		WHERE login = $1 AND confirmed_at IS NOT NULL AND last_step < $2
+		`, login, step)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+func (a *auth) UseRecoveryCode(ctx context.Context, login string, hash string) error {
+	tag, err := a.db.Exec(ctx, `
+		UPDATE merch_shop.mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE login = $1 AND code_hash = $2 AND used_at IS NULL
+		`, login, hash)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+func (a *auth) DeleteTOTP(ctx context.Context, login string) error {
+	_, err := a.db.Exec(ctx, `
+		WITH r AS (
+			DELETE FROM merch_shop.mfa_recovery_codes WHERE login = $1
+		)
+		DELETE FROM merch_shop.totp_secrets WHERE login = $1
+		`, login)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
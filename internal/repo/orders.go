@@ -0,0 +1,373 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type orders struct {
+	db *pgxpool.Pool
+}
+
+func NewOrders(db *pgxpool.Pool) *orders { //nolint:revive
+	return &orders{db: db}
+}
+
+// PlaceOrder buys all lines in one transaction. The first line that cannot be
+// bought fails the whole order with *models.LineError.
+func (o *orders) PlaceOrder(
+	ctx context.Context, buyer string, lines []models.OrderLine, promo string,
+) (*models.Order, error) {
+	var order *models.Order
+	err := inTx(ctx, o.db, func(tx pgx.Tx) (err error) {
+		order, err = placeOrder(ctx, tx, &models.Order{User: buyer}, lines, promo)
+
+		return err
+	})
+
+	return order, err
+}
+
+// Checkout places order from the cart. Cart is cleared only if the order succeeds.
+func (o *orders) Checkout(ctx context.Context, buyer string, promo string) (*models.Order, error) {
+	var order *models.Order
+	err := inTx(ctx, o.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH c AS (
+				DELETE FROM merch_shop.carts WHERE login = $1 RETURNING item, quantity, added_at
+			)
+			SELECT item, quantity FROM c ORDER BY added_at, item
+			`, buyer)
+		if err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+		lines, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OrderLine, error) {
+			var l models.OrderLine
+			err := row.Scan(&l.Item, &l.Quantity)
+
+			return l, err //nolint:wrapcheck
+		})
+		if err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+		if len(lines) == 0 {
+			return models.ErrCartEmpty
+		}
+
+		order, err = placeOrder(ctx, tx, &models.Order{User: buyer}, lines, promo)
+
+		return err
+	})
+
+	return order, err
+}
+
+// inTx commits the transaction only if f succeeds. Errors of f are returned as is.
+// Inside a transaction carried by ctx it is a savepoint of that transaction.
+func inTx(ctx context.Context, db *pgxpool.Pool, f func(tx pgx.Tx) error) error {
+	tx, err := conn(ctx, db).Begin(ctx)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err = f(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// placeOrder fills the order prepared by caller (buyer, gift recipient). The promo
+// code applies to lines it is valid for; a code that applies to none of them fails the order.
+func placeOrder(
+	ctx context.Context, tx pgx.Tx, order *models.Order, lines []models.OrderLine, code string,
+) (*models.Order, error) {
+	if order.Recipient != "" {
+		if err := activeRecipient(ctx, tx, order.Recipient); err != nil {
+			return nil, err
+		}
+	}
+
+	// товары блокируются в одном порядке, иначе встречные заказы взаимоблокируются
+	names := make([]string, 0, len(lines))
+	for _, l := range lines {
+		names = append(names, l.Item)
+	}
+	slices.Sort(names)
+	if _, err := tx.Exec(ctx, `
+		SELECT 1 FROM merch_shop.items WHERE name = ANY($1) ORDER BY name FOR UPDATE
+		`, names); err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	order.Lines = make([]models.OrderLine, 0, len(lines))
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO merch_shop.orders (login, recipient, message) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+		RETURNING id, status, created_at
+		`, order.User, order.Recipient, order.Message).Scan(&order.ID, &order.Status, &order.CreatedAt); err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	var p *promo
+	if code != "" {
+		var err error
+		if p, err = redeemPromo(ctx, tx, code, order.User, order.ID); err != nil {
+			return nil, err
+		}
+		order.Promo = code
+	}
+	for _, l := range lines {
+		price, discount, err := buyLine(ctx, tx, order, l.Item, l.Quantity, p)
+		if err != nil {
+			return nil, &models.LineError{Item: l.Item, Err: err}
+		}
+		order.Lines = append(order.Lines, models.OrderLine{
+			Item: l.Item, Quantity: l.Quantity, Price: price, Discount: discount,
+		})
+		order.Total += price * l.Quantity
+	}
+	if p != nil && !p.applied {
+		return nil, models.ErrInvalidPromo
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE merch_shop.orders SET total = $2 WHERE id = $1
+		`, order.ID, order.Total); err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return order, nil
+}
+
+// activeRecipient locks gift recipient, so it can not be deactivated meanwhile.
+func activeRecipient(ctx context.Context, tx pgx.Tx, login string) error {
+	var active bool
+	err := tx.QueryRow(ctx, `
+		SELECT deleted_at IS NULL FROM merch_shop.auth WHERE login = $1 FOR SHARE
+		`, login).Scan(&active)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.ErrNoRows
+	case err != nil:
+		return errors.Join(models.ErrGeneric, err)
+	case !active:
+		return models.ErrUserDeactivated
+	}
+
+	return nil
+}
+
+// Gift buys one unit for another user: the buyer pays and owns the order,
+// the item lands in the recipient's inventory.
+func (o *orders) Gift(
+	ctx context.Context, buyer string, recipient string, item string, message string, promo string,
+) (*models.Order, error) {
+	var order *models.Order
+	err := inTx(ctx, o.db, func(tx pgx.Tx) (err error) {
+		order, err = placeOrder(ctx, tx, &models.Order{User: buyer, Recipient: recipient, Message: message},
+			[]models.OrderLine{{Item: item, Quantity: 1}}, promo)
+
+		return err
+	})
+
+	return order, err
+}
+
+// orderColumns are read by scanOrder.
+const orderColumns = `id, login, status, total, created_at, COALESCE(recipient, ''), COALESCE(message, '')`
+
+func scanOrder(row pgx.Row, v *models.Order) error {
+	return row.Scan(&v.ID, &v.User, &v.Status, &v.Total, &v.CreatedAt, &v.Recipient, &v.Message) //nolint:wrapcheck
+}
+
+// ListOrders returns orders newest first. Empty login or status means any.
+func (o *orders) ListOrders(ctx context.Context, login string, status string) ([]models.Order, error) {
+	rows, err := conn(ctx, o.db).Query(ctx, `
+		SELECT `+orderColumns+`
+		FROM merch_shop.orders
+		WHERE ($1 = '' OR login = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		`, login, status)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Order, error) {
+		var v models.Order
+		err := scanOrder(row, &v)
+
+		return v, err
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if err = o.fillLines(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// fillLines loads order lines from purchases, one row per unit.
+func (o *orders) fillLines(ctx context.Context, list []models.Order) error {
+	if len(list) == 0 {
+		return nil
+	}
+	idx := make(map[int64]int, len(list))
+	ids := make([]int64, 0, len(list))
+	for i, v := range list {
+		idx[v.ID] = i
+		ids = append(ids, v.ID)
+		list[i].Lines = []models.OrderLine{}
+	}
+
+	rows, err := conn(ctx, o.db).Query(ctx, `
+		SELECT order_id, item, count(*), min(sum), min(discount), COALESCE(min(promo), '')
+		FROM merch_shop.purchases
+		WHERE order_id = ANY($1)
+		GROUP BY order_id, item
+		ORDER BY order_id, item
+		`, ids)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var promo string
+		var l models.OrderLine
+		if err := rows.Scan(&id, &l.Item, &l.Quantity, &l.Price, &l.Discount, &promo); err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+		i := idx[id]
+		list[i].Lines = append(list[i].Lines, l)
+		if promo != "" {
+			list[i].Promo = promo
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// SetOrderStatus moves order to status if its current status is one of from.
+func (o *orders) SetOrderStatus(ctx context.Context, id int64, status string, from []string) (*models.Order, error) {
+	v := models.Order{}
+	err := scanOrder(conn(ctx, o.db).QueryRow(ctx, `
+		UPDATE merch_shop.orders SET status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = ANY($3)
+		RETURNING `+orderColumns, id, status, from), &v)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err = conn(ctx, o.db).QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM merch_shop.orders WHERE id = $1)
+			`, id).Scan(&exists); err != nil {
+			return nil, errors.Join(models.ErrGeneric, err)
+		}
+		if !exists {
+			return nil, models.ErrOrderNotFound
+		}
+
+		return nil, models.ErrOrderStatus
+	}
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	list := []models.Order{v}
+	if err = o.fillLines(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return &list[0], nil
+}
+
+// CancelOrder refunds the order after its items are locked: purchases are marked refunded,
+// the charged sum is credited back, the stock is restored and the promo code use is returned.
+// Empty login allows any owner; the order must be placed after since.
+// Purchases made before orders existed have no order and can not be refunded.
+func (o *orders) CancelOrder(
+	ctx context.Context, id int64, login string, from []string, since time.Time,
+) (*models.Order, error) {
+	v := models.Order{}
+	err := inTx(ctx, o.db, func(tx pgx.Tx) error {
+		// товары блокируются в том же порядке, что и при покупке, иначе встречные операции взаимоблокируются
+		if _, err := tx.Exec(ctx, `
+			SELECT 1 FROM merch_shop.items
+			WHERE name IN (SELECT item FROM merch_shop.purchases WHERE order_id = $1)
+			ORDER BY name FOR UPDATE
+			`, id); err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+		err := scanOrder(tx.QueryRow(ctx, `
+		   WITH o AS (
+			UPDATE merch_shop.orders SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND ($2 = '' OR login = $2) AND status = ANY($3) AND created_at >= $4
+			RETURNING *
+		   ), p AS (
+			UPDATE merch_shop.purchases AS p SET refunded_at = CURRENT_TIMESTAMP
+			FROM o WHERE p.order_id = o.id AND p.refunded_at IS NULL
+			RETURNING p.item, p.sum
+		   ), st AS (
+			UPDATE merch_shop.items AS i SET stock = i.stock + r.qty
+			FROM (SELECT item, count(*) AS qty FROM p GROUP BY item) AS r
+			WHERE i.name = r.item AND i.stock IS NOT NULL
+		   ), b AS (
+			UPDATE merch_shop.auth AS a SET balance = a.balance + (SELECT COALESCE(sum(sum), 0) FROM p)
+			FROM o WHERE a.login = o.login
+		   ), pc AS (
+			DELETE FROM merch_shop.promo_redemptions AS r USING o WHERE r.order_id = o.id
+		   )
+		   SELECT `+orderColumns+` FROM o;
+			`, id, login, from, since), &v)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrNoRows
+		}
+		if err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+
+		return nil
+	})
+	if errors.Is(err, models.ErrNoRows) {
+		return nil, o.notCancelled(ctx, id, login, from)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := []models.Order{v}
+	if err = o.fillLines(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return &list[0], nil
+}
+
+// notCancelled explains why order was not cancelled. Orders of other users are reported as not found.
+func (o *orders) notCancelled(ctx context.Context, id int64, login string, from []string) error {
+	var allowed bool
+	err := conn(ctx, o.db).QueryRow(ctx, `
+		SELECT status = ANY($3) FROM merch_shop.orders WHERE id = $1 AND ($2 = '' OR login = $2)
+		`, id, login, from).Scan(&allowed)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.ErrOrderNotFound
+	case err != nil:
+		return errors.Join(models.ErrGeneric, err)
+	case !allowed:
+		return models.ErrOrderStatus
+	}
+
+	return models.ErrCancelExpired
+}
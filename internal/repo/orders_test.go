@@ -0,0 +1,216 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_PlaceOrder(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 100);
+		INSERT INTO merch_shop.items (name, price, stock) VALUES ('limited', 5, 2);
+		`)
+	require.NoError(t, err)
+
+	o := NewOrders(db)
+	state := func() (int, int, int) {
+		var balance, purchases, stock int
+		err := db.QueryRow(ctx, `
+			SELECT balance,
+				(SELECT count(*) FROM merch_shop.purchases WHERE name = 'buyer'),
+				(SELECT stock FROM merch_shop.items WHERE name = 'limited')
+			FROM merch_shop.auth WHERE login = 'buyer'
+			`).Scan(&balance, &purchases, &stock)
+		require.NoError(t, err)
+
+		return balance, purchases, stock
+	}
+
+	t.Run("failed_line_rolls_back", func(t *testing.T) {
+		_, err := o.PlaceOrder(ctx, "buyer", []models.OrderLine{
+			{Item: "socks", Quantity: 2}, {Item: "limited", Quantity: 1}, {Item: "limited", Quantity: 2},
+		}, "")
+		require.ErrorIs(t, err, models.ErrOutOfStock)
+		var line *models.LineError
+		require.ErrorAs(t, err, &line)
+		require.Equal(t, "limited", line.Item)
+
+		balance, purchases, stock := state()
+		require.Equal(t, []int{100, 0, 2}, []int{balance, purchases, stock})
+	})
+
+	t.Run("placed", func(t *testing.T) {
+		order, err := o.PlaceOrder(ctx, "buyer", []models.OrderLine{{Item: "socks", Quantity: 2}, {Item: "limited", Quantity: 2}}, "")
+		require.NoError(t, err)
+		require.Equal(t, 30, order.Total)
+		require.Equal(t, models.OrderPlaced, order.Status)
+
+		balance, purchases, stock := state()
+		require.Equal(t, []int{70, 4, 0}, []int{balance, purchases, stock})
+	})
+
+	t.Run("checkout", func(t *testing.T) {
+		require.NoError(t, o.AddToCart(ctx, "buyer", "cup", 1))
+		require.NoError(t, o.AddToCart(ctx, "buyer", "cup", 1))
+		require.ErrorIs(t, o.AddToCart(ctx, "buyer", "unicorn", 1), models.ErrItemNotFound)
+
+		cart, err := o.GetCart(ctx, "buyer")
+		require.NoError(t, err)
+		require.Equal(t, &models.Cart{Lines: []models.OrderLine{{Item: "cup", Quantity: 2, Price: 20}}, Total: 40}, cart)
+
+		order, err := o.Checkout(ctx, "buyer", "")
+		require.NoError(t, err)
+		require.Equal(t, 40, order.Total)
+
+		_, err = o.Checkout(ctx, "buyer", "")
+		require.ErrorIs(t, err, models.ErrCartEmpty)
+	})
+
+	t.Run("checkout_failed_keeps_cart", func(t *testing.T) {
+		require.NoError(t, o.AddToCart(ctx, "buyer", "powerbank", 1)) // 200 при балансе 30
+
+		_, err := o.Checkout(ctx, "buyer", "")
+		require.ErrorIs(t, err, models.ErrNoMoney)
+
+		cart, err := o.GetCart(ctx, "buyer")
+		require.NoError(t, err)
+		require.Len(t, cart.Lines, 1)
+	})
+
+	t.Run("lifecycle", func(t *testing.T) {
+		require.NoError(t, NewShop(db).BuyItem(ctx, "buyer", "pen", ""))
+
+		list, err := o.ListOrders(ctx, "buyer", "")
+		require.NoError(t, err)
+		require.Len(t, list, 3) // placed, checkout, покупка через /api/buy
+		require.Equal(t, []models.OrderLine{{Item: "pen", Quantity: 1, Price: 10}}, list[0].Lines)
+		require.Equal(t, 10, list[0].Total)
+
+		id := list[0].ID
+		ready := models.OrderTransitions[models.OrderReady]
+		delivered := models.OrderTransitions[models.OrderDelivered]
+
+		order, err := o.SetOrderStatus(ctx, id, models.OrderReady, ready)
+		require.NoError(t, err)
+		require.Equal(t, models.OrderReady, order.Status)
+		require.Len(t, order.Lines, 1)
+
+		_, err = o.SetOrderStatus(ctx, id, models.OrderReady, ready)
+		require.ErrorIs(t, err, models.ErrOrderStatus)
+		_, err = o.SetOrderStatus(ctx, id, models.OrderDelivered, delivered)
+		require.NoError(t, err)
+		_, err = o.SetOrderStatus(ctx, -1, models.OrderDelivered, delivered)
+		require.ErrorIs(t, err, models.ErrOrderNotFound)
+
+		queue, err := o.ListOrders(ctx, "", models.OrderPlaced)
+		require.NoError(t, err)
+		require.Len(t, queue, 2)
+
+		// инвентарь по-прежнему считается по покупкам, независимо от статуса заказа
+		inv, err := NewShop(db).ListPurchases(ctx, "buyer")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []models.InventoryItem{
+			{Type: "socks", Qty: 2, Product: "socks"}, {Type: "limited", Qty: 2, Product: "limited"},
+			{Type: "cup", Qty: 2, Product: "cup"}, {Type: "pen", Qty: 1, Product: "pen"},
+		}, inv)
+	})
+
+	t.Run("cancel_refunds", func(t *testing.T) {
+		order, err := o.PlaceOrder(ctx, "buyer", []models.OrderLine{{Item: "pen", Quantity: 2}}, "")
+		require.NoError(t, err)
+		// цена после покупки не влияет на возврат
+		_, err = db.Exec(ctx, `UPDATE merch_shop.items SET price = 50, stock = 3 WHERE name = 'pen'`)
+		require.NoError(t, err)
+		balance, _, _ := state()
+
+		_, err = o.CancelOrder(ctx, order.ID, "other", models.UserCancellable, time.Time{})
+		require.ErrorIs(t, err, models.ErrOrderNotFound)
+		_, err = o.CancelOrder(ctx, order.ID, "buyer", models.UserCancellable, time.Now().Add(time.Hour))
+		require.ErrorIs(t, err, models.ErrCancelExpired)
+
+		cancelled, err := o.CancelOrder(ctx, order.ID, "buyer", models.UserCancellable, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, models.OrderCancelled, cancelled.Status)
+		require.Equal(t, []models.OrderLine{{Item: "pen", Quantity: 2, Price: 10}}, cancelled.Lines)
+
+		after, _, _ := state()
+		require.Equal(t, balance+20, after)
+		var stock int
+		require.NoError(t, db.QueryRow(ctx, `SELECT stock FROM merch_shop.items WHERE name = 'pen'`).Scan(&stock))
+		require.Equal(t, 5, stock)
+
+		_, err = o.CancelOrder(ctx, order.ID, "", models.AdminCancellable, time.Time{})
+		require.ErrorIs(t, err, models.ErrOrderStatus)
+
+		inv, err := NewShop(db).ListPurchases(ctx, "buyer")
+		require.NoError(t, err)
+		require.Contains(t, inv, models.InventoryItem{Type: "pen", Qty: 1, Product: "pen"})
+	})
+}
+
+func Test_Gift(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('giver', '!', 100), ('taker', '!', 0);
+		INSERT INTO merch_shop.auth (login, password, deleted_at) VALUES ('gone', '!', CURRENT_TIMESTAMP);
+		UPDATE merch_shop.items SET max_per_user = 1 WHERE name = 'cup';
+		`)
+	require.NoError(t, err)
+
+	o := NewOrders(db)
+	s := NewShop(db)
+
+	_, err = o.Gift(ctx, "giver", "nobody", "cup", "", "")
+	require.ErrorIs(t, err, models.ErrNoRows)
+	_, err = o.Gift(ctx, "giver", "gone", "cup", "", "")
+	require.ErrorIs(t, err, models.ErrUserDeactivated)
+
+	order, err := o.Gift(ctx, "giver", "taker", "cup", "thanks", "")
+	require.NoError(t, err)
+	require.Equal(t, "taker", order.Recipient)
+	require.Equal(t, 20, order.Total)
+
+	// лимит на сотрудника считается по получателю
+	_, err = o.Gift(ctx, "giver", "taker", "cup", "", "")
+	require.ErrorIs(t, err, models.ErrPurchaseLimit)
+
+	giver, err := NewBalance(db).GetBalance(ctx, "giver")
+	require.NoError(t, err)
+	require.Equal(t, 80, giver)
+
+	inv, err := s.ListPurchases(ctx, "taker")
+	require.NoError(t, err)
+	require.Equal(t, []models.InventoryItem{{Type: "cup", Qty: 1, Product: "cup"}}, inv)
+	inv, err = s.ListPurchases(ctx, "giver")
+	require.NoError(t, err)
+	require.Empty(t, inv)
+
+	received, err := s.ListGiftsReceived(ctx, "taker")
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, models.ReceivedGift{From: "giver", Item: "cup", Message: "thanks", Date: received[0].Date}, received[0])
+	sent, err := s.ListGiftsSent(ctx, "giver")
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	require.Equal(t, "taker", sent[0].To)
+
+	// возврат идёт покупателю, подарок пропадает у получателя
+	_, err = o.CancelOrder(ctx, order.ID, "giver", models.UserCancellable, time.Time{})
+	require.NoError(t, err)
+	giver, err = NewBalance(db).GetBalance(ctx, "giver")
+	require.NoError(t, err)
+	require.Equal(t, 100, giver)
+	received, err = s.ListGiftsReceived(ctx, "taker")
+	require.NoError(t, err)
+	require.Empty(t, received)
+}
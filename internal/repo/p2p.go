@@ -3,7 +3,9 @@ package repo
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -18,30 +20,72 @@ func NewP2p(db *pgxpool.Pool) *p2p { //nolint:revive
 	return &p2p{db: db}
 }
 
-func (p *p2p) Transfer(ctx context.Context, from string, to string, amount int) error {
-	_, err := p.db.Exec(ctx, `
-		WITH 
-			ftx AS (UPDATE merch_shop.auth SET balance = balance-$3 WHERE login=$1),
-			ttx AS (UPDATE merch_shop.auth SET balance = balance+$3 WHERE login=$2)
-		INSERT INTO merch_shop.transfers (src,dst,sum) VALUES ($1, $2, $3)
-		`, from, to, amount)
-	if err != nil {
-		var pgerr *pgconn.PgError
-		if errors.As(err, &pgerr) {
-			if pgerr.ConstraintName == "positive_balance" {
-				return errors.Join(models.ErrNoMoney, err)
+// Transfer moves coins between active users within the sender limits: configured ones
+// unless overridden for the user. Both rows are locked first, in login order to avoid deadlocks,
+// so concurrent deactivation can't leave coins on a deactivated account and concurrent
+// transfers of the sender are counted by the limits. Memo is optional.
+func (p *p2p) Transfer(
+	ctx context.Context, from string, to string, amount int, memo string, limits *models.TransferLimits,
+) error {
+	return inTx(ctx, p.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			SELECT 1 FROM merch_shop.auth WHERE login IN ($1, $2) ORDER BY login FOR UPDATE
+			`, from, to)
+		if err != nil {
+			return errors.Join(models.ErrGeneric, err)
+		}
+		if err = checkLimits(ctx, tx, from, to, amount, limits); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `
+		WITH
+			active AS (
+				SELECT login FROM merch_shop.auth
+				WHERE login IN ($1, $2) AND deleted_at IS NULL
+			),
+			checked AS (SELECT count(*) = 2 AS ok FROM active),
+			ftx AS (UPDATE merch_shop.auth SET balance = balance-$3 WHERE login=$1 AND (SELECT ok FROM checked)),
+			ttx AS (UPDATE merch_shop.auth SET balance = balance+$3 WHERE login=$2 AND (SELECT ok FROM checked))
+		INSERT INTO merch_shop.transfers (src,dst,sum,memo) SELECT $1, $2, $3, NULLIF($4, '') WHERE (SELECT ok FROM checked)
+		`, from, to, amount, memo)
+		if err != nil {
+			var pgerr *pgconn.PgError
+			if errors.As(err, &pgerr) {
+				if pgerr.ConstraintName == "positive_balance" {
+					return errors.Join(models.ErrNoMoney, err)
+				}
 			}
+
+			return errors.Join(models.ErrGeneric, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return inactiveParty(ctx, tx, from, to)
 		}
 
+		return nil
+	})
+}
+
+// inactiveParty explains why transfer was not made: unknown or deactivated user.
+func inactiveParty(ctx context.Context, q dbtx, from string, to string) error {
+	var deleted int
+	err := q.QueryRow(ctx, `
+		SELECT count(*) FROM merch_shop.auth WHERE login IN ($1, $2) AND deleted_at IS NOT NULL
+		`, from, to).Scan(&deleted)
+	if err != nil {
 		return errors.Join(models.ErrGeneric, err)
 	}
+	if deleted > 0 {
+		return models.ErrUserDeactivated
+	}
 
-	return nil
+	return models.ErrNoRows
 }
 
 func (p *p2p) ListReceived(ctx context.Context, user string) ([]models.ReceivedTransfer, error) {
 	var resive []models.ReceivedTransfer
-	rows, err := p.db.Query(ctx, `
+	rows, err := conn(ctx, p.db).Query(ctx, `
 		SELECT src, sum(transfers.sum) AS sum 
 		FROM merch_shop.transfers
 		WHERE dst = $1
@@ -69,7 +113,7 @@ func (p *p2p) ListReceived(ctx context.Context, user string) ([]models.ReceivedT
 
 func (p *p2p) ListSent(ctx context.Context, user string) ([]models.SentTransfer, error) {
 	var sent []models.SentTransfer
-	rows, err := p.db.Query(ctx, `
+	rows, err := conn(ctx, p.db).Query(ctx, `
 		SELECT dst, sum(transfers.sum) AS sum 
 		FROM merch_shop.transfers
 		WHERE src = $1
@@ -90,3 +134,55 @@ func (p *p2p) ListSent(ctx context.Context, user string) ([]models.SentTransfer,
 
 	return sent, nil
 }
+
+// ListTransfers returns a page of user transfers, newest first, starting after the key if given.
+// Sent and received transfers are read by separate index scans and merged.
+func (p *p2p) ListTransfers(
+	ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey,
+) ([]models.Transfer, error) {
+	from, to, afterDate, afterID := historyBounds(f, after)
+	rows, err := conn(ctx, p.db).Query(ctx, `
+		SELECT id, direction, counterparty, sum, COALESCE(memo, ''), dt FROM (
+			(SELECT id, 'out' AS direction, dst AS counterparty, sum, memo, dt
+			FROM merch_shop.transfers
+			WHERE src = $1 AND ($2::text = '' OR dst = $2)
+				AND ($3::timestamptz IS NULL OR dt >= $3) AND ($4::timestamptz IS NULL OR dt < $4)
+				AND ($5::timestamptz IS NULL OR (dt, id) < ($5, $6))
+			ORDER BY dt DESC, id DESC
+			LIMIT $7)
+			UNION ALL
+			(SELECT id, 'in', src, sum, memo, dt
+			FROM merch_shop.transfers
+			WHERE dst = $1 AND ($2::text = '' OR src = $2)
+				AND ($3::timestamptz IS NULL OR dt >= $3) AND ($4::timestamptz IS NULL OR dt < $4)
+				AND ($5::timestamptz IS NULL OR (dt, id) < ($5, $6))
+			ORDER BY dt DESC, id DESC
+			LIMIT $7)
+		) AS t
+		ORDER BY dt DESC, id DESC
+		LIMIT $7
+		`, user, f.Counterparty, from, to, afterDate, afterID, f.Limit)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Transfer, error) {
+		var v models.Transfer
+		err := row.Scan(&v.ID, &v.Direction, &v.Counterparty, &v.Amount, &v.Memo, &v.Date)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return list, nil
+}
+
+// historyBounds converts filter and page key to query arguments.
+func historyBounds(f *models.HistoryFilter, after *models.HistoryKey) (from, to, afterDate *time.Time, afterID int64) {
+	if after != nil {
+		afterDate, afterID = &after.Date, after.ID
+	}
+
+	return f.From, f.To, afterDate, afterID
+}
@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Transfers(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `INSERT INTO merch_shop.auth (login, password, balance) VALUES ('alice', '!', 100), ('bob', '!', 100)`)
+	require.NoError(t, err)
+
+	p := NewP2p(db)
+	require.NoError(t, p.Transfer(ctx, "alice", "bob", 10, "thanks for the review!", &models.TransferLimits{}))
+	require.NoError(t, p.Transfer(ctx, "alice", "bob", 5, "", &models.TransferLimits{}))
+	require.NoError(t, p.Transfer(ctx, "bob", "alice", 3, "", &models.TransferLimits{}))
+
+	list, err := p.ListTransfers(ctx, "bob", &models.HistoryFilter{Limit: 10}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	// новые переводы первыми
+	require.Equal(t, models.TransferOut, list[0].Direction)
+	require.Equal(t, "alice", list[0].Counterparty)
+	require.Equal(t, 3, list[0].Amount)
+	require.Equal(t, models.TransferIn, list[2].Direction)
+	require.Equal(t, "thanks for the review!", list[2].Memo)
+	require.Greater(t, list[0].ID, list[2].ID)
+
+	// следующая страница начинается после ключа последней строки
+	page, err := p.ListTransfers(ctx, "bob", &models.HistoryFilter{Limit: 10},
+		&models.HistoryKey{Date: list[0].Date, ID: list[0].ID})
+	require.NoError(t, err)
+	require.Equal(t, list[1:], page)
+
+	page, err = p.ListTransfers(ctx, "bob", &models.HistoryFilter{Limit: 10, Counterparty: "carol"}, nil)
+	require.NoError(t, err)
+	require.Empty(t, page)
+
+	future := time.Now().Add(time.Hour)
+	page, err = p.ListTransfers(ctx, "bob", &models.HistoryFilter{Limit: 10, From: &future}, nil)
+	require.NoError(t, err)
+	require.Empty(t, page)
+	page, err = p.ListTransfers(ctx, "alice", &models.HistoryFilter{Limit: 1, To: &future}, nil)
+	require.NoError(t, err)
+	require.Equal(t, models.TransferIn, page[0].Direction)
+
+	// сводная история по-прежнему агрегирована по отправителю
+	received, err := p.ListReceived(ctx, "bob")
+	require.NoError(t, err)
+	require.Equal(t, []models.ReceivedTransfer{{From: "alice", Amount: 15}}, received)
+}
+
+func Test_TransferLimits(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('alice', '!', 1000), ('bob', '!', 0), ('carol', '!', 0)
+		`)
+	require.NoError(t, err)
+
+	p := NewP2p(db)
+	def := &models.TransferLimits{MaxAmount: 100, Daily: 150, DailyCounterparty: 120}
+	limit := func(err error) string {
+		var le *models.LimitError
+		require.ErrorAs(t, err, &le)
+		require.ErrorIs(t, err, models.ErrLimitExceeded)
+
+		return le.Limit
+	}
+
+	require.Equal(t, models.LimitAmount, limit(p.Transfer(ctx, "alice", "bob", 101, "", def)))
+	require.NoError(t, p.Transfer(ctx, "alice", "bob", 100, "", def))
+	require.Equal(t, models.LimitCounterparty, limit(p.Transfer(ctx, "alice", "bob", 30, "", def)))
+	require.NoError(t, p.Transfer(ctx, "alice", "carol", 50, "", def))
+	require.Equal(t, models.LimitDaily, limit(p.Transfer(ctx, "alice", "carol", 1, "", def)))
+
+	// отклонённые переводы не списывают монеты
+	var balance int
+	require.NoError(t, db.QueryRow(ctx, `SELECT balance FROM merch_shop.auth WHERE login = 'alice'`).Scan(&balance))
+	require.Equal(t, 850, balance)
+
+	// переопределение заменяет настройки целиком, пропущенные поля берутся из настроек
+	unlimited, hourly := 0, 3
+	require.NoError(t, p.SetTransferLimits(ctx, "alice", &models.TransferLimitsOverride{
+		Daily: &unlimited, DailyCounterparty: &unlimited, HourlyCount: &hourly,
+	}))
+	limits, err := p.GetTransferLimits(ctx, "alice", def)
+	require.NoError(t, err)
+	require.Equal(t, &models.TransferLimits{MaxAmount: 100, HourlyCount: 3}, limits)
+
+	require.NoError(t, p.Transfer(ctx, "alice", "bob", 100, "", def))
+	require.Equal(t, models.LimitHourly, limit(p.Transfer(ctx, "alice", "bob", 1, "", def)))
+
+	require.NoError(t, p.ResetTransferLimits(ctx, "alice"))
+	limits, err = p.GetTransferLimits(ctx, "alice", def)
+	require.NoError(t, err)
+	require.Equal(t, def, limits)
+
+	require.ErrorIs(t, p.SetTransferLimits(ctx, "nobody", &models.TransferLimitsOverride{}), models.ErrNoRows)
+	_, err = p.GetTransferLimits(ctx, "nobody", def)
+	require.ErrorIs(t, err, models.ErrNoRows)
+}
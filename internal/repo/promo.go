@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type promos struct {
+	db *pgxpool.Pool
+}
+
+func NewPromos(db *pgxpool.Pool) *promos { //nolint:revive
+	return &promos{db: db}
+}
+
+func (p *promos) ListPromos(ctx context.Context) ([]models.Promo, error) {
+	rows, err := conn(ctx, p.db).Query(ctx, `
+		SELECT c.code, c.kind, c.amount, c.items, c.valid_from, c.valid_to, c.max_uses, c.max_uses_per_user,
+			(SELECT count(*) FROM merch_shop.promo_redemptions AS r WHERE r.code = c.code),
+			c.disabled_at IS NOT NULL
+		FROM merch_shop.promo_codes AS c
+		ORDER BY c.created_at DESC, c.code
+		`)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Promo, error) {
+		var v models.Promo
+		err := row.Scan(&v.Code, &v.Kind, &v.Amount, &v.Items, &v.From, &v.Until, &v.MaxUses, &v.MaxUsesPerUser, &v.Uses, &v.Disabled)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return list, nil
+}
+
+// CreatePromo refuses codes for unknown items.
+func (p *promos) CreatePromo(ctx context.Context, v *models.Promo) error {
+	tag, err := conn(ctx, p.db).Exec(ctx, `
+		INSERT INTO merch_shop.promo_codes
+			(code, kind, amount, items, valid_from, valid_to, max_uses, max_uses_per_user)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE NOT EXISTS (
+			SELECT 1 FROM unnest($4::text[]) AS x (name)
+			WHERE x.name NOT IN (SELECT name FROM merch_shop.items)
+		)
+		`, v.Code, v.Kind, v.Amount, v.Items, v.From, v.Until, v.MaxUses, v.MaxUsesPerUser)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			switch pgerr.ConstraintName {
+			case "promo_codes_pkey":
+				return errors.Join(models.ErrPromoExists, err)
+			case "valid_amount", "valid_promo_period":
+				return errors.Join(models.ErrInvalidPromo, err)
+			}
+		}
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrItemNotFound
+	}
+
+	return nil
+}
+
+// DisablePromo stops accepting the code, applied discounts stay on purchases.
+func (p *promos) DisablePromo(ctx context.Context, code string) error {
+	tag, err := conn(ctx, p.db).Exec(ctx, `
+		UPDATE merch_shop.promo_codes SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP) WHERE code = $1
+		`, code)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrInvalidPromo
+	}
+
+	return nil
+}
+
+// promo is a redeemed code applied to the order lines.
+type promo struct {
+	code   string
+	kind   string
+	amount int
+	items  []string
+
+	applied bool
+}
+
+// discount returns purchase columns for the item: code, kind and amount
+// are empty when the code does not apply.
+func (p *promo) discount(item string) (*string, string, int) {
+	if p == nil || (len(p.items) > 0 && !slices.Contains(p.items, item)) {
+		return nil, "", 0
+	}
+	p.applied = true
+
+	return &p.code, p.kind, p.amount
+}
+
+// redeemPromo checks the code validity and limits and records its use by the order.
+// The code row lock serializes concurrent redemptions.
+func redeemPromo(ctx context.Context, tx pgx.Tx, code string, buyer string, orderID int64) (*promo, error) {
+	p := &promo{code: code}
+	var active, exhausted bool
+	err := tx.QueryRow(ctx, `
+		SELECT kind, amount, items, disabled_at IS NULL
+			AND valid_from <= CURRENT_TIMESTAMP AND (valid_to IS NULL OR valid_to > CURRENT_TIMESTAMP)
+		FROM merch_shop.promo_codes WHERE code = $1
+		FOR UPDATE
+		`, code).Scan(&p.kind, &p.amount, &p.items, &active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvalidPromo
+	}
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if !active {
+		return nil, models.ErrInvalidPromo
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT (c.max_uses IS NOT NULL AND c.max_uses <= (
+				SELECT count(*) FROM merch_shop.promo_redemptions AS r WHERE r.code = c.code))
+			OR (c.max_uses_per_user IS NOT NULL AND c.max_uses_per_user <= (
+				SELECT count(*) FROM merch_shop.promo_redemptions AS r WHERE r.code = c.code AND r.login = $2))
+		FROM merch_shop.promo_codes AS c WHERE c.code = $1
+		`, code, buyer).Scan(&exhausted)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if exhausted {
+		return nil, models.ErrPromoExhausted
+	}
+
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO merch_shop.promo_redemptions (order_id, code, login) VALUES ($1, $2, $3)
+		`, orderID, code, buyer); err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return p, nil
+}
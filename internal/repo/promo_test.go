@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Promo(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 1000), ('other', '!', 1000);
+		`)
+	require.NoError(t, err)
+
+	p := NewPromos(db)
+	o := NewOrders(db)
+	s := NewShop(db)
+	one, two := 1, 2
+	now := time.Now()
+
+	require.NoError(t, p.CreatePromo(ctx, &models.Promo{
+		Code: "HOODY20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, From: now,
+		MaxUses: &two, MaxUsesPerUser: &one,
+	}))
+	require.NoError(t, p.CreatePromo(ctx, &models.Promo{Code: "MINUS50", Kind: models.PromoFixed, Amount: 50, From: now}))
+	require.ErrorIs(t, p.CreatePromo(ctx, &models.Promo{Code: "HOODY20", Kind: models.PromoFixed, Amount: 1, From: now}),
+		models.ErrPromoExists)
+	require.ErrorIs(t, p.CreatePromo(ctx, &models.Promo{
+		Code: "UNICORN", Kind: models.PromoFixed, Amount: 1, Items: []string{"unicorn"}, From: now,
+	}), models.ErrItemNotFound)
+
+	t.Run("applies_to_listed_items", func(t *testing.T) {
+		order, err := o.PlaceOrder(ctx, "buyer", []models.OrderLine{{Item: "hoody", Quantity: 1}, {Item: "cup", Quantity: 1}}, "HOODY20")
+		require.NoError(t, err)
+		require.Equal(t, []models.OrderLine{
+			{Item: "hoody", Quantity: 1, Price: 240, Discount: 60}, {Item: "cup", Quantity: 1, Price: 20},
+		}, order.Lines)
+		require.Equal(t, 260, order.Total)
+
+		var promo string
+		var discount int
+		require.NoError(t, db.QueryRow(ctx, `
+			SELECT promo, discount FROM merch_shop.purchases WHERE name = 'buyer' AND item = 'hoody'
+			`).Scan(&promo, &discount))
+		require.Equal(t, []any{"HOODY20", 60}, []any{promo, discount})
+	})
+
+	t.Run("per_user_limit", func(t *testing.T) {
+		require.ErrorIs(t, s.BuyItem(ctx, "buyer", "hoody", "HOODY20"), models.ErrPromoExhausted)
+	})
+
+	t.Run("not_applicable", func(t *testing.T) {
+		require.ErrorIs(t, s.BuyItem(ctx, "other", "cup", "HOODY20"), models.ErrInvalidPromo)
+	})
+
+	t.Run("fixed_not_above_price", func(t *testing.T) {
+		order, err := o.PlaceOrder(ctx, "other", []models.OrderLine{{Item: "cup", Quantity: 2}}, "MINUS50")
+		require.NoError(t, err)
+		require.Equal(t, 0, order.Total)
+		require.Equal(t, 20, order.Lines[0].Discount)
+	})
+
+	t.Run("cancel_returns_use", func(t *testing.T) {
+		order, err := o.PlaceOrder(ctx, "other", []models.OrderLine{{Item: "hoody", Quantity: 1}}, "HOODY20")
+		require.NoError(t, err)
+		// глобальный лимит исчерпан
+		_, err = o.PlaceOrder(ctx, "other", []models.OrderLine{{Item: "hoody", Quantity: 1}}, "HOODY20")
+		require.ErrorIs(t, err, models.ErrPromoExhausted)
+
+		_, err = o.CancelOrder(ctx, order.ID, "", models.AdminCancellable, time.Time{})
+		require.NoError(t, err)
+		require.NoError(t, s.BuyItem(ctx, "other", "hoody", "HOODY20"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		require.NoError(t, p.DisablePromo(ctx, "MINUS50"))
+		require.ErrorIs(t, s.BuyItem(ctx, "buyer", "cup", "MINUS50"), models.ErrInvalidPromo)
+		require.ErrorIs(t, p.DisablePromo(ctx, "NOPE"), models.ErrInvalidPromo)
+
+		list, err := p.ListPromos(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+	})
+}
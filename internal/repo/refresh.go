@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type refresh struct {
+	db *pgxpool.Pool
+}
+
+func NewRefresh(db *pgxpool.Pool) *refresh { //nolint:revive
+	return &refresh{db: db}
+}
+
+func (r *refresh) SaveRefresh(ctx context.Context, login string, family string, hash string, ttl time.Duration) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		INSERT INTO merch_shop.refresh_tokens (hash, login, family, expires_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP + $4 * interval '1 second')
+		`, hash, login, family, int(ttl.Seconds()))
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// RotateRefresh marks valid token as used and issues the next one in the same family.
+// Returns token owner or models.ErrNoRows if token is unknown, expired, used or revoked.
+func (r *refresh) RotateRefresh(ctx context.Context, oldHash string, newHash string, ttl time.Duration) (string, error) {
+	var login string
+	err := conn(ctx, r.db).QueryRow(ctx, `
+		WITH old AS (
+			UPDATE merch_shop.refresh_tokens SET used_at = CURRENT_TIMESTAMP
+			WHERE hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+			RETURNING login, family
+		)
+		INSERT INTO merch_shop.refresh_tokens (hash, login, family, expires_at)
+			SELECT $2, old.login, old.family, CURRENT_TIMESTAMP + $3 * interval '1 second'
+			FROM old
+		RETURNING login
+		`, oldHash, newHash, int(ttl.Seconds())).Scan(&login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrNoRows
+		}
+
+		return "", errors.Join(models.ErrGeneric, err)
+	}
+
+	return login, nil
+}
+
+func (r *refresh) GetRefresh(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	rt := &models.RefreshToken{}
+	err := conn(ctx, r.db).QueryRow(ctx, `
+		SELECT login, family::text, used_at IS NOT NULL, revoked_at IS NOT NULL
+		FROM merch_shop.refresh_tokens
+		WHERE hash = $1
+		`, hash).Scan(&rt.Login, &rt.Family, &rt.Used, &rt.Revoked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNoRows
+		}
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return rt, nil
+}
+
+// RevokeRefresh revokes all active refresh tokens of the user.
+func (r *refresh) RevokeRefresh(ctx context.Context, login string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE merch_shop.refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE login = $1 AND revoked_at IS NULL
+		`, login)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshFamily revokes the rotation chain the token belongs to.
+func (r *refresh) RevokeRefreshFamily(ctx context.Context, login string, hash string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE merch_shop.refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE login = $1 AND revoked_at IS NULL
+			AND family = (SELECT family FROM merch_shop.refresh_tokens WHERE hash = $2)
+		`, login, hash)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/cxbelka/winter_2025/migrations"
+)
+
+// testDB starts a clean Postgres with all migrations applied.
+// Tests are skipped when Docker is not available.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	ctx := context.Background()
+	pg, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:13",
+			ExposedPorts: []string{"5432/tcp"},
+			// postgres перезапускается после init-скриптов, готов после второго сообщения
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).WithStartupTimeout(time.Minute),
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": "password",
+				"POSTGRES_USER":     "postgres",
+				"POSTGRES_DB":       "shop",
+			},
+		},
+		Started: true,
+		Logger:  testcontainers.TestLogger(t),
+	})
+	testcontainers.CleanupContainer(t, pg)
+	require.NoError(t, err)
+
+	endpoint, err := pg.PortEndpoint(ctx, "5432/tcp", "")
+	require.NoError(t, err)
+
+	db, err := pgxpool.New(ctx, fmt.Sprintf("postgres://postgres:password@%s/shop?sslmode=disable", endpoint))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	_, err = db.Exec(ctx, string(migrations.Init))
+	require.NoError(t, err)
+
+	return db
+}
+
+// skipWithoutDocker: testcontainers panics instead of skipping if docker host is not found.
+func skipWithoutDocker(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("docker is not available: %v", r)
+		}
+	}()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+// SaveReset stores a new reset code for active user. Previously issued unused codes are dropped.
+func (a *auth) SaveReset(ctx context.Context, login string, hash string, ttl time.Duration) (time.Time, error) {
+	var expires time.Time
+	err := conn(ctx, a.db).QueryRow(ctx, `
+		WITH old AS (
+			DELETE FROM merch_shop.password_resets WHERE login = $1 AND used_at IS NULL
+		)
+		INSERT INTO merch_shop.password_resets (code_hash, login, expires_at)
+			SELECT $2, login, CURRENT_TIMESTAMP + $3 * interval '1 second'
+			FROM merch_shop.auth
+			WHERE login = $1 AND deleted_at IS NULL
+		RETURNING expires_at
+		`, login, hash, int(ttl.Seconds())).Scan(&expires)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, models.ErrNoRows
+		}
+
+		return time.Time{}, errors.Join(models.ErrGeneric, err)
+	}
+
+	return expires, nil
+}
+
+// GetReset returns owner of a valid unused code.
+func (a *auth) GetReset(ctx context.Context, hash string) (string, error) {
+	var login string
+	err := conn(ctx, a.db).QueryRow(ctx, `
+		SELECT login FROM merch_shop.password_resets
+		WHERE code_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		`, hash).Scan(&login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrNoRows
+		}
+
+		return "", errors.Join(models.ErrGeneric, err)
+	}
+
+	return login, nil
+}
+
+// ConsumeReset marks the code used and sets the new password in one statement.
+// Code of a deactivated user is kept unused, as the password is not changed.
+func (a *auth) ConsumeReset(ctx context.Context, hash string, passHash string) (string, error) {
+	var login string
+	err := conn(ctx, a.db).QueryRow(ctx, `
+		WITH r AS (
+			UPDATE merch_shop.password_resets AS pr SET used_at = CURRENT_TIMESTAMP
+			WHERE pr.code_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > CURRENT_TIMESTAMP
+				AND EXISTS (SELECT 1 FROM merch_shop.auth WHERE login = pr.login AND deleted_at IS NULL)
+			RETURNING pr.login
+		)
+		UPDATE merch_shop.auth SET password = $2
+		WHERE login = (SELECT login FROM r) AND deleted_at IS NULL
+		RETURNING login
+		`, hash, passHash).Scan(&login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrNoRows
+		}
+
+		return "", errors.Join(models.ErrGeneric, err)
+	}
+
+	return login, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -18,38 +19,116 @@ func NewShop(db *pgxpool.Pool) *shop { //nolint:revive
 	return &shop{db: db}
 }
 
-func (s *shop) BuyItem(ctx context.Context, buyer string, item string) error {
-	_, err := s.db.Exec(ctx, `
-	   WITH pr AS (
-		INSERT INTO merch_shop.purchases (name, item, sum)
-			SELECT $1, i.name, i.price
-			FROM merch_shop.items AS i
-			WHERE i.name = $2
+// BuyItem refuses unknown, retired and sold out items and respects per-user limit.
+// The purchase is placed as a single line order to be handed out by the office.
+func (s *shop) BuyItem(ctx context.Context, buyer string, item string, promo string) error {
+	return inTx(ctx, s.db, func(tx pgx.Tx) error {
+		_, err := placeOrder(ctx, tx, &models.Order{User: buyer}, []models.OrderLine{{Item: item, Quantity: 1}}, promo)
+		var lerr *models.LineError
+		if errors.As(err, &lerr) {
+			return lerr.Err //nolint:wrapcheck
+		}
+
+		return err
+	})
+}
+
+// buyLine buys qty units of the item and returns the unit sum charged and the discount.
+// The price is the one effective at the transaction start, promo may be nil.
+// The order buyer pays, purchases and per-user limit belong to the gift recipient if any.
+// The caller must hold the item row lock (see placeOrder): it serializes buyers, so purchases
+// counted by a separate statement include those committed while waiting for the lock.
+// A subquery in the UPDATE would see only the snapshot taken before the wait.
+func buyLine(
+	ctx context.Context, q dbtx, order *models.Order, item string, qty int, p *promo,
+) (int, int, error) {
+	code, kind, amount := p.discount(item)
+	owner := order.User
+	if order.Recipient != "" {
+		owner = order.Recipient
+	}
+
+	var bought int
+	if err := q.QueryRow(ctx, `
+		SELECT count(*) FROM merch_shop.purchases WHERE name = $1 AND item = $2 AND refunded_at IS NULL
+		`, owner, item).Scan(&bought); err != nil {
+		return 0, 0, errors.Join(models.ErrGeneric, err)
+	}
+
+	var sum, discount int
+	err := q.QueryRow(ctx, `
+	   WITH it AS (
+		UPDATE merch_shop.items AS i SET stock = i.stock - $3
+		WHERE i.name = $2 AND i.deleted_at IS NULL
+			AND (i.stock IS NULL OR i.stock >= $3)
+			AND (i.max_per_user IS NULL OR i.max_per_user >= $3 + $9)
+		RETURNING i.name, merch_shop.item_price(i.name, CURRENT_TIMESTAMP) AS price
+	   ), d AS (
+		SELECT name, price - discount AS sum, discount FROM (
+			SELECT name, price, CASE $6::text
+				WHEN 'percent' THEN price * $7::integer / 100
+				WHEN 'fixed' THEN LEAST($7::integer, price)
+				ELSE 0 END AS discount
+			FROM it
+		) AS x
+	   ), pr AS (
+		INSERT INTO merch_shop.purchases (name, item, sum, order_id, promo, discount)
+			SELECT $1, d.name, d.sum, $4, $5, d.discount FROM d, generate_series(1, $3)
 		RETURNING sum
 	   )
-	   UPDATE merch_shop.auth SET balance = balance - (SELECT sum FROM pr) WHERE login = $1;
-		`, buyer, item)
+	   UPDATE merch_shop.auth SET balance = balance - (SELECT sum(sum) FROM pr)
+	   WHERE login = $8 AND EXISTS (SELECT 1 FROM pr)
+	   RETURNING (SELECT sum FROM d), (SELECT discount FROM d);
+		`, owner, item, qty, order.ID, code, kind, amount, order.User, bought).Scan(&sum, &discount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, unavailable(ctx, q, item, qty, qty+bought)
+	}
 	if err != nil {
 		var pgerr *pgconn.PgError
 		if errors.As(err, &pgerr) {
 			if pgerr.ConstraintName == "positive_balance" {
-				return errors.Join(models.ErrNoMoney, err)
+				return 0, 0, errors.Join(models.ErrNoMoney, err)
 			}
 		}
 
+		return 0, 0, errors.Join(models.ErrGeneric, err)
+	}
+
+	return sum, discount, nil
+}
+
+// unavailable explains why purchase was not made, owned is the owner's quantity after the purchase.
+func unavailable(ctx context.Context, q dbtx, item string, qty int, owned int) error {
+	var retired, soldOut, limited bool
+	err := q.QueryRow(ctx, `
+		SELECT deleted_at IS NOT NULL, COALESCE(stock < $2, false), COALESCE(max_per_user < $3, false)
+		FROM merch_shop.items
+		WHERE name = $1
+		`, item, qty, owned).Scan(&retired, &soldOut, &limited)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.ErrItemNotFound
+	case err != nil:
 		return errors.Join(models.ErrGeneric, err)
+	case retired:
+		return models.ErrItemUnavailable
+	case soldOut:
+		return models.ErrOutOfStock
+	case limited:
+		return models.ErrPurchaseLimit
 	}
 
-	return nil
+	return models.ErrItemUnavailable
 }
 
 func (s *shop) ListPurchases(ctx context.Context, user string) ([]models.InventoryItem, error) {
 	var purch []models.InventoryItem
-	rows, err := s.db.Query(ctx, `
-		SELECT item, count(purchases.item) AS qty 
-		FROM merch_shop.purchases
-		WHERE name = $1
-		GROUP BY item
+	rows, err := conn(ctx, s.db).Query(ctx, `
+		SELECT p.item, count(p.item) AS qty, i.product, COALESCE(i.size, ''), COALESCE(i.color, '')
+		FROM merch_shop.purchases AS p
+		JOIN merch_shop.items AS i ON i.name = p.item
+		WHERE p.name = $1 AND p.refunded_at IS NULL
+		GROUP BY p.item, i.product, i.size, i.color
 		ORDER BY qty DESC
 		`, user)
 	if err != nil {
@@ -58,7 +137,7 @@ func (s *shop) ListPurchases(ctx context.Context, user string) ([]models.Invento
 	defer rows.Close()
 	for rows.Next() {
 		var v models.InventoryItem
-		if err := rows.Scan(&v.Type, &v.Qty); err != nil {
+		if err := rows.Scan(&v.Type, &v.Qty, &v.Product, &v.Size, &v.Color); err != nil {
 			return nil, errors.Join(models.ErrGeneric, err)
 		}
 		purch = append(purch, v)
@@ -66,3 +145,84 @@ func (s *shop) ListPurchases(ctx context.Context, user string) ([]models.Invento
 
 	return purch, nil
 }
+
+// ListPurchaseHistory returns a page of user purchases, refunded ones included, newest first.
+func (s *shop) ListPurchaseHistory(
+	ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey,
+) ([]models.Purchase, error) {
+	from, to, afterDate, afterID := historyBounds(f, after)
+	rows, err := conn(ctx, s.db).Query(ctx, `
+		SELECT p.id, p.item, p.sum, p.discount, COALESCE(p.promo, ''), p.order_id, COALESCE(o.login, ''),
+			p.refunded_at IS NOT NULL, p.dt
+		FROM merch_shop.purchases AS p
+		LEFT JOIN merch_shop.orders AS o ON o.id = p.order_id AND o.recipient IS NOT NULL
+		WHERE p.name = $1 AND ($2::text = '' OR p.item = $2)
+			AND ($3::timestamptz IS NULL OR p.dt >= $3) AND ($4::timestamptz IS NULL OR p.dt < $4)
+			AND ($5::timestamptz IS NULL OR (p.dt, p.id) < ($5, $6))
+		ORDER BY p.dt DESC, p.id DESC
+		LIMIT $7
+		`, user, f.Item, from, to, afterDate, afterID, f.Limit)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Purchase, error) {
+		var v models.Purchase
+		err := row.Scan(&v.ID, &v.Item, &v.Sum, &v.Discount, &v.Promo, &v.OrderID, &v.From, &v.Refunded, &v.Date)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return list, nil
+}
+
+// ListGiftsReceived lists gifts not refunded, newest first.
+func (s *shop) ListGiftsReceived(ctx context.Context, user string) ([]models.ReceivedGift, error) {
+	rows, err := conn(ctx, s.db).Query(ctx, `
+		SELECT o.login, p.item, COALESCE(o.message, ''), o.created_at
+		FROM merch_shop.orders AS o
+		JOIN merch_shop.purchases AS p ON p.order_id = o.id
+		WHERE o.recipient = $1 AND p.refunded_at IS NULL
+		ORDER BY o.created_at DESC, o.id DESC
+		`, user)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	gifts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ReceivedGift, error) {
+		var v models.ReceivedGift
+		err := row.Scan(&v.From, &v.Item, &v.Message, &v.Date)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return gifts, nil
+}
+
+func (s *shop) ListGiftsSent(ctx context.Context, user string) ([]models.SentGift, error) {
+	rows, err := conn(ctx, s.db).Query(ctx, `
+		SELECT o.recipient, p.item, COALESCE(o.message, ''), o.created_at
+		FROM merch_shop.orders AS o
+		JOIN merch_shop.purchases AS p ON p.order_id = o.id
+		WHERE o.login = $1 AND o.recipient IS NOT NULL AND p.refunded_at IS NULL
+		ORDER BY o.created_at DESC, o.id DESC
+		`, user)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	gifts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.SentGift, error) {
+		var v models.SentGift
+		err := row.Scan(&v.To, &v.Item, &v.Message, &v.Date)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return gifts, nil
+}
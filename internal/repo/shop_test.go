@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_BuyItem(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 100), ('broke', '!', 0);
+		INSERT INTO merch_shop.items (name, price, stock, max_per_user, deleted_at) VALUES
+			('sticker', 0, NULL, NULL, NULL),
+			('old-cup', 20, NULL, NULL, CURRENT_TIMESTAMP),
+			('limited', 10, 1, NULL, NULL),
+			('once', 10, NULL, 1, NULL);
+		`)
+	require.NoError(t, err)
+
+	shop := NewShop(db)
+	state := func(login string) (int, int) {
+		var balance, purchases int
+		err := db.QueryRow(ctx, `
+			SELECT balance, (SELECT count(*) FROM merch_shop.purchases WHERE name = $1)
+			FROM merch_shop.auth WHERE login = $1
+			`, login).Scan(&balance, &purchases)
+		require.NoError(t, err)
+
+		return balance, purchases
+	}
+
+	testCases := []struct {
+		name  string
+		buyer string
+		item  string
+		err   error
+
+		balance   int
+		purchases int
+	}{
+		{name: "unknown", buyer: "buyer", item: "unicorn", err: models.ErrItemNotFound, balance: 100, purchases: 0},
+		{name: "retired", buyer: "buyer", item: "old-cup", err: models.ErrItemUnavailable, balance: 100, purchases: 0},
+		{name: "zero_price", buyer: "broke", item: "sticker", balance: 0, purchases: 1},
+		{name: "no_money", buyer: "broke", item: "cup", err: models.ErrNoMoney, balance: 0, purchases: 1},
+		{name: "priced", buyer: "buyer", item: "cup", balance: 80, purchases: 1},
+		{name: "last_in_stock", buyer: "buyer", item: "limited", balance: 70, purchases: 2},
+		{name: "out_of_stock", buyer: "buyer", item: "limited", err: models.ErrOutOfStock, balance: 70, purchases: 2},
+		{name: "first_of_limit", buyer: "buyer", item: "once", balance: 60, purchases: 3},
+		{name: "limit_reached", buyer: "buyer", item: "once", err: models.ErrPurchaseLimit, balance: 60, purchases: 3},
+	}
+
+	// кейсы зависят от предыдущих покупок, поэтому выполняются по порядку
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := shop.BuyItem(ctx, tc.buyer, tc.item, "")
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+
+			balance, purchases := state(tc.buyer)
+			require.Equal(t, tc.balance, balance)
+			require.Equal(t, tc.purchases, purchases)
+		})
+	}
+}
+
+func Test_BuyItemConcurrent(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 1000);
+		INSERT INTO merch_shop.items (name, price, max_per_user) VALUES ('once', 10, 1);
+		`)
+	require.NoError(t, err)
+
+	// параллельные покупки не превышают лимит на пользователя
+	shop := NewShop(db)
+	errs := make([]error, 5)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = shop.BuyItem(ctx, "buyer", "once", "")
+		}()
+	}
+	wg.Wait()
+
+	bought := 0
+	for _, err := range errs {
+		if err == nil {
+			bought++
+		} else {
+			require.ErrorIs(t, err, models.ErrPurchaseLimit)
+		}
+	}
+	require.Equal(t, 1, bought)
+}
+
+func Test_PurchaseHistory(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `INSERT INTO merch_shop.auth (login, password, balance) VALUES ('buyer', '!', 1000), ('friend', '!', 1000)`)
+	require.NoError(t, err)
+
+	shop := NewShop(db)
+	require.NoError(t, shop.BuyItem(ctx, "buyer", "cup", ""))
+	require.NoError(t, shop.BuyItem(ctx, "buyer", "pen", ""))
+	_, err = NewOrders(db).Gift(ctx, "friend", "buyer", "socks", "", "")
+	require.NoError(t, err)
+
+	list, err := shop.ListPurchaseHistory(ctx, "buyer", &models.HistoryFilter{Limit: 2}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "socks", list[0].Item)
+	require.Equal(t, "friend", list[0].From)
+	require.Equal(t, "pen", list[1].Item)
+
+	list, err = shop.ListPurchaseHistory(ctx, "buyer", &models.HistoryFilter{Limit: 2},
+		&models.HistoryKey{Date: list[1].Date, ID: list[1].ID})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "cup", list[0].Item)
+	require.Equal(t, 20, list[0].Sum)
+
+	list, err = shop.ListPurchaseHistory(ctx, "buyer", &models.HistoryFilter{Limit: 10, Item: "pen"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
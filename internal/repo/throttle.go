@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type throttle struct {
+	db *pgxpool.Pool
+}
+
+func NewThrottle(db *pgxpool.Pool) *throttle { //nolint:revive
+	return &throttle{db: db}
+}
+
+// LockedFor returns the longest remaining lock among keys.
+func (t *throttle) LockedFor(ctx context.Context, keys []string) (time.Duration, error) {
+	var seconds float64
+	err := t.db.QueryRow(ctx, `
+		SELECT COALESCE(max(EXTRACT(EPOCH FROM locked_until - CURRENT_TIMESTAMP)), 0)
+		FROM merch_shop.login_attempts
+		WHERE key = ANY($1) AND locked_until > CURRENT_TIMESTAMP
+		`, keys).Scan(&seconds)
+	if err != nil {
+		return 0, errors.Join(models.ErrGeneric, err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RegisterFailure counts failed attempt and returns number of failures within the window.
+func (t *throttle) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := t.db.QueryRow(ctx, `
+		INSERT INTO merch_shop.login_attempts AS a (key, failures, last_failure) VALUES ($1, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN a.last_failure < CURRENT_TIMESTAMP - $2 * interval '1 second' THEN 1
+				ELSE a.failures + 1
+			END,
+			last_failure = CURRENT_TIMESTAMP
+		RETURNING failures
+		`, key, int(window.Seconds())).Scan(&failures)
+	if err != nil {
+		return 0, errors.Join(models.ErrGeneric, err)
+	}
+
+	return failures, nil
+}
+
+func (t *throttle) Lock(ctx context.Context, key string, d time.Duration) error {
+	_, err := t.db.Exec(ctx, `
+		UPDATE merch_shop.login_attempts SET locked_until = CURRENT_TIMESTAMP + $2 * interval '1 millisecond'
+		WHERE key = $1
+		`, key, d.Milliseconds())
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
+
+func (t *throttle) Reset(ctx context.Context, key string) error {
+	_, err := t.db.Exec(ctx, `DELETE FROM merch_shop.login_attempts WHERE key = $1`, key)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+// notificationsShown limits notifications list, older ones are kept but not returned.
+const notificationsShown = 100
+
+type wishlist struct {
+	db *pgxpool.Pool
+}
+
+func NewWishlist(db *pgxpool.Pool) *wishlist { //nolint:revive
+	return &wishlist{db: db}
+}
+
+func (w *wishlist) ListWishlist(ctx context.Context, login string) ([]models.WishlistItem, error) {
+	rows, err := conn(ctx, w.db).Query(ctx, `
+		SELECT item, price, balance >= price, available, added_at
+		FROM (
+			SELECT w.item, merch_shop.item_price(w.item, CURRENT_TIMESTAMP) AS price, a.balance,
+				i.deleted_at IS NULL AND COALESCE(i.stock > 0, true) AS available, w.added_at
+			FROM merch_shop.wishlist AS w
+			JOIN merch_shop.auth AS a ON a.login = w.login
+			JOIN merch_shop.items AS i ON i.name = w.item
+			WHERE w.login = $1
+		) AS w
+		ORDER BY added_at, item
+		`, login)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WishlistItem, error) {
+		var v models.WishlistItem
+		err := row.Scan(&v.Item, &v.Price, &v.Affordable, &v.Available, &v.AddedAt)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return list, nil
+}
+
+// AddToWishlist remembers current state of the item, so only later changes are notified.
+// Adding the item again keeps it as is.
+func (w *wishlist) AddToWishlist(ctx context.Context, login string, item string) error {
+	tag, err := conn(ctx, w.db).Exec(ctx, `
+		INSERT INTO merch_shop.wishlist (login, item, affordable, available)
+		SELECT a.login, i.name, a.balance >= merch_shop.item_price(i.name, CURRENT_TIMESTAMP),
+			i.deleted_at IS NULL AND COALESCE(i.stock > 0, true)
+		FROM merch_shop.auth AS a, merch_shop.items AS i
+		WHERE a.login = $1 AND i.name = $2
+		ON CONFLICT (login, item) DO UPDATE SET added_at = merch_shop.wishlist.added_at
+		`, login, item)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrItemNotFound
+	}
+
+	return nil
+}
+
+func (w *wishlist) RemoveFromWishlist(ctx context.Context, login string, item string) error {
+	tag, err := conn(ctx, w.db).Exec(ctx, `DELETE FROM merch_shop.wishlist WHERE login = $1 AND item = $2`, login, item)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrItemNotFound
+	}
+
+	return nil
+}
+
+// EvaluateWishlist rechecks wishlist entries of the users and of the items and
+// creates notifications for ones that became affordable or available. Rows are
+// locked, so concurrent evaluations do not notify twice.
+func (w *wishlist) EvaluateWishlist(ctx context.Context, logins []string, items []string) (int, error) {
+	return w.evaluate(ctx, `w.login = ANY($1) OR w.item = ANY($2)`, logins, items)
+}
+
+// EvaluateAllWishlists rechecks every wishlist entry, catching up changes that were
+// never queued: dropped ones and scheduled price changes.
+func (w *wishlist) EvaluateAllWishlists(ctx context.Context) (int, error) {
+	return w.evaluate(ctx, `true`)
+}
+
+func (w *wishlist) evaluate(ctx context.Context, where string, args ...any) (int, error) {
+	var n int
+	err := conn(ctx, w.db).QueryRow(ctx, `
+	   WITH cur AS (
+		SELECT w.login, w.item, w.affordable AS was_affordable, w.available AS was_available,
+			a.balance >= merch_shop.item_price(w.item, CURRENT_TIMESTAMP) AS affordable,
+			i.deleted_at IS NULL AND COALESCE(i.stock > 0, true) AS available
+		FROM merch_shop.wishlist AS w
+		JOIN merch_shop.auth AS a ON a.login = w.login
+		JOIN merch_shop.items AS i ON i.name = w.item
+		WHERE `+where+`
+		FOR UPDATE OF w
+	   ), upd AS (
+		UPDATE merch_shop.wishlist AS w SET affordable = cur.affordable, available = cur.available
+		FROM cur
+		WHERE w.login = cur.login AND w.item = cur.item
+			AND (cur.affordable <> cur.was_affordable OR cur.available <> cur.was_available)
+	   ), ins AS (
+		INSERT INTO merch_shop.notifications (login, kind, item)
+			SELECT login, 'affordable', item FROM cur WHERE affordable AND NOT was_affordable AND available
+			UNION ALL
+			SELECT login, 'restocked', item FROM cur WHERE available AND NOT was_available
+		RETURNING id
+	   )
+	   SELECT count(*) FROM ins;
+		`, args...).Scan(&n)
+	if err != nil {
+		return 0, errors.Join(models.ErrGeneric, err)
+	}
+
+	return n, nil
+}
+
+// ListNotifications returns latest notifications, newest first.
+func (w *wishlist) ListNotifications(ctx context.Context, login string) ([]models.Notification, error) {
+	rows, err := conn(ctx, w.db).Query(ctx, `
+		SELECT id, kind, item, created_at, read_at IS NOT NULL
+		FROM merch_shop.notifications
+		WHERE login = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+		`, login, notificationsShown)
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Notification, error) {
+		var v models.Notification
+		err := row.Scan(&v.ID, &v.Kind, &v.Item, &v.CreatedAt, &v.Read)
+
+		return v, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	return list, nil
+}
+
+func (w *wishlist) ReadNotifications(ctx context.Context, login string) error {
+	_, err := conn(ctx, w.db).Exec(ctx, `
+		UPDATE merch_shop.notifications SET read_at = CURRENT_TIMESTAMP WHERE login = $1 AND read_at IS NULL
+		`, login)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Wishlist(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO merch_shop.auth (login, password, balance) VALUES ('dreamer', '!', 400), ('friend', '!', 1000);
+		UPDATE merch_shop.items SET stock = 0 WHERE name = 'hoody';
+		`)
+	require.NoError(t, err)
+
+	w := NewWishlist(db)
+	require.NoError(t, w.AddToWishlist(ctx, "dreamer", "pink-hoody"))
+	require.NoError(t, w.AddToWishlist(ctx, "dreamer", "pink-hoody"))
+	require.NoError(t, w.AddToWishlist(ctx, "dreamer", "hoody"))
+	require.ErrorIs(t, w.AddToWishlist(ctx, "dreamer", "unicorn"), models.ErrItemNotFound)
+
+	list, err := w.ListWishlist(ctx, "dreamer")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.False(t, list[0].Affordable)
+	require.False(t, list[1].Available)
+
+	// ничего не изменилось - уведомлений нет
+	n, err := w.EvaluateWishlist(ctx, []string{"dreamer"}, nil)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, NewP2p(db).Transfer(ctx, "friend", "dreamer", 100, "", &models.TransferLimits{}))
+	require.NoError(t, NewCatalog(db).UpdateStock(ctx, "hoody", nil, nil))
+	n, err = w.EvaluateWishlist(ctx, []string{"friend", "dreamer"}, []string{"hoody"})
+	require.NoError(t, err)
+	require.Equal(t, 2, n) // pink-hoody по карману, hoody снова в продаже
+
+	// повторная проверка не дублирует уведомления
+	n, err = w.EvaluateWishlist(ctx, nil, []string{"hoody", "pink-hoody"})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	notes, err := w.ListNotifications(ctx, "dreamer")
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	kinds := []string{notes[0].Kind, notes[1].Kind}
+	require.ElementsMatch(t, []string{models.NotifyAffordable, models.NotifyRestocked}, kinds)
+
+	require.NoError(t, w.ReadNotifications(ctx, "dreamer"))
+	notes, err = w.ListNotifications(ctx, "dreamer")
+	require.NoError(t, err)
+	require.True(t, notes[0].Read)
+
+	// изменение, отброшенное из очереди, находит полная проверка
+	require.NoError(t, NewCatalog(db).UpdateStock(ctx, "hoody", new(int), nil))
+	n, err = w.EvaluateWishlist(ctx, nil, []string{"hoody"})
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.NoError(t, NewCatalog(db).UpdateStock(ctx, "hoody", nil, nil))
+	n, err = w.EvaluateAllWishlists(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	require.NoError(t, w.RemoveFromWishlist(ctx, "dreamer", "hoody"))
+	require.ErrorIs(t, w.RemoveFromWishlist(ctx, "dreamer", "hoody"), models.ErrItemNotFound)
+}
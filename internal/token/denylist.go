@@ -0,0 +1,103 @@
+package token
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+var revoked = newDenylist()
+
+// denylist is in-memory copy of revoked tokens. It is filled from the db
+// by Load and by local revocations; entries are useless after token expiry
+// and dropped on the next Load.
+type denylist struct {
+	mu       sync.RWMutex
+	tokens   map[string]time.Time             // jti -> token expiry
+	subjects map[string]models.RevokedSession // login -> tokens issued before RevokedAt are invalid
+}
+
+func newDenylist() *denylist {
+	return &denylist{
+		tokens:   map[string]time.Time{},
+		subjects: map[string]models.RevokedSession{},
+	}
+}
+
+func (d *denylist) check(c *Claims) bool {
+	now := time.Now()
+
+	d.mu.RLock()
+	exp, tokenRevoked := d.tokens[c.ID]
+	session, subjectRevoked := d.subjects[c.Subject]
+	d.mu.RUnlock()
+
+	if tokenRevoked && now.Before(exp) {
+		return true
+	}
+	if subjectRevoked && now.Before(session.ExpiresAt) {
+		return !issuedAfter(c, session.RevokedAt)
+	}
+
+	return false
+}
+
+// issuedAfter compares exactly by iat_us. Tokens without it have iat in seconds only
+// and are revoked in the whole second of the revocation.
+func issuedAfter(c *Claims, t time.Time) bool {
+	switch {
+	case c.IssuedAtMicro != 0:
+		return time.UnixMicro(c.IssuedAtMicro).After(t)
+	case c.IssuedAt != nil:
+		return c.IssuedAt.After(t)
+	}
+
+	return false
+}
+
+// Revoke adds single token to the local denylist.
+func Revoke(jti string, expiresAt time.Time) {
+	revoked.mu.Lock()
+	defer revoked.mu.Unlock()
+
+	revoked.tokens[jti] = expiresAt
+}
+
+// RevokeSubject invalidates all tokens of the user issued before session.RevokedAt.
+func RevokeSubject(session models.RevokedSession) {
+	revoked.mu.Lock()
+	defer revoked.mu.Unlock()
+
+	revoked.subjects[session.Login] = session
+}
+
+// Load merges revocations stored in the db (possibly made by other instances)
+// into the local denylist and drops expired entries. Revocation is never undone,
+// so merging is safe against concurrent local Revoke calls.
+func Load(tokens []models.RevokedToken, sessions []models.RevokedSession) {
+	now := time.Now()
+
+	revoked.mu.Lock()
+	defer revoked.mu.Unlock()
+
+	for _, t := range tokens {
+		revoked.tokens[t.ID] = t.ExpiresAt
+	}
+	for _, s := range sessions {
+		if cur, ok := revoked.subjects[s.Login]; !ok || cur.RevokedAt.Before(s.RevokedAt) {
+			revoked.subjects[s.Login] = s
+		}
+	}
+
+	for jti, exp := range revoked.tokens {
+		if !now.Before(exp) {
+			delete(revoked.tokens, jti)
+		}
+	}
+	for login, s := range revoked.subjects {
+		if !now.Before(s.ExpiresAt) {
+			delete(revoked.subjects, login)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_denylistSubject(t *testing.T) {
+	revokedAt := time.Date(2025, 2, 1, 10, 0, 0, 500_000_000, time.UTC)
+	d := newDenylist()
+	d.subjects["u1"] = models.RevokedSession{Login: "u1", RevokedAt: revokedAt, ExpiresAt: time.Now().Add(time.Hour)}
+
+	claims := func(issued time.Time, micro bool) *Claims {
+		c := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "u1", IssuedAt: jwt.NewNumericDate(issued)}}
+		if micro {
+			c.IssuedAtMicro = issued.UnixMicro()
+		}
+
+		return c
+	}
+
+	// в ту же секунду: до отзыва токен недействителен, после - действителен
+	require.True(t, d.check(claims(revokedAt.Add(-time.Millisecond), true)))
+	require.True(t, d.check(claims(revokedAt, true)))
+	require.False(t, d.check(claims(revokedAt.Add(time.Microsecond), true)))
+	// без iat_us вся секунда отзыва считается отозванной
+	require.True(t, d.check(claims(revokedAt.Add(time.Millisecond), false)))
+	require.False(t, d.check(claims(revokedAt.Add(time.Second), false)))
+}
@@ -0,0 +1,240 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey         = errors.New("unknown signing key")
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+)
+
+// key is a single signing or verification key. For HS256 both sign and verify
+// hold the shared secret and the key is never published.
+type key struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   any // nil for verify-only keys
+	verify any
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// loadKeys reads signing key from JWT_SIGNING_KEY (PEM) or JWT_SIGNING_KEY_FILE
+// and extra verification keys (previous ones during rotation) from
+// JWT_VERIFY_KEYS or comma separated JWT_VERIFY_KEYS_FILE.
+// JWT_SECRET keeps HS256 working: it signs if no private key is given
+// and otherwise only verifies tokens issued before the switch.
+func loadKeys(cfg *JWT) error {
+	cfg.keys = map[string]*key{}
+
+	secret := os.Getenv("JWT_SECRET")
+	hs := &key{method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}
+	cfg.signing = hs
+
+	blocks, err := readPEM("JWT_SIGNING_KEY")
+	if err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		k, err := parsePrivate(b)
+		if err != nil {
+			return err
+		}
+		cfg.keys[k.kid] = k
+		cfg.signing = k
+	}
+	if cfg.signing == hs || secret != "" {
+		cfg.keys[hs.kid] = hs
+	}
+
+	blocks, err = readPEM("JWT_VERIFY_KEYS")
+	if err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		k, err := parsePublic(b)
+		if err != nil {
+			return err
+		}
+		if _, ok := cfg.keys[k.kid]; !ok {
+			cfg.keys[k.kid] = k
+		}
+	}
+
+	return nil
+}
+
+func readPEM(env string) ([]*pem.Block, error) {
+	data := []byte(os.Getenv(env))
+	for _, path := range strings.Split(os.Getenv(env+"_FILE"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+		data = append(data, '\n')
+		data = append(data, b...)
+	}
+
+	var blocks []*pem.Block
+	for {
+		var b *pem.Block
+		b, data = pem.Decode(data)
+		if b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+
+	return blocks, nil
+}
+
+func parsePrivate(b *pem.Block) (*key, error) {
+	var (
+		priv any
+		err  error
+	)
+	if b.Type == "RSA PRIVATE KEY" {
+		priv, err = x509.ParsePKCS1PrivateKey(b.Bytes)
+	} else {
+		priv, err = x509.ParsePKCS8PrivateKey(b.Bytes)
+	}
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKeyType
+	}
+	k, err := newPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	k.sign = priv
+
+	return k, nil
+}
+
+func parsePublic(b *pem.Block) (*key, error) {
+	pub, err := x509.ParsePKIXPublicKey(b.Bytes)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return newPublicKey(pub)
+}
+
+func newPublicKey(pub any) (*key, error) {
+	k := &key{verify: pub}
+	switch pub.(type) {
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	case *rsa.PublicKey:
+		k.method = jwt.SigningMethodRS256
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	k.kid = thumbprint(k.jwk())
+
+	return k, nil
+}
+
+// jwk returns public part of the key as published in JWKS.
+func (k *key) jwk() JWK {
+	switch pub := k.verify.(type) {
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: k.kid, Use: "sig", Alg: k.method.Alg(), Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(pub)}
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: k.kid, Use: "sig", Alg: k.method.Alg(),
+			N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())}
+	default:
+		return JWK{}
+	}
+}
+
+// thumbprint is RFC 7638 JWK thumbprint: sha256 over required members in lexicographic order.
+func thumbprint(j JWK) string {
+	var required any
+	switch j.Kty {
+	case "OKP":
+		required = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	case "RSA":
+		required = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	}
+	b, _ := json.Marshal(required) //nolint:errchkjson
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// keyFunc selects verification key by `kid` header and checks that
+// algorithm matches the key to prevent algorithm substitution.
+func keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := jwtCfg.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, ErrUnknownKey
+	}
+
+	return k.verify, nil
+}
+
+// PublicKeys returns all asymmetric verification keys for /.well-known/jwks.json.
+func PublicKeys() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range jwtCfg.keys {
+		if k.method == jwt.SigningMethodHS256 {
+			continue
+		}
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+
+	return set
+}
@@ -0,0 +1,42 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Pending MFA token proves the password was checked and is exchanged
+// for access/refresh pair after the second factor.
+
+const (
+	mfaPendingAudience = "mfa-pending"
+	mfaPendingTTL      = 5 * time.Minute
+)
+
+func CreateMFAPending(user string) (string, error) {
+	return sign(Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    "avito-merch-shop",
+			Subject:   user,
+			Audience:  jwt.ClaimStrings{mfaPendingAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaPendingTTL)),
+		},
+	})
+}
+
+// CheckMFAPending accepts only pending MFA tokens, access tokens are rejected.
+func CheckMFAPending(token string) (*Claims, error) {
+	claims, err := parse(token, jwt.WithAudience(mfaPendingAudience))
+	if err != nil {
+		return nil, err
+	}
+	if revoked.check(claims) {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
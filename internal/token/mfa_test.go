@@ -0,0 +1,27 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MFAPending(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test")
+	require.NoError(t, Reinit())
+
+	pending, err := CreateMFAPending("u1")
+	require.NoError(t, err)
+	access, err := Create("u1")
+	require.NoError(t, err)
+
+	claims, err := CheckMFAPending(pending)
+	require.NoError(t, err)
+	require.Equal(t, "u1", claims.Subject)
+
+	// токены не взаимозаменяемы
+	_, err = Check(pending)
+	require.ErrorIs(t, err, ErrWrongTokenType)
+	_, err = CheckMFAPending(access)
+	require.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const refreshTokenLen = 32
+
+// CreateRefresh returns opaque refresh token for the client and its hash to be stored.
+func CreateRefresh() (string, string, error) {
+	b := make([]byte, refreshTokenLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err //nolint:wrapcheck
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+
+	return raw, HashRefresh(raw), nil
+}
+
+func HashRefresh(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func RefreshTTL() time.Duration {
+	return jwtCfg.refreshTTL
+}
@@ -0,0 +1,12 @@
+package token
+
+// Password reset codes are opaque random strings like refresh tokens,
+// only the hash is stored.
+
+func CreateResetCode() (string, string, error) {
+	return CreateRefresh()
+}
+
+func HashResetCode(raw string) string {
+	return HashRefresh(raw)
+}
@@ -2,61 +2,144 @@ package token
 
 import (
 	"context"
+	"errors"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 const (
-	defaultTokenTTL = 10 * time.Minute
+	defaultTokenTTL   = 10 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
 )
 
 var (
 	jwtCfg *JWT
 
-	ctxKey = &struct{}{}
+	// ключи разного значения: указатели на struct{}{} могут совпадать
+	ctxKey       = ctxKeyType("user")
+	ctxClaimsKey = ctxKeyType("claims")
+
+	ErrTokenRevoked   = errors.New("token revoked")
+	ErrWrongTokenType = errors.New("wrong token type")
 )
 
+type ctxKeyType string
+
 type JWT struct {
-	secret string
-	ttl    time.Duration
+	signing    *key
+	keys       map[string]*key // kid -> verification key, "" for HS256
+	ttl        time.Duration
+	refreshTTL time.Duration
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	// IssuedAtMicro is iat with microseconds, so that revocation of user sessions
+	// tells apart tokens issued in the same second before and after it.
+	IssuedAtMicro int64 `json:"iat_us,omitempty"`
+}
+
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(c.Roles, r) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func init() {
-	Reinit()
+	_ = Reinit() // app вызывает Reinit повторно и падает на невалидных ключах
 }
 
-func Reinit() {
+func Reinit() error {
 	jwtCfg = &JWT{}
+	revoked = newDenylist()
+
 	var err error
-	jwtCfg.secret = os.Getenv("JWT_SECRET")
 	jwtCfg.ttl, err = time.ParseDuration(os.Getenv("JWT_TTL"))
 	if err != nil {
 		jwtCfg.ttl = defaultTokenTTL
 	}
+	jwtCfg.refreshTTL, err = time.ParseDuration(os.Getenv("JWT_REFRESH_TTL"))
+	if err != nil {
+		jwtCfg.refreshTTL = defaultRefreshTTL
+	}
+
+	return loadKeys(jwtCfg)
 }
 
-func Create(user string) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Issuer:    "avito-merch-shop",
-		Subject:   user,
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtCfg.ttl)),
+// Create issues access token. Roles are fixed for the token lifetime,
+// changing them requires revoking user sessions.
+func Create(user string, roles ...string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    "avito-merch-shop",
+			Subject:   user,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(jwtCfg.ttl)),
+		},
+		Roles:         roles,
+		IssuedAtMicro: now.UnixMicro(),
 	}
 
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtCfg.secret)) //nolint:wrapcheck
+	return sign(claims)
 }
 
-func Check(token string) (string, error) {
-	claims := jwt.RegisteredClaims{}
-	_, err := jwt.ParseWithClaims(
-		token, &claims,
-		func(_ *jwt.Token) (interface{}, error) { return []byte(jwtCfg.secret), nil },
+func sign(claims Claims) (string, error) {
+	if jwtCfg.signing == nil {
+		return "", ErrUnknownKey
+	}
+	t := jwt.NewWithClaims(jwtCfg.signing.method, claims)
+	if jwtCfg.signing.kid != "" {
+		t.Header["kid"] = jwtCfg.signing.kid
+	}
+
+	return t.SignedString(jwtCfg.signing.sign) //nolint:wrapcheck
+}
+
+// Check validates signature and expiration and rejects revoked tokens.
+// Pending MFA tokens are not accepted as access tokens.
+func Check(token string) (*Claims, error) {
+	claims, err := parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(claims.Audience, mfaPendingAudience) {
+		return nil, ErrWrongTokenType
+	}
+	if revoked.check(claims) {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+func parse(token string, opts ...jwt.ParserOption) (*Claims, error) {
+	claims := &Claims{}
+	opts = append(opts,
 		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodHS256.Alg(),
+		}),
 	)
+	if _, err := jwt.ParseWithClaims(token, claims, keyFunc, opts...); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return claims, nil
+}
 
-	return claims.Subject, err //nolint:wrapcheck
+func TTL() time.Duration {
+	return jwtCfg.ttl
 }
 
 func ContextWithUser(ctx context.Context, user string) context.Context {
@@ -71,3 +154,16 @@ func UserFromContext(ctx context.Context) string {
 
 	return v
 }
+
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, ctxClaimsKey, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) *Claims {
+	v, ok := ctx.Value(ctxClaimsKey).(*Claims)
+	if !ok {
+		return nil
+	}
+
+	return v
+}
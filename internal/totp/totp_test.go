@@ -0,0 +1,89 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 Appendix B, SHA1 secret "12345678901234567890", last 6 of 8 digits.
+func Test_Code(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	testCases := map[string]struct {
+		unix int64
+		code string
+	}{
+		"59":         {unix: 59, code: "287082"},
+		"1111111109": {unix: 1111111109, code: "081804"},
+		"1111111111": {unix: 1111111111, code: "050471"},
+		"1234567890": {unix: 1234567890, code: "005924"},
+		"2000000000": {unix: 2000000000, code: "279037"},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			c, err := Code(secret, time.Unix(tc.unix, 0))
+			require.NoError(t, err)
+			require.Equal(t, tc.code, c)
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	c, err := Code(secret, now)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		at   time.Time
+		code string
+		ok   bool
+	}{
+		"same_step":   {at: now, code: c, ok: true},
+		"prev_step":   {at: now.Add(Period), code: c, ok: true},
+		"next_step":   {at: now.Add(-Period), code: c, ok: true},
+		"too_late":    {at: now.Add(2 * Period), code: c, ok: false},
+		"wrong_code":  {at: now, code: "000000", ok: c == "000000"},
+		"wrong_len":   {at: now, code: c + "1", ok: false},
+		"empty_input": {at: now, code: "", ok: false},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			step, ok := Validate(secret, tc.code, tc.at, 1)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, Step(now), step)
+			}
+		})
+	}
+}
+
+func Test_URI(t *testing.T) {
+	uri := URI("shop", "u1", "ABC")
+	require.Equal(t, "otpauth://totp/shop:u1?algorithm=SHA1&digits=6&issuer=shop&period=30&secret=ABC", uri)
+}
+
+func Test_RecoveryCodes(t *testing.T) {
+	codes, err := RecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, c := range codes {
+		require.Len(t, c, 11)
+		require.False(t, seen[c])
+		seen[c] = true
+	}
+	// ввод нечувствителен к регистру и дефису
+	require.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+}
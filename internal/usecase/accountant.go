@@ -5,6 +5,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/cxbelka/winter_2025/internal/logger"
 	"github.com/cxbelka/winter_2025/internal/models"
@@ -15,50 +16,157 @@ type balance interface {
 }
 
 type p2p interface {
-	Transfer(ctx context.Context, from string, to string, amount int) error
+	Transfer(ctx context.Context, from string, to string, amount int, memo string, limits *models.TransferLimits) error
+	GetTransferLimits(ctx context.Context, login string, def *models.TransferLimits) (*models.TransferLimits, error)
+	SetTransferLimits(ctx context.Context, login string, o *models.TransferLimitsOverride) error
+	ResetTransferLimits(ctx context.Context, login string) error
+	ListTransfers(ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey) ([]models.Transfer, error)
 	ListReceived(ctx context.Context, user string) ([]models.ReceivedTransfer, error)
 	ListSent(ctx context.Context, user string) ([]models.SentTransfer, error)
 }
 
 type shop interface {
-	BuyItem(ctx context.Context, buyer string, item string) error
+	BuyItem(ctx context.Context, buyer string, item string, promo string) error
 	ListPurchases(ctx context.Context, user string) ([]models.InventoryItem, error)
+	ListPurchaseHistory(
+		ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey,
+	) ([]models.Purchase, error)
+	ListGiftsReceived(ctx context.Context, user string) ([]models.ReceivedGift, error)
+	ListGiftsSent(ctx context.Context, user string) ([]models.SentGift, error)
 }
 
 type accountant struct {
 	balance balance
 	p2p     p2p
 	shop    shop
+	wishes  wishes
+
+	limits models.TransferLimits
 }
 
+// NewAccountant: limits apply to users without an admin override.
 func NewAccountant(
 	balance balance,
 	p2p p2p,
 	shop shop,
+	wishes wishes,
+	limits models.TransferLimits,
 ) *accountant { //nolint:revive
-	return &accountant{balance: balance, p2p: p2p, shop: shop}
+	return &accountant{balance: balance, p2p: p2p, shop: shop, wishes: wishes, limits: limits}
 }
 
-func (acc *accountant) Buy(ctx context.Context, user string, item string) error {
-	if err := acc.shop.BuyItem(ctx, user, item); err != nil {
+// Buy charges the price effective now, with the promo code discount if given.
+func (acc *accountant) Buy(ctx context.Context, user string, item string, promo string) error {
+	if err := acc.shop.BuyItem(ctx, user, item, promoCode(promo)); err != nil {
 		logger.AddError(ctx, err)
 
 		return errors.Join(models.ErrGeneric, err)
 	}
+	acc.wishes.Changed(ctx, []string{user}, []string{item})
 
 	return nil
 }
 
-func (acc *accountant) Transfer(ctx context.Context, from string, to string, amount int) error {
-	if err := acc.p2p.Transfer(ctx, from, to, amount); err != nil {
+// Transfer memo is optional, it is shown to both parties in the transfer list.
+func (acc *accountant) Transfer(ctx context.Context, from string, to string, amount int, memo string) error {
+	if err := acc.p2p.Transfer(ctx, from, to, amount, strings.TrimSpace(memo), &acc.limits); err != nil {
 		logger.AddError(ctx, err)
 
 		return errors.Join(models.ErrGeneric, err)
 	}
+	acc.wishes.Changed(ctx, []string{from, to}, nil)
 
 	return nil
 }
 
+// TransferLimits returns limits in effect for the user: the admin override or configured ones.
+// Repository errors are passed as is, so an unknown login stays models.ErrNoRows.
+func (acc *accountant) TransferLimits(ctx context.Context, login string) (*models.TransferLimits, error) {
+	limits, err := acc.p2p.GetTransferLimits(ctx, login, &acc.limits)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return limits, nil
+}
+
+// SetTransferLimits replaces the user override, omitted limits are the configured ones.
+func (acc *accountant) SetTransferLimits(
+	ctx context.Context, login string, o *models.TransferLimitsOverride,
+) (*models.TransferLimits, error) {
+	if err := acc.p2p.SetTransferLimits(ctx, login, o); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return acc.TransferLimits(ctx, login)
+}
+
+func (acc *accountant) ResetTransferLimits(ctx context.Context, login string) error {
+	if err := acc.p2p.ResetTransferLimits(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// Transfers is a page of individual transfers, while Info aggregates them per counterparty.
+func (acc *accountant) Transfers(ctx context.Context, user string, f *models.HistoryFilter) (*models.TransferPage, error) {
+	q, after, err := historyPage(f)
+	if err != nil {
+		return nil, err
+	}
+	list, err := acc.p2p.ListTransfers(ctx, user, q, after)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	page := &models.TransferPage{Transfers: list}
+	if len(list) == q.Limit {
+		page.Transfers = list[:len(list)-1]
+		last := page.Transfers[len(page.Transfers)-1]
+		page.NextCursor = encodeCursor(last.Date, last.ID)
+	}
+	if page.Transfers == nil {
+		page.Transfers = []models.Transfer{}
+	}
+
+	return page, nil
+}
+
+// Purchases is a page of bought units, refunded ones included, while Info counts the inventory.
+func (acc *accountant) Purchases(ctx context.Context, user string, f *models.HistoryFilter) (*models.PurchasePage, error) {
+	q, after, err := historyPage(f)
+	if err != nil {
+		return nil, err
+	}
+	list, err := acc.shop.ListPurchaseHistory(ctx, user, q, after)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	page := &models.PurchasePage{Purchases: list}
+	if len(list) == q.Limit {
+		page.Purchases = list[:len(list)-1]
+		last := page.Purchases[len(page.Purchases)-1]
+		page.NextCursor = encodeCursor(last.Date, last.ID)
+	}
+	if page.Purchases == nil {
+		page.Purchases = []models.Purchase{}
+	}
+
+	return page, nil
+}
+
 func (acc *accountant) Info(ctx context.Context, user string) (*models.InfoResponse, error) {
 	var err error
 	info := &models.InfoResponse{}
@@ -83,6 +191,16 @@ func (acc *accountant) Info(ctx context.Context, user string) (*models.InfoRespo
 
 		return nil, errors.Join(models.ErrGeneric, err)
 	}
+	if info.Gifts.Received, err = acc.shop.ListGiftsReceived(ctx, user); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if info.Gifts.Sent, err = acc.shop.ListGiftsSent(ctx, user); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
 
 	return info, nil
 }
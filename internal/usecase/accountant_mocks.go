@@ -80,6 +80,21 @@ func (m *Mockp2p) EXPECT() *Mockp2pMockRecorder {
 	return m.recorder
 }
 
+// GetTransferLimits mocks base method.
+func (m *Mockp2p) GetTransferLimits(ctx context.Context, login string, def *models.TransferLimits) (*models.TransferLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferLimits", ctx, login, def)
+	ret0, _ := ret[0].(*models.TransferLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferLimits indicates an expected call of GetTransferLimits.
+func (mr *Mockp2pMockRecorder) GetTransferLimits(ctx, login, def any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferLimits", reflect.TypeOf((*Mockp2p)(nil).GetTransferLimits), ctx, login, def)
+}
+
 // ListReceived mocks base method.
 func (m *Mockp2p) ListReceived(ctx context.Context, user string) ([]models.ReceivedTransfer, error) {
 	m.ctrl.T.Helper()
@@ -110,18 +125,61 @@ func (mr *Mockp2pMockRecorder) ListSent(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSent", reflect.TypeOf((*Mockp2p)(nil).ListSent), ctx, user)
 }
 
+// ListTransfers mocks base method.
+func (m *Mockp2p) ListTransfers(ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey) ([]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", ctx, user, f, after)
+	ret0, _ := ret[0].([]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *Mockp2pMockRecorder) ListTransfers(ctx, user, f, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*Mockp2p)(nil).ListTransfers), ctx, user, f, after)
+}
+
+// ResetTransferLimits mocks base method.
+func (m *Mockp2p) ResetTransferLimits(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTransferLimits", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetTransferLimits indicates an expected call of ResetTransferLimits.
+func (mr *Mockp2pMockRecorder) ResetTransferLimits(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTransferLimits", reflect.TypeOf((*Mockp2p)(nil).ResetTransferLimits), ctx, login)
+}
+
+// SetTransferLimits mocks base method.
+func (m *Mockp2p) SetTransferLimits(ctx context.Context, login string, o *models.TransferLimitsOverride) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferLimits", ctx, login, o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTransferLimits indicates an expected call of SetTransferLimits.
+func (mr *Mockp2pMockRecorder) SetTransferLimits(ctx, login, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferLimits", reflect.TypeOf((*Mockp2p)(nil).SetTransferLimits), ctx, login, o)
+}
+
 // Transfer mocks base method.
-func (m *Mockp2p) Transfer(ctx context.Context, from, to string, amount int) error {
+func (m *Mockp2p) Transfer(ctx context.Context, from, to string, amount int, memo string, limits *models.TransferLimits) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", ctx, from, to, amount)
+	ret := m.ctrl.Call(m, "Transfer", ctx, from, to, amount, memo, limits)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Transfer indicates an expected call of Transfer.
-func (mr *Mockp2pMockRecorder) Transfer(ctx, from, to, amount any) *gomock.Call {
+func (mr *Mockp2pMockRecorder) Transfer(ctx, from, to, amount, memo, limits any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockp2p)(nil).Transfer), ctx, from, to, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockp2p)(nil).Transfer), ctx, from, to, amount, memo, limits)
 }
 
 // Mockshop is a mock of shop interface.
@@ -149,17 +207,62 @@ func (m *Mockshop) EXPECT() *MockshopMockRecorder {
 }
 
 // BuyItem mocks base method.
-func (m *Mockshop) BuyItem(ctx context.Context, buyer, item string) error {
+func (m *Mockshop) BuyItem(ctx context.Context, buyer, item, promo string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BuyItem", ctx, buyer, item)
+	ret := m.ctrl.Call(m, "BuyItem", ctx, buyer, item, promo)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // BuyItem indicates an expected call of BuyItem.
-func (mr *MockshopMockRecorder) BuyItem(ctx, buyer, item any) *gomock.Call {
+func (mr *MockshopMockRecorder) BuyItem(ctx, buyer, item, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*Mockshop)(nil).BuyItem), ctx, buyer, item, promo)
+}
+
+// ListGiftsReceived mocks base method.
+func (m *Mockshop) ListGiftsReceived(ctx context.Context, user string) ([]models.ReceivedGift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGiftsReceived", ctx, user)
+	ret0, _ := ret[0].([]models.ReceivedGift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGiftsReceived indicates an expected call of ListGiftsReceived.
+func (mr *MockshopMockRecorder) ListGiftsReceived(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGiftsReceived", reflect.TypeOf((*Mockshop)(nil).ListGiftsReceived), ctx, user)
+}
+
+// ListGiftsSent mocks base method.
+func (m *Mockshop) ListGiftsSent(ctx context.Context, user string) ([]models.SentGift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGiftsSent", ctx, user)
+	ret0, _ := ret[0].([]models.SentGift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGiftsSent indicates an expected call of ListGiftsSent.
+func (mr *MockshopMockRecorder) ListGiftsSent(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGiftsSent", reflect.TypeOf((*Mockshop)(nil).ListGiftsSent), ctx, user)
+}
+
+// ListPurchaseHistory mocks base method.
+func (m *Mockshop) ListPurchaseHistory(ctx context.Context, user string, f *models.HistoryFilter, after *models.HistoryKey) ([]models.Purchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPurchaseHistory", ctx, user, f, after)
+	ret0, _ := ret[0].([]models.Purchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPurchaseHistory indicates an expected call of ListPurchaseHistory.
+func (mr *MockshopMockRecorder) ListPurchaseHistory(ctx, user, f, after any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*Mockshop)(nil).BuyItem), ctx, buyer, item)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPurchaseHistory", reflect.TypeOf((*Mockshop)(nil).ListPurchaseHistory), ctx, user, f, after)
 }
 
 // ListPurchases mocks base method.
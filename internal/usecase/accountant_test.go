@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/cxbelka/winter_2025/internal/models"
 	"github.com/stretchr/testify/require"
@@ -16,8 +17,9 @@ func Test_Buy(t *testing.T) {
 	ctx := context.Background()
 
 	type _tc struct {
-		user string
-		item string
+		user  string
+		item  string
+		promo string
 
 		err error
 
@@ -34,7 +36,7 @@ func Test_Buy(t *testing.T) {
 			init: func(t *_tc) shop {
 				mock := NewMockshop(ctrl)
 
-				mock.EXPECT().BuyItem(ctx, t.user, t.item).Return(nil)
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "").Return(nil)
 
 				return mock
 			},
@@ -48,7 +50,7 @@ func Test_Buy(t *testing.T) {
 			init: func(t *_tc) shop {
 				mock := NewMockshop(ctrl)
 
-				mock.EXPECT().BuyItem(ctx, t.user, t.item).Return(models.ErrNoRows)
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "").Return(models.ErrNoRows)
 
 				return mock
 			},
@@ -62,7 +64,35 @@ func Test_Buy(t *testing.T) {
 			init: func(t *_tc) shop {
 				mock := NewMockshop(ctrl)
 
-				mock.EXPECT().BuyItem(ctx, t.user, t.item).Return(models.ErrNoMoney)
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "").Return(models.ErrNoMoney)
+
+				return mock
+			},
+		},
+		"promo_normalized": {
+			user:  "u1",
+			item:  "hoody",
+			promo: " hoody20 ",
+
+			init: func(t *_tc) shop {
+				mock := NewMockshop(ctrl)
+
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "HOODY20").Return(nil)
+
+				return mock
+			},
+		},
+		"promo_exhausted": {
+			user:  "u1",
+			item:  "hoody",
+			promo: "HOODY20",
+
+			err: models.ErrPromoExhausted,
+
+			init: func(t *_tc) shop {
+				mock := NewMockshop(ctrl)
+
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "HOODY20").Return(models.ErrPromoExhausted)
 
 				return mock
 			},
@@ -76,7 +106,7 @@ func Test_Buy(t *testing.T) {
 			init: func(t *_tc) shop {
 				mock := NewMockshop(ctrl)
 
-				mock.EXPECT().BuyItem(ctx, t.user, t.item).Return(models.ErrGeneric)
+				mock.EXPECT().BuyItem(ctx, t.user, t.item, "").Return(models.ErrGeneric)
 
 				return mock
 			},
@@ -88,9 +118,14 @@ func Test_Buy(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tc := tc
 
-			uc := NewAccountant(nil, nil, tc.init(&tc))
+			// после успешной покупки списки желаний покупателя и товара перепроверяются
+			wishes := NewMockwishes(ctrl)
+			if tc.err == nil {
+				wishes.EXPECT().Changed(gomock.Any(), []string{tc.user}, []string{tc.item})
+			}
+			uc := NewAccountant(nil, nil, tc.init(&tc), wishes, models.TransferLimits{})
 
-			err := uc.Buy(ctx, tc.user, tc.item)
+			err := uc.Buy(ctx, tc.user, tc.item, tc.promo)
 			require.ErrorIs(t, err, tc.err)
 		})
 	}
@@ -105,6 +140,7 @@ func Test_Transfer(t *testing.T) {
 		from   string
 		to     string
 		amount int
+		memo   string
 
 		err error
 
@@ -121,7 +157,22 @@ func Test_Transfer(t *testing.T) {
 			init: func(t *_tc) p2p {
 				mock := NewMockp2p(ctrl)
 
-				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount).Return(nil)
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).Return(nil)
+
+				return mock
+			},
+		},
+		"with_memo": {
+			from:   "u1",
+			to:     "u2",
+			amount: 20,
+			memo:   " thanks for the review! ",
+			err:    nil,
+
+			init: func(t *_tc) p2p {
+				mock := NewMockp2p(ctrl)
+
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "thanks for the review!", &models.TransferLimits{Daily: 500}).Return(nil)
 
 				return mock
 			},
@@ -135,7 +186,21 @@ func Test_Transfer(t *testing.T) {
 			init: func(t *_tc) p2p {
 				mock := NewMockp2p(ctrl)
 
-				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount).Return(models.ErrNoRows)
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).Return(models.ErrNoRows)
+
+				return mock
+			},
+		},
+		"toUser_deactivated": {
+			from:   "u1",
+			to:     "u2",
+			amount: 20,
+			err:    models.ErrUserDeactivated,
+
+			init: func(t *_tc) p2p {
+				mock := NewMockp2p(ctrl)
+
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).Return(models.ErrUserDeactivated)
 
 				return mock
 			},
@@ -149,7 +214,22 @@ func Test_Transfer(t *testing.T) {
 			init: func(t *_tc) p2p {
 				mock := NewMockp2p(ctrl)
 
-				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount).Return(models.ErrNoMoney)
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).Return(models.ErrNoMoney)
+
+				return mock
+			},
+		},
+		"limit_exceeded": {
+			from:   "u1",
+			to:     "u2",
+			amount: 20,
+			err:    models.ErrLimitExceeded,
+
+			init: func(t *_tc) p2p {
+				mock := NewMockp2p(ctrl)
+
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).
+					Return(&models.LimitError{Limit: models.LimitDaily})
 
 				return mock
 			},
@@ -163,7 +243,7 @@ func Test_Transfer(t *testing.T) {
 			init: func(t *_tc) p2p {
 				mock := NewMockp2p(ctrl)
 
-				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount).Return(models.ErrGeneric)
+				mock.EXPECT().Transfer(ctx, t.from, t.to, t.amount, "", &models.TransferLimits{Daily: 500}).Return(models.ErrGeneric)
 
 				return mock
 			},
@@ -175,14 +255,86 @@ func Test_Transfer(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tc := tc
 
-			uc := NewAccountant(nil, tc.init(&tc), nil)
+			wishes := NewMockwishes(ctrl)
+			if tc.err == nil {
+				wishes.EXPECT().Changed(gomock.Any(), []string{tc.from, tc.to}, nil)
+			}
+			uc := NewAccountant(nil, tc.init(&tc), nil, wishes, models.TransferLimits{Daily: 500})
 
-			err := uc.Transfer(ctx, tc.from, tc.to, tc.amount)
+			err := uc.Transfer(ctx, tc.from, tc.to, tc.amount, tc.memo)
 			require.ErrorIs(t, err, tc.err)
 		})
 	}
 }
 
+func Test_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	p2pMock := NewMockp2p(ctrl)
+	shopMock := NewMockshop(ctrl)
+	uc := NewAccountant(nil, p2pMock, shopMock, nil, models.TransferLimits{})
+
+	at := time.Date(2025, 2, 1, 10, 0, 0, 123000, time.UTC)
+	first := []models.Transfer{{ID: 3, Date: at}, {ID: 2, Date: at}, {ID: 1, Date: at}}
+
+	// лишняя строка означает, что есть следующая страница
+	p2pMock.EXPECT().ListTransfers(ctx, "u1", &models.HistoryFilter{Counterparty: "u2", Limit: 3}, nil).Return(first, nil)
+	page, err := uc.Transfers(ctx, "u1", &models.HistoryFilter{Counterparty: "u2", Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, first[:2], page.Transfers)
+	require.NotEmpty(t, page.NextCursor)
+
+	p2pMock.EXPECT().ListTransfers(ctx, "u1", &models.HistoryFilter{Limit: 3, Cursor: page.NextCursor},
+		&models.HistoryKey{Date: at, ID: 2}).Return(first[2:], nil)
+	page, err = uc.Transfers(ctx, "u1", &models.HistoryFilter{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Equal(t, first[2:], page.Transfers)
+	require.Empty(t, page.NextCursor)
+
+	_, err = uc.Transfers(ctx, "u1", &models.HistoryFilter{Cursor: "!"})
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+	_, err = uc.Transfers(ctx, "u1", &models.HistoryFilter{Cursor: encodeCursor(at, 1)[1:]})
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+
+	shopMock.EXPECT().ListPurchaseHistory(ctx, "u1", &models.HistoryFilter{Limit: historyPageSize + 1}, nil).Return(nil, nil)
+	purchases, err := uc.Purchases(ctx, "u1", &models.HistoryFilter{})
+	require.NoError(t, err)
+	require.Equal(t, &models.PurchasePage{Purchases: []models.Purchase{}}, purchases)
+
+	shopMock.EXPECT().ListPurchaseHistory(ctx, "u1", gomock.Any(), nil).Return(nil, models.ErrNoRows)
+	_, err = uc.Purchases(ctx, "u1", &models.HistoryFilter{})
+	require.ErrorIs(t, err, models.ErrGeneric)
+}
+
+func Test_TransferLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockp2p(ctrl)
+	def := models.TransferLimits{MaxAmount: 100, Daily: 500}
+	uc := NewAccountant(nil, mock, nil, nil, def)
+
+	// переопределение применяется поверх настроек, в ответе действующие ограничения
+	daily := 0
+	override := &models.TransferLimitsOverride{Daily: &daily}
+	mock.EXPECT().SetTransferLimits(ctx, "u1", override).Return(nil)
+	mock.EXPECT().GetTransferLimits(ctx, "u1", &def).Return(&models.TransferLimits{MaxAmount: 100}, nil)
+	limits, err := uc.SetTransferLimits(ctx, "u1", override)
+	require.NoError(t, err)
+	require.Equal(t, &models.TransferLimits{MaxAmount: 100}, limits)
+
+	mock.EXPECT().SetTransferLimits(ctx, "u50", override).Return(models.ErrNoRows)
+	_, err = uc.SetTransferLimits(ctx, "u50", override)
+	require.ErrorIs(t, err, models.ErrNoRows)
+	require.NotErrorIs(t, err, models.ErrGeneric) // неизвестный логин - ошибка клиента
+
+	mock.EXPECT().ResetTransferLimits(ctx, "u1").Return(nil)
+	require.NoError(t, uc.ResetTransferLimits(ctx, "u1"))
+}
+
 func Test_Info(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -206,6 +358,9 @@ func Test_Info(t *testing.T) {
 					Received: []models.ReceivedTransfer{{From: "u2", Amount: 20}},
 					Sent:     nil,
 				},
+				Gifts: models.InfoResponseGifts{
+					Received: []models.ReceivedGift{{From: "u3", Item: "cup", Message: "thanks"}},
+				},
 			},
 			err: nil,
 
@@ -219,8 +374,10 @@ func Test_Info(t *testing.T) {
 
 				mockShop := NewMockshop(ctrl)
 				mockShop.EXPECT().ListPurchases(ctx, t.user).Return([]models.InventoryItem{0: {Type: "hoody", Qty: 12}}, nil)
+				mockShop.EXPECT().ListGiftsReceived(ctx, t.user).Return([]models.ReceivedGift{{From: "u3", Item: "cup", Message: "thanks"}}, nil)
+				mockShop.EXPECT().ListGiftsSent(ctx, t.user).Return(nil, nil)
 
-				ret := NewAccountant(mockBalance, mockP2P, mockShop)
+				ret := NewAccountant(mockBalance, mockP2P, mockShop, nil, models.TransferLimits{})
 				return ret
 			},
 		},
@@ -233,7 +390,7 @@ func Test_Info(t *testing.T) {
 				mockBalance := NewMockbalance(ctrl)
 				mockBalance.EXPECT().GetBalance(ctx, t.user).Return(0, models.ErrGeneric)
 
-				ret := NewAccountant(mockBalance, nil, nil)
+				ret := NewAccountant(mockBalance, nil, nil, nil, models.TransferLimits{})
 				return ret
 			},
 		},
@@ -249,7 +406,7 @@ func Test_Info(t *testing.T) {
 				mockShop := NewMockshop(ctrl)
 				mockShop.EXPECT().ListPurchases(ctx, t.user).Return(nil, models.ErrGeneric)
 
-				ret := NewAccountant(mockBalance, nil, mockShop)
+				ret := NewAccountant(mockBalance, nil, mockShop, nil, models.TransferLimits{})
 				return ret
 			},
 		},
@@ -268,7 +425,7 @@ func Test_Info(t *testing.T) {
 				mockShop := NewMockshop(ctrl)
 				mockShop.EXPECT().ListPurchases(ctx, t.user).Return([]models.InventoryItem{0: {Type: "hoody", Qty: 12}}, nil)
 
-				ret := NewAccountant(mockBalance, mockP2P, mockShop)
+				ret := NewAccountant(mockBalance, mockP2P, mockShop, nil, models.TransferLimits{})
 				return ret
 			},
 		},
@@ -288,7 +445,28 @@ func Test_Info(t *testing.T) {
 				mockShop := NewMockshop(ctrl)
 				mockShop.EXPECT().ListPurchases(ctx, t.user).Return([]models.InventoryItem{0: {Type: "hoody", Qty: 12}}, nil)
 
-				ret := NewAccountant(mockBalance, mockP2P, mockShop)
+				ret := NewAccountant(mockBalance, mockP2P, mockShop, nil, models.TransferLimits{})
+				return ret
+			},
+		},
+		"List_Gifts_Error": {
+			user: "u1",
+			resp: nil,
+			err:  models.ErrGeneric,
+
+			init: func(t *_tc) *accountant {
+				mockBalance := NewMockbalance(ctrl)
+				mockBalance.EXPECT().GetBalance(ctx, t.user).Return(20, nil)
+
+				mockP2P := NewMockp2p(ctrl)
+				mockP2P.EXPECT().ListReceived(ctx, t.user).Return(nil, nil)
+				mockP2P.EXPECT().ListSent(ctx, t.user).Return(nil, nil)
+
+				mockShop := NewMockshop(ctrl)
+				mockShop.EXPECT().ListPurchases(ctx, t.user).Return(nil, nil)
+				mockShop.EXPECT().ListGiftsReceived(ctx, t.user).Return(nil, models.ErrGeneric)
+
+				ret := NewAccountant(mockBalance, mockP2P, mockShop, nil, models.TransferLimits{})
 				return ret
 			},
 		},
@@ -4,57 +4,563 @@ package usecase
 
 import (
 	"context"
-	"crypto/sha512"
 	"errors"
 	"slices"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/cxbelka/winter_2025/internal/logger"
 	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/password"
 	"github.com/cxbelka/winter_2025/internal/token"
 )
 
 type auth struct {
-	repo authRepo
+	repo     authRepo
+	refresh  refreshRepo
+	denylist denylistRepo
+	hasher   PasswordHasher
+	throttle loginThrottle
+	policy   CredentialsPolicy
+	now      func() time.Time // подменяется в тестах для проверки TOTP
+}
+
+// CredentialsPolicy: without Explicit unknown users are created on the first /api/auth
+// as before, otherwise only through Register. Password policy applies to every
+// password chosen by user: registration, change and reset.
+type CredentialsPolicy struct {
+	Explicit   bool
+	InviteOnly bool
+	AllowList  bool
+	Password   password.Policy
+	ResetTTL   time.Duration // время жизни кода сброса пароля
 }
 
 type authRepo interface {
-	CheckLogin(ctx context.Context, login string) ([]byte, error)
-	CreateUser(ctx context.Context, login string, pass string) error
+	CheckLogin(ctx context.Context, login string) (string, error)
+	RegisterUser(ctx context.Context, reg *models.Registration) error
+	UpdatePassword(ctx context.Context, login string, passHash string) error
+	ListRoles(ctx context.Context, login string) ([]string, error)
+	GrantRole(ctx context.Context, login string, role string) error
+	RevokeRole(ctx context.Context, login string, role string) error
+	Deactivate(ctx context.Context, login string) error
+	Reactivate(ctx context.Context, login string) error
+	SaveReset(ctx context.Context, login string, hash string, ttl time.Duration) (time.Time, error)
+	GetReset(ctx context.Context, hash string) (string, error)
+	ConsumeReset(ctx context.Context, hash string, passHash string) (string, error)
+	GetTOTP(ctx context.Context, login string) (*models.TOTP, error)
+	SaveTOTP(ctx context.Context, login string, secret string) error
+	ConfirmTOTP(ctx context.Context, login string, secret string, step int64, recovery []string) error
+	UseTOTPStep(ctx context.Context, login string, step int64) error
+	UseRecoveryCode(ctx context.Context, login string, hash string) error
+	DeleteTOTP(ctx context.Context, login string) error
+}
+
+type refreshRepo interface {
+	SaveRefresh(ctx context.Context, login string, family string, hash string, ttl time.Duration) error
+	RotateRefresh(ctx context.Context, oldHash string, newHash string, ttl time.Duration) (string, error)
+	GetRefresh(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeRefresh(ctx context.Context, login string) error
+	RevokeRefreshFamily(ctx context.Context, login string, hash string) error
+}
+
+type denylistRepo interface {
+	RevokeToken(ctx context.Context, rt models.RevokedToken) error
+	RevokeSessions(ctx context.Context, rs models.RevokedSession) error
+	ListRevoked(ctx context.Context) ([]models.RevokedToken, []models.RevokedSession, error)
 }
 
-func NewAuth(repo authRepo) *auth { //nolint:revive
-	return &auth{repo: repo}
+type loginThrottle interface {
+	Check(ctx context.Context, login string, ip string) error
+	Fail(ctx context.Context, login string, ip string)
+	Reset(ctx context.Context, login string)
+}
+
+// PasswordHasher produces self-describing hashes (algorithm, params, salt)
+// and verifies any hash it knows how to read.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password string, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+func NewAuth( //nolint:revive
+	repo authRepo, refresh refreshRepo, denylist denylistRepo, hasher PasswordHasher, throttle loginThrottle,
+	policy CredentialsPolicy,
+) *auth {
+	return &auth{
+		repo: repo, refresh: refresh, denylist: denylist, hasher: hasher, throttle: throttle, policy: policy,
+		now: time.Now,
+	}
 }
 
 func (a *auth) Authorize(ctx context.Context, rq *models.AuthReqest) (*models.AuthResponse, error) {
+	if err := a.throttle.Check(ctx, rq.Username, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
 	passHash, err := a.repo.CheckLogin(ctx, rq.Username)
-	if err != nil && !errors.Is(err, models.ErrNoRows) {
+	deactivated := errors.Is(err, models.ErrUserDeactivated)
+	if err != nil && !errors.Is(err, models.ErrNoRows) && !deactivated {
 		logger.AddError(ctx, err)
 
 		return nil, err //nolint:wrapcheck
 	}
 	if errors.Is(err, models.ErrNoRows) {
-		if err := a.repo.CreateUser(ctx, rq.Username, rq.Password); err != nil {
+		if a.policy.Explicit {
+			// неизвестный логин неотличим от неверного пароля
+			a.throttle.Fail(ctx, rq.Username, rq.ClientIP)
+			logger.AddError(ctx, models.ErrInvalidPassword)
+
+			return nil, models.ErrInvalidPassword
+		}
+		if err := a.createUser(ctx, rq); err != nil {
 			logger.AddError(ctx, err)
 
-			return nil, err //nolint:wrapcheck
+			return nil, err
 		}
 	} else {
-		hash := sha512.New()
-		hash.Write([]byte(rq.Password))
-
-		if !slices.Equal(passHash, hash.Sum(nil)) {
+		ok, err := a.hasher.Verify(rq.Password, passHash)
+		if err != nil {
+			// испорченный или служебный хеш ('!' у company-pool) не подходит ни к одному паролю
+			logger.AddError(ctx, err)
+			ok = false
+		}
+		if !ok {
+			a.throttle.Fail(ctx, rq.Username, rq.ClientIP)
 			logger.AddError(ctx, models.ErrInvalidPassword)
 
 			return nil, models.ErrInvalidPassword
 		}
+		// без пароля деактивированный аккаунт неотличим от активного
+		if deactivated {
+			logger.AddError(ctx, models.ErrUserDeactivated)
+
+			return nil, models.ErrUserDeactivated
+		}
+		if a.hasher.NeedsRehash(passHash) {
+			a.rehash(ctx, rq)
+		}
+		// блокировка снимается только после второго фактора, иначе вход
+		// по известному паролю сбрасывал бы счётчик подбора кода
+		if resp, err := a.mfaChallenge(ctx, rq.Username); resp != nil || err != nil {
+			return resp, err
+		}
+		a.throttle.Reset(ctx, rq.Username)
+	}
+
+	return a.issueTokens(ctx, rq.Username)
+}
+
+// Register creates user explicitly. Password policy, invite code and HR allow-list
+// are checked according to CredentialsPolicy.
+func (a *auth) Register(ctx context.Context, rq *models.RegisterRequest) (*models.AuthResponse, error) {
+	if err := a.throttle.Check(ctx, rq.Username, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	reg := &models.Registration{Login: rq.Username, CheckAllowList: a.policy.AllowList}
+	if a.policy.InviteOnly {
+		if rq.InviteCode == "" {
+			logger.AddError(ctx, models.ErrRegistrationDenied)
+
+			return nil, models.ErrRegistrationDenied
+		}
+		reg.InviteCode = rq.InviteCode
 	}
+
+	var err error
+	if reg.PassHash, err = a.newPasswordHash(rq.Username, rq.Password); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err
+	}
+	if err = a.repo.RegisterUser(ctx, reg); err != nil {
+		if errors.Is(err, models.ErrRegistrationDenied) {
+			// перебор кодов приглашения
+			a.throttle.Fail(ctx, rq.Username, rq.ClientIP)
+		}
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return a.issueTokens(ctx, rq.Username)
+}
+
+func (a *auth) issueTokens(ctx context.Context, login string) (*models.AuthResponse, error) {
+	var err error
 	resp := &models.AuthResponse{}
-	resp.Token, err = token.Create(rq.Username)
+	resp.Token, err = a.accessToken(ctx, login)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(err, models.ErrGeneric)
+	}
+	// новая цепочка refresh токенов на каждый вход по паролю
+	if resp.RefreshToken, err = a.issueRefresh(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Refresh exchanges refresh token for a new access/refresh pair. The presented
+// token can be used only once; a second attempt revokes all user's refresh tokens.
+func (a *auth) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	raw, hash, err := token.CreateRefresh()
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+
+	oldHash := token.HashRefresh(refreshToken)
+	login, err := a.refresh.RotateRefresh(ctx, oldHash, hash, token.RefreshTTL())
+	if errors.Is(err, models.ErrNoRows) {
+		a.detectReuse(ctx, oldHash)
+		logger.AddError(ctx, models.ErrInvalidToken)
+
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	logger.AddField(ctx, "login", login)
+
+	resp := &models.AuthResponse{RefreshToken: raw}
+	resp.Token, err = a.accessToken(ctx, login)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(err, models.ErrGeneric)
+	}
+
+	return resp, nil
+}
+
+// accessToken reads current roles on every issue, so granted role is
+// available after the next refresh.
+func (a *auth) accessToken(ctx context.Context, login string) (string, error) {
+	roles, err := a.repo.ListRoles(ctx, login)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return token.Create(login, roles...) //nolint:wrapcheck
+}
+
+func (a *auth) issueRefresh(ctx context.Context, login string) (string, error) {
+	raw, hash, err := token.CreateRefresh()
+	if err != nil {
+		return "", errors.Join(models.ErrGeneric, err)
+	}
+	if err := a.refresh.SaveRefresh(ctx, login, uuid.NewString(), hash, token.RefreshTTL()); err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return raw, nil
+}
+
+// detectReuse revokes every refresh token of the owner if already rotated
+// or revoked token is replayed. Unknown and expired tokens are just rejected.
+func (a *auth) detectReuse(ctx context.Context, hash string) {
+	rt, err := a.refresh.GetRefresh(ctx, hash)
+	if err != nil {
+		return
+	}
+	if !rt.Used && !rt.Revoked {
+		return
+	}
+
+	logger.AddField(ctx, "refresh_reuse", rt.Login)
+	logger.AddField(ctx, "refresh_family", rt.Family)
+	if err := a.refresh.RevokeRefresh(ctx, rt.Login); err != nil {
+		logger.AddField(ctx, "revoke_error", err.Error())
+	}
+}
+
+// createUser is the legacy auto-registration on the first login.
+// Only the HR allow-list applies, an unlisted user gets 401.
+func (a *auth) createUser(ctx context.Context, rq *models.AuthReqest) error {
+	hash, err := a.hasher.Hash(rq.Password)
+	if err != nil {
+		return errors.Join(models.ErrGeneric, err)
+	}
+
+	err = a.repo.RegisterUser(ctx, &models.Registration{Login: rq.Username, PassHash: hash, CheckAllowList: a.policy.AllowList})
+	if errors.Is(err, models.ErrRegistrationDenied) {
+		return models.ErrInvalidPassword
+	}
+
+	return err //nolint:wrapcheck
+}
+
+// rehash upgrades legacy or outdated hash after successful login.
+// Failure is not fatal: the old hash is still valid and will be upgraded next time.
+func (a *auth) rehash(ctx context.Context, rq *models.AuthReqest) {
+	hash, err := a.hasher.Hash(rq.Password)
+	if err == nil {
+		err = a.repo.UpdatePassword(ctx, rq.Username, hash)
+	}
+	if err != nil {
+		logger.AddField(ctx, "rehash_error", err.Error())
+	}
+}
+
+// Logout revokes access token from the context and, if given, the refresh token chain.
+// With rq.All every session of the user is terminated.
+func (a *auth) Logout(ctx context.Context, rq *models.LogoutRequest) error {
+	claims := token.ClaimsFromContext(ctx)
+	if claims == nil || claims.ExpiresAt == nil {
+		return models.ErrInvalidToken
+	}
+	if rq.All {
+		return a.RevokeSessions(ctx, claims.Subject)
+	}
+
+	rt := models.RevokedToken{ID: claims.ID, Login: claims.Subject, ExpiresAt: claims.ExpiresAt.Time}
+	if err := a.denylist.RevokeToken(ctx, rt); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	onCommit(ctx, func() { token.Revoke(rt.ID, rt.ExpiresAt) })
+
+	if rq.RefreshToken != "" {
+		if err := a.refresh.RevokeRefreshFamily(ctx, claims.Subject, token.HashRefresh(rq.RefreshToken)); err != nil {
+			logger.AddError(ctx, err)
+
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return nil
+}
+
+// RevokeSessions invalidates all access and refresh tokens issued to the user so far.
+func (a *auth) RevokeSessions(ctx context.Context, login string) error {
+	now := a.now().Truncate(time.Microsecond) // точность iat_us и timestamp в БД
+	rs := models.RevokedSession{Login: login, RevokedAt: now, ExpiresAt: now.Add(token.TTL())}
+	if err := a.denylist.RevokeSessions(ctx, rs); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	// local denylist changes only once the revocation is committed
+	onCommit(ctx, func() { token.RevokeSubject(rs) })
+
+	if err := a.refresh.RevokeRefresh(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// SyncRevoked loads revocations made by all service instances into the local denylist.
+func (a *auth) SyncRevoked(ctx context.Context) error {
+	tokens, sessions, err := a.denylist.ListRevoked(ctx)
 	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	token.Load(tokens, sessions)
+
+	return nil
+}
+
+func (a *auth) GrantRole(ctx context.Context, login string, role string) error {
+	if !slices.Contains(models.Roles, role) {
+		return models.ErrUnknownRole
+	}
+	if err := a.repo.GrantRole(ctx, login, role); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// RevokeRole also terminates user sessions: tokens issued earlier still carry the role.
+func (a *auth) RevokeRole(ctx context.Context, login string, role string) error {
+	if !slices.Contains(models.Roles, role) {
+		return models.ErrUnknownRole
+	}
+	if err := a.repo.RevokeRole(ctx, login, role); err != nil {
 		logger.AddError(ctx, err)
-		err = errors.Join(err, models.ErrGeneric)
+
+		return err //nolint:wrapcheck
+	}
+
+	return a.RevokeSessions(ctx, login)
+}
+
+// BootstrapAdmin creates the first administrator from config if there is no such user
+// and grants the admin role. Password of an existing user is not changed.
+func (a *auth) BootstrapAdmin(ctx context.Context, login string, pass string) error {
+	_, err := a.repo.CheckLogin(ctx, login)
+	switch {
+	case errors.Is(err, models.ErrNoRows):
+		hash, err := a.newPasswordHash(login, pass)
+		if err != nil {
+			return err
+		}
+		err = a.repo.RegisterUser(ctx, &models.Registration{Login: login, PassHash: hash})
+		if err != nil && !errors.Is(err, models.ErrUserExists) { // другой инстанс успел раньше
+			return err //nolint:wrapcheck
+		}
+	case err != nil && !errors.Is(err, models.ErrUserDeactivated):
+		return err //nolint:wrapcheck
+	}
+
+	return a.repo.GrantRole(ctx, login, models.RoleAdmin) //nolint:wrapcheck
+}
+
+// Deactivate blocks login and transfers, remaining coins go to the company pool.
+// Already issued tokens are revoked.
+func (a *auth) Deactivate(ctx context.Context, login string) error {
+	if login == models.CompanyPool {
+		return models.ErrForbidden
+	}
+	if err := a.repo.Deactivate(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return a.RevokeSessions(ctx, login)
+}
+
+func (a *auth) Reactivate(ctx context.Context, login string) error {
+	if err := a.repo.Reactivate(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// ChangePassword requires the current password. All sessions including the current
+// one are terminated, the client has to log in again.
+func (a *auth) ChangePassword(ctx context.Context, rq *models.ChangePasswordRequest) error {
+	claims := token.ClaimsFromContext(ctx)
+	if claims == nil {
+		return models.ErrInvalidToken
+	}
+	login := claims.Subject
+
+	if err := a.throttle.Check(ctx, login, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	passHash, err := a.repo.CheckLogin(ctx, login)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	ok, err := a.hasher.Verify(rq.CurrentPassword, passHash)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return errors.Join(models.ErrGeneric, err)
+	}
+	if !ok {
+		a.throttle.Fail(ctx, login, rq.ClientIP)
+		logger.AddError(ctx, models.ErrInvalidPassword)
+
+		return models.ErrInvalidPassword
+	}
+
+	newHash, err := a.newPasswordHash(login, rq.NewPassword)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return err
+	}
+	if err = a.repo.UpdatePassword(ctx, login, newHash); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return a.RevokeSessions(ctx, login)
+}
+
+// IssueReset creates one-time password reset code for the user. Only the latest code is valid.
+func (a *auth) IssueReset(ctx context.Context, login string) (*models.PasswordResetResponse, error) {
+	raw, hash, err := token.CreateResetCode()
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	expires, err := a.repo.SaveReset(ctx, login, hash, a.policy.ResetTTL)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return &models.PasswordResetResponse{Code: raw, ExpiresAt: expires}, nil
+}
+
+// ResetPassword sets new password by reset code, terminates sessions and lifts login lockout.
+func (a *auth) ResetPassword(ctx context.Context, rq *models.ResetPasswordRequest) error {
+	hash := token.HashResetCode(rq.Code)
+	login, err := a.repo.GetReset(ctx, hash)
+	if errors.Is(err, models.ErrNoRows) {
+		logger.AddError(ctx, models.ErrInvalidToken)
+
+		return models.ErrInvalidToken
+	}
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	logger.AddField(ctx, "login", login)
+
+	newHash, err := a.newPasswordHash(login, rq.NewPassword)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return err
+	}
+	// код мог быть использован параллельно, поэтому проверяется повторно при записи
+	if _, err = a.repo.ConsumeReset(ctx, hash, newHash); err != nil {
+		if errors.Is(err, models.ErrNoRows) {
+			err = models.ErrInvalidToken
+		}
+		logger.AddError(ctx, err)
+
+		return err
+	}
+	a.throttle.Reset(ctx, login)
+
+	return a.RevokeSessions(ctx, login)
+}
+
+func (a *auth) newPasswordHash(login string, pass string) (string, error) {
+	if err := a.policy.Password.Validate(login, pass); err != nil {
+		return "", errors.Join(models.ErrWeakPassword, err)
+	}
+	hash, err := a.hasher.Hash(pass)
+	if err != nil {
+		return "", errors.Join(models.ErrGeneric, err)
 	}
 
-	return resp, err
+	return hash, nil
 }
@@ -12,7 +12,9 @@ package usecase
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	models "github.com/cxbelka/winter_2025/internal/models"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,10 +43,10 @@ func (m *MockauthRepo) EXPECT() *MockauthRepoMockRecorder {
 }
 
 // CheckLogin mocks base method.
-func (m *MockauthRepo) CheckLogin(ctx context.Context, login string) ([]byte, error) {
+func (m *MockauthRepo) CheckLogin(ctx context.Context, login string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CheckLogin", ctx, login)
-	ret0, _ := ret[0].([]byte)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -55,16 +57,525 @@ func (mr *MockauthRepoMockRecorder) CheckLogin(ctx, login any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLogin", reflect.TypeOf((*MockauthRepo)(nil).CheckLogin), ctx, login)
 }
 
-// CreateUser mocks base method.
-func (m *MockauthRepo) CreateUser(ctx context.Context, login, pass string) error {
+// ConfirmTOTP mocks base method.
+func (m *MockauthRepo) ConfirmTOTP(ctx context.Context, login, secret string, step int64, recovery []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, login, pass)
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, login, secret, step, recovery)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockauthRepoMockRecorder) CreateUser(ctx, login, pass any) *gomock.Call {
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockauthRepoMockRecorder) ConfirmTOTP(ctx, login, secret, step, recovery any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockauthRepo)(nil).CreateUser), ctx, login, pass)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockauthRepo)(nil).ConfirmTOTP), ctx, login, secret, step, recovery)
+}
+
+// ConsumeReset mocks base method.
+func (m *MockauthRepo) ConsumeReset(ctx context.Context, hash, passHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeReset", ctx, hash, passHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeReset indicates an expected call of ConsumeReset.
+func (mr *MockauthRepoMockRecorder) ConsumeReset(ctx, hash, passHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeReset", reflect.TypeOf((*MockauthRepo)(nil).ConsumeReset), ctx, hash, passHash)
+}
+
+// Deactivate mocks base method.
+func (m *MockauthRepo) Deactivate(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockauthRepoMockRecorder) Deactivate(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockauthRepo)(nil).Deactivate), ctx, login)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockauthRepo) DeleteTOTP(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockauthRepoMockRecorder) DeleteTOTP(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockauthRepo)(nil).DeleteTOTP), ctx, login)
+}
+
+// GetReset mocks base method.
+func (m *MockauthRepo) GetReset(ctx context.Context, hash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReset", ctx, hash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReset indicates an expected call of GetReset.
+func (mr *MockauthRepoMockRecorder) GetReset(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReset", reflect.TypeOf((*MockauthRepo)(nil).GetReset), ctx, hash)
+}
+
+// GetTOTP mocks base method.
+func (m *MockauthRepo) GetTOTP(ctx context.Context, login string) (*models.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, login)
+	ret0, _ := ret[0].(*models.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockauthRepoMockRecorder) GetTOTP(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockauthRepo)(nil).GetTOTP), ctx, login)
+}
+
+// GrantRole mocks base method.
+func (m *MockauthRepo) GrantRole(ctx context.Context, login, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockauthRepoMockRecorder) GrantRole(ctx, login, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockauthRepo)(nil).GrantRole), ctx, login, role)
+}
+
+// ListRoles mocks base method.
+func (m *MockauthRepo) ListRoles(ctx context.Context, login string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx, login)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockauthRepoMockRecorder) ListRoles(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockauthRepo)(nil).ListRoles), ctx, login)
+}
+
+// Reactivate mocks base method.
+func (m *MockauthRepo) Reactivate(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockauthRepoMockRecorder) Reactivate(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockauthRepo)(nil).Reactivate), ctx, login)
+}
+
+// RegisterUser mocks base method.
+func (m *MockauthRepo) RegisterUser(ctx context.Context, reg *models.Registration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterUser", ctx, reg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterUser indicates an expected call of RegisterUser.
+func (mr *MockauthRepoMockRecorder) RegisterUser(ctx, reg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockauthRepo)(nil).RegisterUser), ctx, reg)
+}
+
+// RevokeRole mocks base method.
+func (m *MockauthRepo) RevokeRole(ctx context.Context, login, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockauthRepoMockRecorder) RevokeRole(ctx, login, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockauthRepo)(nil).RevokeRole), ctx, login, role)
+}
+
+// SaveReset mocks base method.
+func (m *MockauthRepo) SaveReset(ctx context.Context, login, hash string, ttl time.Duration) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReset", ctx, login, hash, ttl)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveReset indicates an expected call of SaveReset.
+func (mr *MockauthRepoMockRecorder) SaveReset(ctx, login, hash, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReset", reflect.TypeOf((*MockauthRepo)(nil).SaveReset), ctx, login, hash, ttl)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockauthRepo) SaveTOTP(ctx context.Context, login, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", ctx, login, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockauthRepoMockRecorder) SaveTOTP(ctx, login, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockauthRepo)(nil).SaveTOTP), ctx, login, secret)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockauthRepo) UpdatePassword(ctx context.Context, login, passHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, login, passHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockauthRepoMockRecorder) UpdatePassword(ctx, login, passHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockauthRepo)(nil).UpdatePassword), ctx, login, passHash)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockauthRepo) UseRecoveryCode(ctx context.Context, login, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, login, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockauthRepoMockRecorder) UseRecoveryCode(ctx, login, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockauthRepo)(nil).UseRecoveryCode), ctx, login, hash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockauthRepo) UseTOTPStep(ctx context.Context, login string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, login, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockauthRepoMockRecorder) UseTOTPStep(ctx, login, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockauthRepo)(nil).UseTOTPStep), ctx, login, step)
+}
+
+// MockrefreshRepo is a mock of refreshRepo interface.
+type MockrefreshRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockrefreshRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockrefreshRepoMockRecorder is the mock recorder for MockrefreshRepo.
+type MockrefreshRepoMockRecorder struct {
+	mock *MockrefreshRepo
+}
+
+// NewMockrefreshRepo creates a new mock instance.
+func NewMockrefreshRepo(ctrl *gomock.Controller) *MockrefreshRepo {
+	mock := &MockrefreshRepo{ctrl: ctrl}
+	mock.recorder = &MockrefreshRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrefreshRepo) EXPECT() *MockrefreshRepoMockRecorder {
+	return m.recorder
+}
+
+// GetRefresh mocks base method.
+func (m *MockrefreshRepo) GetRefresh(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefresh", ctx, hash)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefresh indicates an expected call of GetRefresh.
+func (mr *MockrefreshRepoMockRecorder) GetRefresh(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefresh", reflect.TypeOf((*MockrefreshRepo)(nil).GetRefresh), ctx, hash)
+}
+
+// RevokeRefresh mocks base method.
+func (m *MockrefreshRepo) RevokeRefresh(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefresh", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefresh indicates an expected call of RevokeRefresh.
+func (mr *MockrefreshRepoMockRecorder) RevokeRefresh(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefresh", reflect.TypeOf((*MockrefreshRepo)(nil).RevokeRefresh), ctx, login)
+}
+
+// RevokeRefreshFamily mocks base method.
+func (m *MockrefreshRepo) RevokeRefreshFamily(ctx context.Context, login, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshFamily", ctx, login, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshFamily indicates an expected call of RevokeRefreshFamily.
+func (mr *MockrefreshRepoMockRecorder) RevokeRefreshFamily(ctx, login, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshFamily", reflect.TypeOf((*MockrefreshRepo)(nil).RevokeRefreshFamily), ctx, login, hash)
+}
+
+// RotateRefresh mocks base method.
+func (m *MockrefreshRepo) RotateRefresh(ctx context.Context, oldHash, newHash string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefresh", ctx, oldHash, newHash, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefresh indicates an expected call of RotateRefresh.
+func (mr *MockrefreshRepoMockRecorder) RotateRefresh(ctx, oldHash, newHash, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefresh", reflect.TypeOf((*MockrefreshRepo)(nil).RotateRefresh), ctx, oldHash, newHash, ttl)
+}
+
+// SaveRefresh mocks base method.
+func (m *MockrefreshRepo) SaveRefresh(ctx context.Context, login, family, hash string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefresh", ctx, login, family, hash, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefresh indicates an expected call of SaveRefresh.
+func (mr *MockrefreshRepoMockRecorder) SaveRefresh(ctx, login, family, hash, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefresh", reflect.TypeOf((*MockrefreshRepo)(nil).SaveRefresh), ctx, login, family, hash, ttl)
+}
+
+// MockdenylistRepo is a mock of denylistRepo interface.
+type MockdenylistRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockdenylistRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockdenylistRepoMockRecorder is the mock recorder for MockdenylistRepo.
+type MockdenylistRepoMockRecorder struct {
+	mock *MockdenylistRepo
+}
+
+// NewMockdenylistRepo creates a new mock instance.
+func NewMockdenylistRepo(ctrl *gomock.Controller) *MockdenylistRepo {
+	mock := &MockdenylistRepo{ctrl: ctrl}
+	mock.recorder = &MockdenylistRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdenylistRepo) EXPECT() *MockdenylistRepoMockRecorder {
+	return m.recorder
+}
+
+// ListRevoked mocks base method.
+func (m *MockdenylistRepo) ListRevoked(ctx context.Context) ([]models.RevokedToken, []models.RevokedSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevoked", ctx)
+	ret0, _ := ret[0].([]models.RevokedToken)
+	ret1, _ := ret[1].([]models.RevokedSession)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListRevoked indicates an expected call of ListRevoked.
+func (mr *MockdenylistRepoMockRecorder) ListRevoked(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevoked", reflect.TypeOf((*MockdenylistRepo)(nil).ListRevoked), ctx)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockdenylistRepo) RevokeSessions(ctx context.Context, rs models.RevokedSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, rs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockdenylistRepoMockRecorder) RevokeSessions(ctx, rs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockdenylistRepo)(nil).RevokeSessions), ctx, rs)
+}
+
+// RevokeToken mocks base method.
+func (m *MockdenylistRepo) RevokeToken(ctx context.Context, rt models.RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, rt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockdenylistRepoMockRecorder) RevokeToken(ctx, rt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockdenylistRepo)(nil).RevokeToken), ctx, rt)
+}
+
+// MockloginThrottle is a mock of loginThrottle interface.
+type MockloginThrottle struct {
+	ctrl     *gomock.Controller
+	recorder *MockloginThrottleMockRecorder
+	isgomock struct{}
+}
+
+// MockloginThrottleMockRecorder is the mock recorder for MockloginThrottle.
+type MockloginThrottleMockRecorder struct {
+	mock *MockloginThrottle
+}
+
+// NewMockloginThrottle creates a new mock instance.
+func NewMockloginThrottle(ctrl *gomock.Controller) *MockloginThrottle {
+	mock := &MockloginThrottle{ctrl: ctrl}
+	mock.recorder = &MockloginThrottleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockloginThrottle) EXPECT() *MockloginThrottleMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockloginThrottle) Check(ctx context.Context, login, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, login, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockloginThrottleMockRecorder) Check(ctx, login, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockloginThrottle)(nil).Check), ctx, login, ip)
+}
+
+// Fail mocks base method.
+func (m *MockloginThrottle) Fail(ctx context.Context, login, ip string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Fail", ctx, login, ip)
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockloginThrottleMockRecorder) Fail(ctx, login, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockloginThrottle)(nil).Fail), ctx, login, ip)
+}
+
+// Reset mocks base method.
+func (m *MockloginThrottle) Reset(ctx context.Context, login string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset", ctx, login)
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockloginThrottleMockRecorder) Reset(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockloginThrottle)(nil).Reset), ctx, login)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface.
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+	isgomock struct{}
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher.
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance.
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method.
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockPasswordHasherMockRecorder) Hash(password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// NeedsRehash mocks base method.
+func (m *MockPasswordHasher) NeedsRehash(encoded string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", encoded)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockPasswordHasherMockRecorder) NeedsRehash(encoded any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHasher)(nil).NeedsRehash), encoded)
+}
+
+// Verify mocks base method.
+func (m *MockPasswordHasher) Verify(password, encoded string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", password, encoded)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockPasswordHasherMockRecorder) Verify(password, encoded any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockPasswordHasher)(nil).Verify), password, encoded)
 }
@@ -2,12 +2,14 @@ package usecase
 
 import (
 	"context"
-	"crypto/sha512"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/password"
 	"github.com/cxbelka/winter_2025/internal/token"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -21,80 +23,230 @@ func Test_Auth(t *testing.T) {
 		rq   *models.AuthReqest
 		resp *models.AuthResponse
 		err  error
-		init func(*_tc) authRepo
+		init func(*_tc) *auth
 	}
 	testCases := map[string]_tc{
 		"user_exist": {
 			rq:   &models.AuthReqest{Username: "u1", Password: "p1"},
 			resp: &models.AuthResponse{},
 			err:  nil,
-			init: func(t *_tc) authRepo {
-				t.resp.Token, _ = token.Create(t.rq.Username)
-
-				hash := sha512.New()
-				hash.Write([]byte(t.rq.Password))
-
+			init: func(t *_tc) *auth {
 				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
 
-				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return(hash.Sum(nil), nil)
-				return mock
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$argon2id$h1", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "$argon2id$h1").Return(true, nil)
+				hasher.EXPECT().NeedsRehash("$argon2id$h1").Return(false)
+				mock.EXPECT().GetTOTP(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
 			},
 		},
 		"user_not_exist": {
 			rq:   &models.AuthReqest{Username: "u10", Password: "p10"},
 			resp: &models.AuthResponse{},
 			err:  nil,
-			init: func(t *_tc) authRepo {
-				t.resp.Token, _ = token.Create(t.rq.Username)
-
+			init: func(t *_tc) *auth {
 				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
 
-				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("", models.ErrNoRows)
 
-				mock.EXPECT().CreateUser(ctx, t.rq.Username, t.rq.Password).Return(nil)
-				return mock
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h10", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{Login: t.rq.Username, PassHash: "$argon2id$h10"}).Return(nil)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
 			},
 		},
 		"bad_password": {
-			rq:   &models.AuthReqest{Username: "u20", Password: "NOT_p20"},
+			rq:   &models.AuthReqest{Username: "u20", Password: "NOT_p20", ClientIP: "10.0.0.1"},
 			resp: nil,
 			err:  models.ErrInvalidPassword,
-			init: func(t *_tc) authRepo {
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
 
-				hash := sha512.New()
-				hash.Write([]byte("p20"))
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$argon2id$h20", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "$argon2id$h20").Return(false, nil)
 
+				throttle := NewMockloginThrottle(ctrl)
+				throttle.EXPECT().Check(ctx, t.rq.Username, "10.0.0.1").Return(nil)
+				throttle.EXPECT().Fail(ctx, t.rq.Username, "10.0.0.1")
+				return NewAuth(mock, nil, nil, hasher, throttle, CredentialsPolicy{})
+			},
+		},
+		"locked": { // пароль даже не проверяется, пока действует блокировка
+			rq:   &models.AuthReqest{Username: "u20", Password: "p20", ClientIP: "10.0.0.1"},
+			resp: nil,
+			err:  models.ErrTooManyAttempts,
+			init: func(t *_tc) *auth {
+				throttle := NewMockloginThrottle(ctrl)
+				throttle.EXPECT().Check(ctx, t.rq.Username, "10.0.0.1").
+					Return(&models.RetryAfterError{Err: models.ErrTooManyAttempts, After: time.Minute})
+				return NewAuth(nil, nil, nil, nil, throttle, CredentialsPolicy{})
+			},
+		},
+		"explicit_unknown_user": { // при явной регистрации опечатка в логине не создаёт аккаунт
+			rq:   &models.AuthReqest{Username: "u11", Password: "p11"},
+			resp: nil,
+			err:  models.ErrInvalidPassword,
+			init: func(t *_tc) *auth {
 				mock := NewMockauthRepo(ctrl)
 
-				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return(hash.Sum(nil), nil)
-				return mock
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("", models.ErrNoRows)
+				return NewAuth(mock, nil, nil, nil, permissiveThrottle(ctrl), CredentialsPolicy{Explicit: true})
+			},
+		},
+		"not_in_allow_list": {
+			rq:   &models.AuthReqest{Username: "u12", Password: "p12"},
+			resp: nil,
+			err:  models.ErrInvalidPassword,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("", models.ErrNoRows)
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h12", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{Login: t.rq.Username, PassHash: "$argon2id$h12", CheckAllowList: true}).
+					Return(models.ErrRegistrationDenied)
+				return NewAuth(mock, nil, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{AllowList: true})
+			},
+		},
+		"deactivated": {
+			rq:   &models.AuthReqest{Username: "u13", Password: "p13"},
+			resp: nil,
+			err:  models.ErrUserDeactivated,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$argon2id$h13", models.ErrUserDeactivated)
+				hasher.EXPECT().Verify(t.rq.Password, "$argon2id$h13").Return(true, nil)
+				return NewAuth(mock, nil, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
+			},
+		},
+		"deactivated_bad_password": { // без пароля деактивация не раскрывается
+			rq:   &models.AuthReqest{Username: "u13", Password: "NOT_p13", ClientIP: "10.0.0.1"},
+			resp: nil,
+			err:  models.ErrInvalidPassword,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$argon2id$h13", models.ErrUserDeactivated)
+				hasher.EXPECT().Verify(t.rq.Password, "$argon2id$h13").Return(false, nil)
+
+				throttle := NewMockloginThrottle(ctrl)
+				throttle.EXPECT().Check(ctx, t.rq.Username, "10.0.0.1").Return(nil)
+				throttle.EXPECT().Fail(ctx, t.rq.Username, "10.0.0.1")
+				return NewAuth(mock, nil, nil, hasher, throttle, CredentialsPolicy{})
 			},
 		},
 		"db_issue": {
 			rq:   &models.AuthReqest{Username: "u20", Password: "p20"},
 			resp: nil,
 			err:  errors.New("fake error"),
-			init: func(t *_tc) authRepo {
-
+			init: func(t *_tc) *auth {
 				mock := NewMockauthRepo(ctrl)
 
 				// Ожидаем что репо возвращает ошибку в обертке (models.Err.....)
-				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return(nil, t.err)
-				return mock
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("", t.err)
+				return NewAuth(mock, nil, nil, nil, permissiveThrottle(ctrl), CredentialsPolicy{})
 			},
 		},
 		"create_issue": {
 			rq:   &models.AuthReqest{Username: "u20", Password: "p20"},
 			resp: nil,
 			err:  errors.New("fake error"),
-			init: func(t *_tc) authRepo {
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("", models.ErrNoRows)
+
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h20", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{Login: t.rq.Username, PassHash: "$argon2id$h20"}).Return(t.err)
+				return NewAuth(mock, nil, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
+			},
+		},
+		"legacy_rehash": {
+			rq:   &models.AuthReqest{Username: "u30", Password: "p30"},
+			resp: &models.AuthResponse{},
+			err:  nil,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$sha512$h30", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "$sha512$h30").Return(true, nil)
+				hasher.EXPECT().NeedsRehash("$sha512$h30").Return(true)
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h30", nil)
+				mock.EXPECT().UpdatePassword(ctx, t.rq.Username, "$argon2id$h30").Return(nil)
+				mock.EXPECT().GetTOTP(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
+			},
+		},
+		"rehash_issue": { // логин проходит, апгрейд хеша повторится при следующем входе
+			rq:   &models.AuthReqest{Username: "u30", Password: "p30"},
+			resp: &models.AuthResponse{},
+			err:  nil,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
 
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$sha512$h30", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "$sha512$h30").Return(true, nil)
+				hasher.EXPECT().NeedsRehash("$sha512$h30").Return(true)
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h30", nil)
+				mock.EXPECT().UpdatePassword(ctx, t.rq.Username, "$argon2id$h30").Return(errors.New("fake error"))
+				mock.EXPECT().GetTOTP(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
+			},
+		},
+		"malformed_hash": { // считается неверным паролем и учитывается при подборе
+			rq:   &models.AuthReqest{Username: "u40", Password: "p40", ClientIP: "10.0.0.1"},
+			resp: nil,
+			err:  models.ErrInvalidPassword,
+			init: func(t *_tc) *auth {
 				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
 
-				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("garbage", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "garbage").Return(false, errors.New("malformed"))
 
-				mock.EXPECT().CreateUser(ctx, t.rq.Username, t.rq.Password).Return(t.err)
-				return mock
+				throttle := NewMockloginThrottle(ctrl)
+				throttle.EXPECT().Check(ctx, t.rq.Username, "10.0.0.1").Return(nil)
+				throttle.EXPECT().Fail(ctx, t.rq.Username, "10.0.0.1")
+				return NewAuth(mock, nil, nil, hasher, throttle, CredentialsPolicy{})
+			},
+		},
+		"refresh_save_issue": {
+			rq:   &models.AuthReqest{Username: "u1", Password: "p1"},
+			resp: nil,
+			err:  models.ErrGeneric,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.rq.Username).Return("$argon2id$h1", nil)
+				hasher.EXPECT().Verify(t.rq.Password, "$argon2id$h1").Return(true, nil)
+				hasher.EXPECT().NeedsRehash("$argon2id$h1").Return(false)
+				mock.EXPECT().GetTOTP(ctx, t.rq.Username).Return(nil, models.ErrNoRows)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(models.ErrGeneric)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), CredentialsPolicy{})
 			},
 		},
 	}
@@ -103,11 +255,721 @@ func Test_Auth(t *testing.T) {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 
-			uc := NewAuth(tc.init(&tc))
+			uc := tc.init(&tc)
 
 			resp, err := uc.Authorize(ctx, tc.rq)
-			require.Equal(t, tc.err, err)
+			require.ErrorIs(t, err, tc.err)
+			if resp != nil {
+				// токены случайные (jti), проверяется владелец и наличие refresh токена
+				claims, err := token.Check(resp.Token)
+				require.NoError(t, err)
+				require.Equal(t, tc.rq.Username, claims.Subject)
+				require.NotEmpty(t, resp.RefreshToken)
+				resp.Token, resp.RefreshToken = "", ""
+			}
 			require.Equal(t, tc.resp, resp)
 		})
 	}
 }
+
+func Test_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	policy := CredentialsPolicy{Explicit: true, Password: password.DefaultPolicy}
+	type _tc struct {
+		rq     *models.RegisterRequest
+		policy CredentialsPolicy
+		err    error
+		init   func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"registered": {
+			rq:     &models.RegisterRequest{Username: "u1", Password: "correct-horse"},
+			policy: policy,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h1", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{Login: t.rq.Username, PassHash: "$argon2id$h1"}).Return(nil)
+				mock.EXPECT().ListRoles(ctx, t.rq.Username).Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, t.rq.Username, gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, hasher, permissiveThrottle(ctrl), t.policy)
+			},
+		},
+		"weak_password": {
+			rq:     &models.RegisterRequest{Username: "u1", Password: "short"},
+			policy: policy,
+			err:    models.ErrWeakPassword,
+			init: func(t *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, permissiveThrottle(ctrl), t.policy)
+			},
+		},
+		"invite_used": {
+			rq:     &models.RegisterRequest{Username: "u1", Password: "correct-horse", InviteCode: "c1", ClientIP: "10.0.0.1"},
+			policy: CredentialsPolicy{Explicit: true, InviteOnly: true, AllowList: true, Password: password.DefaultPolicy},
+			err:    models.ErrRegistrationDenied,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, t.rq.Username, t.rq.ClientIP).Return(nil)
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h1", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{
+					Login: t.rq.Username, PassHash: "$argon2id$h1", InviteCode: "c1", CheckAllowList: true,
+				}).Return(models.ErrRegistrationDenied)
+				throttle.EXPECT().Fail(ctx, t.rq.Username, t.rq.ClientIP)
+				return NewAuth(mock, nil, nil, hasher, throttle, t.policy)
+			},
+		},
+		"invite_missing": {
+			rq:     &models.RegisterRequest{Username: "u1", Password: "correct-horse"},
+			policy: CredentialsPolicy{Explicit: true, InviteOnly: true, Password: password.DefaultPolicy},
+			err:    models.ErrRegistrationDenied,
+			init: func(t *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, permissiveThrottle(ctrl), t.policy)
+			},
+		},
+		"user_exists": {
+			rq:     &models.RegisterRequest{Username: "u1", Password: "correct-horse"},
+			policy: policy,
+			err:    models.ErrUserExists,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				hasher.EXPECT().Hash(t.rq.Password).Return("$argon2id$h1", nil)
+				mock.EXPECT().RegisterUser(ctx, gomock.Any()).Return(errors.Join(models.ErrUserExists, errors.New("fake error")))
+				return NewAuth(mock, nil, nil, hasher, permissiveThrottle(ctrl), t.policy)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+
+			resp, err := uc.Register(ctx, tc.rq)
+			require.ErrorIs(t, err, tc.err)
+			if tc.err == nil {
+				claims, err := token.Check(resp.Token)
+				require.NoError(t, err)
+				require.Equal(t, tc.rq.Username, claims.Subject)
+				require.NotEmpty(t, resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func Test_Roles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		grant bool
+		login string
+		role  string
+		err   error
+		init  func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"grant": {
+			grant: true,
+			login: "u1",
+			role:  models.RoleManager,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().GrantRole(ctx, t.login, t.role).Return(nil)
+				return NewAuth(mock, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"grant_unknown_role": {
+			grant: true,
+			login: "u1",
+			role:  "root",
+			err:   models.ErrUnknownRole,
+			init: func(_ *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"grant_unknown_user": {
+			grant: true,
+			login: "u404",
+			role:  models.RoleAdmin,
+			err:   models.ErrNoRows,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().GrantRole(ctx, t.login, t.role).Return(errors.Join(models.ErrNoRows, errors.New("fk")))
+				return NewAuth(mock, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"revoke": { // выданные токены содержат роль, поэтому сессии завершаются
+			login: "u_demoted", // отзыв сессий глобальный, логин не должен пересекаться с другими тестами
+			role:  models.RoleAdmin,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().RevokeRole(ctx, t.login, t.role).Return(nil)
+				denylist.EXPECT().RevokeSessions(ctx, gomock.Cond(func(rs models.RevokedSession) bool {
+					return rs.Login == t.login
+				})).Return(nil)
+				refresh.EXPECT().RevokeRefresh(ctx, t.login).Return(nil)
+				return NewAuth(mock, refresh, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+
+			var err error
+			if tc.grant {
+				err = uc.GrantRole(ctx, tc.login, tc.role)
+			} else {
+				err = uc.RevokeRole(ctx, tc.login, tc.role)
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func Test_BootstrapAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		password string
+		err      error
+		init     func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"created": {
+			password: "Bootstrap-1",
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, "admin").Return("", models.ErrNoRows)
+				hasher.EXPECT().Hash(t.password).Return("$argon2id$a", nil)
+				mock.EXPECT().RegisterUser(ctx, &models.Registration{Login: "admin", PassHash: "$argon2id$a"}).Return(nil)
+				mock.EXPECT().GrantRole(ctx, "admin", models.RoleAdmin).Return(nil)
+				return NewAuth(mock, nil, nil, hasher, nil, CredentialsPolicy{Password: password.DefaultPolicy})
+			},
+		},
+		"existing_user": { // пароль существующего пользователя не меняется
+			init: func(_ *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, "admin").Return("$argon2id$old", nil)
+				mock.EXPECT().GrantRole(ctx, "admin", models.RoleAdmin).Return(nil)
+				return NewAuth(mock, nil, nil, nil, nil, CredentialsPolicy{Password: password.DefaultPolicy})
+			},
+		},
+		"weak_password": {
+			password: "admin",
+			err:      models.ErrWeakPassword,
+			init: func(_ *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, "admin").Return("", models.ErrNoRows)
+				return NewAuth(mock, nil, nil, nil, nil, CredentialsPolicy{Password: password.DefaultPolicy})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+
+			require.ErrorIs(t, uc.BootstrapAdmin(ctx, "admin", tc.password), tc.err)
+		})
+	}
+}
+
+func Test_Deactivate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		login string
+		err   error
+		init  func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"deactivated": { // токены пользователя отзываются
+			login: "u_deactivated",
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().Deactivate(ctx, t.login).Return(nil)
+				denylist.EXPECT().RevokeSessions(ctx, gomock.Cond(func(rs models.RevokedSession) bool {
+					return rs.Login == t.login
+				})).Return(nil)
+				refresh.EXPECT().RevokeRefresh(ctx, t.login).Return(nil)
+				return NewAuth(mock, refresh, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"unknown_user": {
+			login: "u404",
+			err:   models.ErrNoRows,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().Deactivate(ctx, t.login).Return(models.ErrNoRows)
+				return NewAuth(mock, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"company_pool": {
+			login: models.CompanyPool,
+			err:   models.ErrForbidden,
+			init: func(_ *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+
+			require.ErrorIs(t, uc.Deactivate(ctx, tc.login), tc.err)
+		})
+	}
+}
+
+func Test_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policy := CredentialsPolicy{Password: password.DefaultPolicy}
+	type _tc struct {
+		login string
+		rq    *models.ChangePasswordRequest
+		err   error
+		init  func(context.Context, *_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"changed": { // все сессии, включая текущую, завершаются
+			login: "u_changed",
+			rq:    &models.ChangePasswordRequest{CurrentPassword: "p1", NewPassword: "correct-horse"},
+			init: func(ctx context.Context, t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.login).Return("$argon2id$old", nil)
+				hasher.EXPECT().Verify("p1", "$argon2id$old").Return(true, nil)
+				hasher.EXPECT().Hash("correct-horse").Return("$argon2id$new", nil)
+				mock.EXPECT().UpdatePassword(ctx, t.login, "$argon2id$new").Return(nil)
+				denylist.EXPECT().RevokeSessions(ctx, gomock.Cond(func(rs models.RevokedSession) bool {
+					return rs.Login == t.login
+				})).Return(nil)
+				refresh.EXPECT().RevokeRefresh(ctx, t.login).Return(nil)
+				return NewAuth(mock, refresh, denylist, hasher, permissiveThrottle(ctrl), policy)
+			},
+		},
+		"wrong_current": {
+			login: "u1",
+			rq:    &models.ChangePasswordRequest{CurrentPassword: "p2", NewPassword: "correct-horse", ClientIP: "10.0.0.1"},
+			err:   models.ErrInvalidPassword,
+			init: func(ctx context.Context, t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, t.login, "10.0.0.1").Return(nil)
+				mock.EXPECT().CheckLogin(ctx, t.login).Return("$argon2id$old", nil)
+				hasher.EXPECT().Verify("p2", "$argon2id$old").Return(false, nil)
+				throttle.EXPECT().Fail(ctx, t.login, "10.0.0.1")
+				return NewAuth(mock, nil, nil, hasher, throttle, policy)
+			},
+		},
+		"weak_new": {
+			login: "u1",
+			rq:    &models.ChangePasswordRequest{CurrentPassword: "p1", NewPassword: "p2"},
+			err:   models.ErrWeakPassword,
+			init: func(ctx context.Context, t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				mock.EXPECT().CheckLogin(ctx, t.login).Return("$argon2id$old", nil)
+				hasher.EXPECT().Verify("p1", "$argon2id$old").Return(true, nil)
+				return NewAuth(mock, nil, nil, hasher, permissiveThrottle(ctrl), policy)
+			},
+		},
+		"no_claims": {
+			rq:  &models.ChangePasswordRequest{CurrentPassword: "p1", NewPassword: "correct-horse"},
+			err: models.ErrInvalidToken,
+			init: func(_ context.Context, _ *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, nil, policy)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.login != "" {
+				ctx = token.ContextWithClaims(ctx, &token.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: tc.login}})
+			}
+			uc := tc.init(ctx, &tc)
+
+			require.ErrorIs(t, uc.ChangePassword(ctx, tc.rq), tc.err)
+		})
+	}
+}
+
+func Test_PasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	policy := CredentialsPolicy{Password: password.DefaultPolicy, ResetTTL: time.Hour}
+
+	t.Run("issue", func(t *testing.T) {
+		mock := NewMockauthRepo(ctrl)
+		expires := time.Now().Add(time.Hour)
+
+		var hash string
+		mock.EXPECT().SaveReset(ctx, "u1", gomock.Any(), time.Hour).
+			DoAndReturn(func(_ context.Context, _ string, h string, _ time.Duration) (time.Time, error) {
+				hash = h
+
+				return expires, nil
+			})
+
+		resp, err := NewAuth(mock, nil, nil, nil, nil, policy).IssueReset(ctx, "u1")
+		require.NoError(t, err)
+		require.Equal(t, expires, resp.ExpiresAt)
+		require.Equal(t, token.HashResetCode(resp.Code), hash) // в БД только хеш
+	})
+
+	type _tc struct {
+		rq   *models.ResetPasswordRequest
+		err  error
+		init func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"reset": {
+			rq: &models.ResetPasswordRequest{Code: "c1", NewPassword: "correct-horse"},
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				hash := token.HashResetCode(t.rq.Code)
+				mock.EXPECT().GetReset(ctx, hash).Return("u_reset", nil)
+				hasher.EXPECT().Hash(t.rq.NewPassword).Return("$argon2id$new", nil)
+				mock.EXPECT().ConsumeReset(ctx, hash, "$argon2id$new").Return("u_reset", nil)
+				throttle.EXPECT().Reset(ctx, "u_reset")
+				denylist.EXPECT().RevokeSessions(ctx, gomock.Any()).Return(nil)
+				refresh.EXPECT().RevokeRefresh(ctx, "u_reset").Return(nil)
+				return NewAuth(mock, refresh, denylist, hasher, throttle, policy)
+			},
+		},
+		"unknown_code": {
+			rq:  &models.ResetPasswordRequest{Code: "c2", NewPassword: "correct-horse"},
+			err: models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().GetReset(ctx, token.HashResetCode(t.rq.Code)).Return("", models.ErrNoRows)
+				return NewAuth(mock, nil, nil, nil, nil, policy)
+			},
+		},
+		"used_concurrently": {
+			rq:  &models.ResetPasswordRequest{Code: "c3", NewPassword: "correct-horse"},
+			err: models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				hasher := NewMockPasswordHasher(ctrl)
+
+				hash := token.HashResetCode(t.rq.Code)
+				mock.EXPECT().GetReset(ctx, hash).Return("u1", nil)
+				hasher.EXPECT().Hash(t.rq.NewPassword).Return("$argon2id$new", nil)
+				mock.EXPECT().ConsumeReset(ctx, hash, "$argon2id$new").Return("", models.ErrNoRows)
+				return NewAuth(mock, nil, nil, hasher, nil, policy)
+			},
+		},
+		"weak_password": {
+			rq:  &models.ResetPasswordRequest{Code: "c4", NewPassword: "u1u1u1u1U"},
+			err: models.ErrWeakPassword,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+
+				mock.EXPECT().GetReset(ctx, token.HashResetCode(t.rq.Code)).Return("u1", nil)
+				return NewAuth(mock, nil, nil, nil, nil, policy)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+
+			require.ErrorIs(t, uc.ResetPassword(ctx, tc.rq), tc.err)
+		})
+	}
+}
+
+func Test_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		refreshToken string
+		login        string
+		roles        []string
+		err          error
+		init         func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"rotated": { // роли перечитываются, выданная роль появляется после refresh
+			refreshToken: "r1",
+			login:        "u1",
+			roles:        []string{models.RoleManager},
+			err:          nil,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				refresh.EXPECT().RotateRefresh(ctx, token.HashRefresh(t.refreshToken), gomock.Any(), token.RefreshTTL()).Return(t.login, nil)
+				mock.EXPECT().ListRoles(ctx, t.login).Return(t.roles, nil)
+				return NewAuth(mock, refresh, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"unknown": {
+			refreshToken: "r2",
+			err:          models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				refresh := NewMockrefreshRepo(ctrl)
+
+				refresh.EXPECT().RotateRefresh(ctx, token.HashRefresh(t.refreshToken), gomock.Any(), token.RefreshTTL()).Return("", models.ErrNoRows)
+				refresh.EXPECT().GetRefresh(ctx, token.HashRefresh(t.refreshToken)).Return(nil, models.ErrNoRows)
+				return NewAuth(nil, refresh, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"expired": {
+			refreshToken: "r3",
+			err:          models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				refresh := NewMockrefreshRepo(ctrl)
+
+				refresh.EXPECT().RotateRefresh(ctx, token.HashRefresh(t.refreshToken), gomock.Any(), token.RefreshTTL()).Return("", models.ErrNoRows)
+				refresh.EXPECT().GetRefresh(ctx, token.HashRefresh(t.refreshToken)).Return(&models.RefreshToken{Login: "u3"}, nil)
+				return NewAuth(nil, refresh, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"reused": {
+			refreshToken: "r4",
+			err:          models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				refresh := NewMockrefreshRepo(ctrl)
+
+				refresh.EXPECT().RotateRefresh(ctx, token.HashRefresh(t.refreshToken), gomock.Any(), token.RefreshTTL()).Return("", models.ErrNoRows)
+				refresh.EXPECT().GetRefresh(ctx, token.HashRefresh(t.refreshToken)).Return(&models.RefreshToken{Login: "u4", Used: true}, nil)
+				refresh.EXPECT().RevokeRefresh(ctx, "u4").Return(nil)
+				return NewAuth(nil, refresh, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"db_issue": {
+			refreshToken: "r5",
+			err:          models.ErrGeneric,
+			init: func(t *_tc) *auth {
+				refresh := NewMockrefreshRepo(ctrl)
+
+				refresh.EXPECT().RotateRefresh(ctx, token.HashRefresh(t.refreshToken), gomock.Any(), token.RefreshTTL()).Return("", models.ErrGeneric)
+				return NewAuth(nil, refresh, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+
+			uc := tc.init(&tc)
+
+			resp, err := uc.Refresh(ctx, tc.refreshToken)
+			require.ErrorIs(t, err, tc.err)
+			if tc.err != nil {
+				require.Nil(t, resp)
+
+				return
+			}
+			claims, err := token.Check(resp.Token)
+			require.NoError(t, err)
+			require.Equal(t, tc.login, claims.Subject)
+			require.Equal(t, tc.roles, claims.Roles)
+			require.NotEmpty(t, resp.RefreshToken)
+			require.NotEqual(t, tc.refreshToken, resp.RefreshToken)
+		})
+	}
+}
+
+func Test_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	require.NoError(t, token.Reinit()) // сбросить глобальный denylist от предыдущих запусков
+
+	type _tc struct {
+		login string
+		rq    *models.LogoutRequest
+		err   error
+
+		revoked bool // access токен из контекста больше не принимается
+		inTx    bool // внутри идемпотентной транзакции, которая откатывается
+		init    func(*_tc, *token.Claims) *auth
+	}
+	testCases := map[string]_tc{
+		"logout": {
+			login:   "u1",
+			rq:      &models.LogoutRequest{},
+			revoked: true,
+			init: func(t *_tc, c *token.Claims) *auth {
+				denylist := NewMockdenylistRepo(ctrl)
+
+				denylist.EXPECT().RevokeToken(gomock.Any(), models.RevokedToken{ID: c.ID, Login: t.login, ExpiresAt: c.ExpiresAt.Time}).Return(nil)
+				return NewAuth(nil, nil, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"logout_refresh": {
+			login:   "u2",
+			rq:      &models.LogoutRequest{RefreshToken: "r2"},
+			revoked: true,
+			init: func(t *_tc, c *token.Claims) *auth {
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				denylist.EXPECT().RevokeToken(gomock.Any(), models.RevokedToken{ID: c.ID, Login: t.login, ExpiresAt: c.ExpiresAt.Time}).Return(nil)
+				refresh.EXPECT().RevokeRefreshFamily(gomock.Any(), t.login, token.HashRefresh("r2")).Return(nil)
+				return NewAuth(nil, refresh, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"logout_all": {
+			login:   "u3",
+			rq:      &models.LogoutRequest{All: true},
+			revoked: true,
+			init: func(t *_tc, _ *token.Claims) *auth {
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				denylist.EXPECT().RevokeSessions(gomock.Any(), gomock.Cond(func(rs models.RevokedSession) bool {
+					return rs.Login == t.login && rs.ExpiresAt.Sub(rs.RevokedAt) == token.TTL()
+				})).Return(nil)
+				refresh.EXPECT().RevokeRefresh(gomock.Any(), t.login).Return(nil)
+				return NewAuth(nil, refresh, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"logout_all_rolled_back": {
+			login:   "u5",
+			rq:      &models.LogoutRequest{All: true},
+			err:     models.ErrGeneric,
+			revoked: false,
+			inTx:    true,
+			init: func(t *_tc, _ *token.Claims) *auth {
+				denylist := NewMockdenylistRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				denylist.EXPECT().RevokeSessions(gomock.Any(), gomock.Any()).Return(nil)
+				refresh.EXPECT().RevokeRefresh(gomock.Any(), t.login).Return(models.ErrGeneric)
+				return NewAuth(nil, refresh, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"db_issue": {
+			login:   "u4",
+			rq:      &models.LogoutRequest{},
+			err:     models.ErrGeneric,
+			revoked: false,
+			init: func(t *_tc, c *token.Claims) *auth {
+				denylist := NewMockdenylistRepo(ctrl)
+
+				denylist.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Return(models.ErrGeneric)
+				return NewAuth(nil, nil, denylist, nil, nil, CredentialsPolicy{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tokn, err := token.Create(tc.login)
+			require.NoError(t, err)
+			claims, err := token.Check(tokn)
+			require.NoError(t, err)
+
+			uc := tc.init(&tc, claims)
+
+			ctx := token.ContextWithClaims(context.Background(), claims)
+			if tc.inTx {
+				ctx = context.WithValue(ctx, commitHooksKey{}, &commitHooks{})
+			}
+			err = uc.Logout(ctx, tc.rq)
+			require.ErrorIs(t, err, tc.err)
+
+			_, err = token.Check(tokn)
+			require.Equal(t, tc.revoked, errors.Is(err, token.ErrTokenRevoked))
+		})
+	}
+}
+
+func Test_SyncRevoked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	revokedToken, _ := token.Create("u1")
+	claims, _ := token.Check(revokedToken)
+	otherToken, _ := token.Create("u1")
+	sessionToken, _ := token.Create("u2")
+
+	denylist := NewMockdenylistRepo(ctrl)
+	denylist.EXPECT().ListRevoked(ctx).Return(
+		[]models.RevokedToken{{ID: claims.ID, Login: "u1", ExpiresAt: claims.ExpiresAt.Time}},
+		[]models.RevokedSession{{Login: "u2", RevokedAt: time.Now(), ExpiresAt: time.Now().Add(token.TTL())}},
+		nil)
+
+	require.NoError(t, NewAuth(nil, nil, denylist, nil, nil, CredentialsPolicy{}).SyncRevoked(ctx))
+
+	_, err := token.Check(revokedToken)
+	require.ErrorIs(t, err, token.ErrTokenRevoked)
+	_, err = token.Check(otherToken)
+	require.NoError(t, err)
+	_, err = token.Check(sessionToken)
+	require.ErrorIs(t, err, token.ErrTokenRevoked)
+}
+
+// permissiveThrottle never locks, for tests not related to brute-force protection.
+func permissiveThrottle(ctrl *gomock.Controller) *MockloginThrottle {
+	throttle := NewMockloginThrottle(ctrl)
+	throttle.EXPECT().Check(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	throttle.EXPECT().Fail(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	throttle.EXPECT().Reset(gomock.Any(), gomock.Any()).AnyTimes()
+
+	return throttle
+}
@@ -0,0 +1,171 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=catalog.go -destination=catalog_mocks.go *
+
+import (
+	"context"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type catalogRepo interface {
+	ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error)
+	CreateItem(ctx context.Context, item *models.Item) error
+	UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error
+	UpdatePrice(ctx context.Context, name string, price int) error
+	UpdateStock(ctx context.Context, name string, stock *int, maxPerUser *int) error
+	RetireItem(ctx context.Context, name string) error
+	RestoreItem(ctx context.Context, name string) error
+	ListPrices(ctx context.Context, name string) ([]models.ItemPrice, error)
+	SchedulePrice(ctx context.Context, name string, price int, from time.Time, until *time.Time) (*models.ItemPrice, error)
+	CancelPrice(ctx context.Context, name string, id int64) error
+}
+
+// itemsPageSize is used when the client does not set limit.
+const itemsPageSize = 100
+
+type catalog struct {
+	repo   catalogRepo
+	wishes wishes
+
+	now func() time.Time
+}
+
+func NewCatalog(repo catalogRepo, wishes wishes) *catalog { //nolint:revive
+	return &catalog{repo: repo, wishes: wishes, now: time.Now}
+}
+
+// ListItems returns a page of items on sale. Retired items are included only for the admin list.
+func (c *catalog) ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error) {
+	if f.Limit == 0 {
+		f.Limit = itemsPageSize
+	}
+	items, err := c.repo.ListItems(ctx, retired, f)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if items == nil {
+		items = []models.Item{}
+	}
+
+	return items, nil
+}
+
+func (c *catalog) CreateItem(ctx context.Context, rq *models.CreateItemRequest) error {
+	item := &models.Item{
+		Name: rq.Name, Price: *rq.Price, Stock: rq.Stock, MaxPerUser: rq.MaxPerUser,
+		Product: rq.Product, Size: rq.Size, Color: rq.Color,
+	}
+	if err := c.repo.CreateItem(ctx, item); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// UpdateProduct changes details shown for all variants of the product.
+func (c *catalog) UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error {
+	if err := c.repo.UpdateProduct(ctx, name, rq); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (c *catalog) SetPrice(ctx context.Context, name string, price int) error {
+	if err := c.repo.UpdatePrice(ctx, name, price); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	c.wishes.Changed(ctx, nil, []string{name})
+
+	return nil
+}
+
+func (c *catalog) Prices(ctx context.Context, name string) ([]models.ItemPrice, error) {
+	prices, err := c.repo.ListPrices(ctx, name)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return prices, nil
+}
+
+// SchedulePrice plans price change or a sale. History can not be rewritten,
+// so the price can not start in the past.
+func (c *catalog) SchedulePrice(ctx context.Context, name string, rq *models.SchedulePriceRequest) (*models.ItemPrice, error) {
+	now := c.now()
+	from := now
+	if rq.From != nil {
+		from = *rq.From
+	}
+	if from.Before(now) || (rq.Until != nil && !rq.Until.After(from)) {
+		return nil, models.ErrInvalidSchedule
+	}
+
+	price, err := c.repo.SchedulePrice(ctx, name, *rq.Price, from, rq.Until)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return price, nil
+}
+
+func (c *catalog) CancelPrice(ctx context.Context, name string, id int64) error {
+	if err := c.repo.CancelPrice(ctx, name, id); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// SetStock restocks a limited item or makes it unlimited. Already bought
+// items are not counted back: stock is what remains for sale.
+func (c *catalog) SetStock(ctx context.Context, name string, rq *models.ItemStockRequest) error {
+	if err := c.repo.UpdateStock(ctx, name, rq.Stock, rq.MaxPerUser); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	c.wishes.Changed(ctx, nil, []string{name})
+
+	return nil
+}
+
+// Retire takes item off sale. Purchased items stay in users' inventory.
+func (c *catalog) Retire(ctx context.Context, name string) error {
+	if err := c.repo.RetireItem(ctx, name); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	c.wishes.Changed(ctx, nil, []string{name})
+
+	return nil
+}
+
+func (c *catalog) Restore(ctx context.Context, name string) error {
+	if err := c.repo.RestoreItem(ctx, name); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	c.wishes.Changed(ctx, nil, []string{name})
+
+	return nil
+}
@@ -0,0 +1,186 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: catalog.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=catalog.go -destination=catalog_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/cxbelka/winter_2025/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockcatalogRepo is a mock of catalogRepo interface.
+type MockcatalogRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockcatalogRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockcatalogRepoMockRecorder is the mock recorder for MockcatalogRepo.
+type MockcatalogRepoMockRecorder struct {
+	mock *MockcatalogRepo
+}
+
+// NewMockcatalogRepo creates a new mock instance.
+func NewMockcatalogRepo(ctrl *gomock.Controller) *MockcatalogRepo {
+	mock := &MockcatalogRepo{ctrl: ctrl}
+	mock.recorder = &MockcatalogRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcatalogRepo) EXPECT() *MockcatalogRepoMockRecorder {
+	return m.recorder
+}
+
+// CancelPrice mocks base method.
+func (m *MockcatalogRepo) CancelPrice(ctx context.Context, name string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPrice", ctx, name, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPrice indicates an expected call of CancelPrice.
+func (mr *MockcatalogRepoMockRecorder) CancelPrice(ctx, name, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPrice", reflect.TypeOf((*MockcatalogRepo)(nil).CancelPrice), ctx, name, id)
+}
+
+// CreateItem mocks base method.
+func (m *MockcatalogRepo) CreateItem(ctx context.Context, item *models.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateItem indicates an expected call of CreateItem.
+func (mr *MockcatalogRepoMockRecorder) CreateItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockcatalogRepo)(nil).CreateItem), ctx, item)
+}
+
+// ListItems mocks base method.
+func (m *MockcatalogRepo) ListItems(ctx context.Context, retired bool, f *models.ItemFilter) ([]models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, retired, f)
+	ret0, _ := ret[0].([]models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockcatalogRepoMockRecorder) ListItems(ctx, retired, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockcatalogRepo)(nil).ListItems), ctx, retired, f)
+}
+
+// ListPrices mocks base method.
+func (m *MockcatalogRepo) ListPrices(ctx context.Context, name string) ([]models.ItemPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrices", ctx, name)
+	ret0, _ := ret[0].([]models.ItemPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrices indicates an expected call of ListPrices.
+func (mr *MockcatalogRepoMockRecorder) ListPrices(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrices", reflect.TypeOf((*MockcatalogRepo)(nil).ListPrices), ctx, name)
+}
+
+// RestoreItem mocks base method.
+func (m *MockcatalogRepo) RestoreItem(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItem", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreItem indicates an expected call of RestoreItem.
+func (mr *MockcatalogRepoMockRecorder) RestoreItem(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItem", reflect.TypeOf((*MockcatalogRepo)(nil).RestoreItem), ctx, name)
+}
+
+// RetireItem mocks base method.
+func (m *MockcatalogRepo) RetireItem(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireItem", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireItem indicates an expected call of RetireItem.
+func (mr *MockcatalogRepoMockRecorder) RetireItem(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireItem", reflect.TypeOf((*MockcatalogRepo)(nil).RetireItem), ctx, name)
+}
+
+// SchedulePrice mocks base method.
+func (m *MockcatalogRepo) SchedulePrice(ctx context.Context, name string, price int, from time.Time, until *time.Time) (*models.ItemPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePrice", ctx, name, price, from, until)
+	ret0, _ := ret[0].(*models.ItemPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchedulePrice indicates an expected call of SchedulePrice.
+func (mr *MockcatalogRepoMockRecorder) SchedulePrice(ctx, name, price, from, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePrice", reflect.TypeOf((*MockcatalogRepo)(nil).SchedulePrice), ctx, name, price, from, until)
+}
+
+// UpdatePrice mocks base method.
+func (m *MockcatalogRepo) UpdatePrice(ctx context.Context, name string, price int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrice", ctx, name, price)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrice indicates an expected call of UpdatePrice.
+func (mr *MockcatalogRepoMockRecorder) UpdatePrice(ctx, name, price any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrice", reflect.TypeOf((*MockcatalogRepo)(nil).UpdatePrice), ctx, name, price)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockcatalogRepo) UpdateProduct(ctx context.Context, name string, rq *models.ProductRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, name, rq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockcatalogRepoMockRecorder) UpdateProduct(ctx, name, rq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockcatalogRepo)(nil).UpdateProduct), ctx, name, rq)
+}
+
+// UpdateStock mocks base method.
+func (m *MockcatalogRepo) UpdateStock(ctx context.Context, name string, stock, maxPerUser *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStock", ctx, name, stock, maxPerUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStock indicates an expected call of UpdateStock.
+func (mr *MockcatalogRepoMockRecorder) UpdateStock(ctx, name, stock, maxPerUser any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStock", reflect.TypeOf((*MockcatalogRepo)(nil).UpdateStock), ctx, name, stock, maxPerUser)
+}
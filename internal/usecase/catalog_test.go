@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Catalog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockcatalogRepo(ctrl)
+	wishes := NewMockwishes(ctrl)
+	uc := NewCatalog(mock, wishes)
+
+	// пустой каталог отдаётся как [], а не null
+	mock.EXPECT().ListItems(ctx, false, &models.ItemFilter{Limit: itemsPageSize}).Return(nil, nil)
+	items, err := uc.ListItems(ctx, false, &models.ItemFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []models.Item{}, items)
+
+	// заданный клиентом размер страницы не меняется
+	f := &models.ItemFilter{Category: "clothing", Limit: 10, Offset: 20}
+	mock.EXPECT().ListItems(ctx, true, &models.ItemFilter{Category: "clothing", Limit: 10, Offset: 20}).Return(nil, nil)
+	_, err = uc.ListItems(ctx, true, f)
+	require.NoError(t, err)
+
+	price := 0
+	mock.EXPECT().CreateItem(ctx, &models.Item{Name: "sticker", Price: 0}).Return(nil)
+	require.NoError(t, uc.CreateItem(ctx, &models.CreateItemRequest{Name: "sticker", Price: &price}))
+
+	mock.EXPECT().CreateItem(ctx, &models.Item{Name: "hoody-xl", Price: 0, Product: "hoody", Size: "XL"}).Return(nil)
+	require.NoError(t, uc.CreateItem(ctx, &models.CreateItemRequest{Name: "hoody-xl", Price: &price, Product: "hoody", Size: "XL"}))
+
+	mock.EXPECT().UpdateProduct(ctx, "hoody", &models.ProductRequest{Category: "clothing"}).Return(models.ErrItemNotFound)
+	require.ErrorIs(t, uc.UpdateProduct(ctx, "hoody", &models.ProductRequest{Category: "clothing"}), models.ErrItemNotFound)
+
+	mock.EXPECT().CreateItem(ctx, gomock.Any()).Return(models.ErrItemExists)
+	require.ErrorIs(t, uc.CreateItem(ctx, &models.CreateItemRequest{Name: "cup", Price: &price}), models.ErrItemExists)
+
+	mock.EXPECT().UpdatePrice(ctx, "cup", 25).Return(nil)
+	wishes.EXPECT().Changed(gomock.Any(), nil, []string{"cup"})
+	require.NoError(t, uc.SetPrice(ctx, "cup", 25))
+
+	stock := 5
+	mock.EXPECT().UpdateStock(ctx, "pink-hoody", &stock, nil).Return(nil)
+	wishes.EXPECT().Changed(gomock.Any(), nil, []string{"pink-hoody"})
+	require.NoError(t, uc.SetStock(ctx, "pink-hoody", &models.ItemStockRequest{Stock: &stock}))
+
+	mock.EXPECT().RetireItem(ctx, "unknown").Return(models.ErrItemNotFound)
+	require.ErrorIs(t, uc.Retire(ctx, "unknown"), models.ErrItemNotFound)
+
+	mock.EXPECT().RestoreItem(ctx, "cup").Return(nil)
+	wishes.EXPECT().Changed(gomock.Any(), nil, []string{"cup"})
+	require.NoError(t, uc.Restore(ctx, "cup"))
+}
+
+func Test_SchedulePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	price := 10
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+
+		return &v
+	}
+
+	testCases := map[string]struct {
+		rq  *models.SchedulePriceRequest
+		err error
+
+		init func(*MockcatalogRepo)
+	}{
+		"now": {
+			rq: &models.SchedulePriceRequest{Price: &price},
+			init: func(m *MockcatalogRepo) {
+				m.EXPECT().SchedulePrice(ctx, "cup", 10, now, nil).Return(&models.ItemPrice{}, nil)
+			},
+		},
+		"sale": {
+			rq: &models.SchedulePriceRequest{Price: &price, From: at(time.Hour), Until: at(2 * time.Hour)},
+			init: func(m *MockcatalogRepo) {
+				m.EXPECT().SchedulePrice(ctx, "cup", 10, *at(time.Hour), at(2*time.Hour)).Return(&models.ItemPrice{}, nil)
+			},
+		},
+		"in_past": {
+			rq:  &models.SchedulePriceRequest{Price: &price, From: at(-time.Minute)},
+			err: models.ErrInvalidSchedule,
+		},
+		"ends_before_start": {
+			rq:  &models.SchedulePriceRequest{Price: &price, From: at(time.Hour), Until: at(time.Hour)},
+			err: models.ErrInvalidSchedule,
+		},
+		"sale_ended": {
+			rq:  &models.SchedulePriceRequest{Price: &price, Until: at(-time.Hour)},
+			err: models.ErrInvalidSchedule,
+		},
+		"unknown_item": {
+			rq:  &models.SchedulePriceRequest{Price: &price},
+			err: models.ErrItemNotFound,
+			init: func(m *MockcatalogRepo) {
+				m.EXPECT().SchedulePrice(ctx, "cup", 10, now, nil).Return(nil, models.ErrItemNotFound)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockcatalogRepo(ctrl)
+			if tc.init != nil {
+				tc.init(mock)
+			}
+			uc := NewCatalog(mock, nil)
+			uc.now = func() time.Time { return now }
+
+			_, err := uc.SchedulePrice(ctx, "cup", tc.rq)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
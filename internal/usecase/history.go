@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+// historyPageSize is used when the client does not set limit.
+const historyPageSize = 50
+
+// encodeCursor hides the page key: clients pass it back as is.
+func encodeCursor(date time.Time, id int64) string {
+	key := strconv.FormatInt(date.UnixMicro(), 10) + "." + strconv.FormatInt(id, 10)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (*models.HistoryKey, error) {
+	if cursor == "" {
+		return nil, nil //nolint:nilnil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	micro, id, ok := strings.Cut(string(b), ".")
+	if !ok {
+		return nil, models.ErrInvalidCursor
+	}
+	m, err := strconv.ParseInt(micro, 10, 64)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	key := &models.HistoryKey{Date: time.UnixMicro(m).UTC()}
+	if key.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	return key, nil
+}
+
+// historyPage prepares the repo query: one row more than the limit tells whether there is a next page.
+func historyPage(f *models.HistoryFilter) (*models.HistoryFilter, *models.HistoryKey, error) {
+	after, err := decodeCursor(f.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	q := *f
+	if q.Limit == 0 {
+		q.Limit = historyPageSize
+	}
+	q.Limit++
+
+	return &q, after, nil
+}
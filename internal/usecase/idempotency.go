@@ -0,0 +1,73 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=idempotency.go -destination=idempotency_mocks.go *
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type idempotencyRepo interface {
+	Do(
+		ctx context.Context, rq *models.IdempotentRequest, expired time.Time,
+		f func(ctx context.Context) *models.StoredResponse,
+	) (*models.StoredResponse, bool, error)
+}
+
+type idempotency struct {
+	repo idempotencyRepo
+
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewIdempotency: responses are kept for ttl, then the key may be reused.
+func NewIdempotency(repo idempotencyRepo, ttl time.Duration) *idempotency { //nolint:revive
+	return &idempotency{repo: repo, ttl: ttl, now: time.Now}
+}
+
+// Do runs f at most once per user key and replays its response to repeated requests.
+// Reusing the key for a different request gives ErrIdempotencyMismatch.
+// Callbacks registered by f with onCommit run after its changes are committed.
+func (i *idempotency) Do(
+	ctx context.Context, rq *models.IdempotentRequest, f func(ctx context.Context) *models.StoredResponse,
+) (*models.StoredResponse, bool, error) {
+	hooks := &commitHooks{}
+	resp, replayed, err := i.repo.Do(context.WithValue(ctx, commitHooksKey{}, hooks), rq, i.now().Add(-i.ttl), f)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, false, err //nolint:wrapcheck
+	}
+	// изменения операции сохраняются только при успешном ответе
+	if !replayed && resp.Status < http.StatusBadRequest {
+		for _, h := range hooks.fs {
+			h()
+		}
+	}
+
+	return resp, replayed, nil
+}
+
+type commitHooksKey struct{}
+
+// commitHooks are callbacks of an operation running in the idempotency transaction.
+type commitHooks struct {
+	fs []func()
+}
+
+// onCommit runs f once the operation changes are visible to other connections:
+// after the idempotency transaction commits, or right away outside of it.
+// Callbacks of an operation rolled back are dropped.
+func onCommit(ctx context.Context, f func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.fs = append(hooks.fs, f)
+
+		return
+	}
+	f()
+}
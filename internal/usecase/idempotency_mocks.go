@@ -0,0 +1,59 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: idempotency.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=idempotency.go -destination=idempotency_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/cxbelka/winter_2025/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockidempotencyRepo is a mock of idempotencyRepo interface.
+type MockidempotencyRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockidempotencyRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockidempotencyRepoMockRecorder is the mock recorder for MockidempotencyRepo.
+type MockidempotencyRepoMockRecorder struct {
+	mock *MockidempotencyRepo
+}
+
+// NewMockidempotencyRepo creates a new mock instance.
+func NewMockidempotencyRepo(ctrl *gomock.Controller) *MockidempotencyRepo {
+	mock := &MockidempotencyRepo{ctrl: ctrl}
+	mock.recorder = &MockidempotencyRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockidempotencyRepo) EXPECT() *MockidempotencyRepoMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockidempotencyRepo) Do(ctx context.Context, rq *models.IdempotentRequest, expired time.Time, f func(context.Context) *models.StoredResponse) (*models.StoredResponse, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, rq, expired, f)
+	ret0, _ := ret[0].(*models.StoredResponse)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockidempotencyRepoMockRecorder) Do(ctx, rq, expired, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockidempotencyRepo)(nil).Do), ctx, rq, expired, f)
+}
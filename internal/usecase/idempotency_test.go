@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Idempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	mock := NewMockidempotencyRepo(ctrl)
+	uc := NewIdempotency(mock, time.Hour)
+	uc.now = func() time.Time { return now }
+
+	rq := &models.IdempotentRequest{User: "u1", Key: "k1", Hash: []byte{1}}
+	stored := &models.StoredResponse{Status: 200}
+
+	// ключи старше ttl забываются
+	mock.EXPECT().Do(gomock.Any(), rq, now.Add(-time.Hour), gomock.Any()).Return(stored, true, nil)
+	resp, replayed, err := uc.Do(ctx, rq, nil)
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, stored, resp)
+
+	mock.EXPECT().Do(gomock.Any(), rq, now.Add(-time.Hour), gomock.Any()).Return(nil, false, models.ErrIdempotencyMismatch)
+	_, _, err = uc.Do(ctx, rq, nil)
+	require.ErrorIs(t, err, models.ErrIdempotencyMismatch)
+}
+
+func Test_IdempotencyOnCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockidempotencyRepo(ctrl)
+	uc := NewIdempotency(mock, time.Hour)
+	rq := &models.IdempotentRequest{User: "u1", Key: "k1", Hash: []byte{1}}
+
+	run := func(status int) bool {
+		committed := false
+		mock.EXPECT().Do(gomock.Any(), rq, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *models.IdempotentRequest, _ time.Time, f func(context.Context) *models.StoredResponse,
+			) (*models.StoredResponse, bool, error) {
+				resp := f(ctx)
+				// до фиксации транзакции изменения не видны, колбэк ждёт
+				require.False(t, committed)
+
+				return resp, false, nil
+			})
+		_, _, err := uc.Do(ctx, rq, func(ctx context.Context) *models.StoredResponse {
+			onCommit(ctx, func() { committed = true })
+
+			return &models.StoredResponse{Status: status}
+		})
+		require.NoError(t, err)
+
+		return committed
+	}
+	require.True(t, run(200))
+	// изменения ответа с ошибкой откатываются
+	require.False(t, run(409))
+
+	// вне транзакции колбэк выполняется сразу
+	called := false
+	onCommit(ctx, func() { called = true })
+	require.True(t, called)
+}
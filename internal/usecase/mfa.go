@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/token"
+	"github.com/cxbelka/winter_2025/internal/totp"
+)
+
+const (
+	totpIssuer        = "avito-merch-shop"
+	totpSkew          = 1 // принимаются коды соседних 30-секундных окон
+	recoveryCodeCount = 10
+)
+
+// mfaChallenge returns pending MFA token instead of access tokens
+// if the user has confirmed TOTP.
+func (a *auth) mfaChallenge(ctx context.Context, login string) (*models.AuthResponse, error) {
+	t, err := a.repo.GetTOTP(ctx, login)
+	if errors.Is(err, models.ErrNoRows) {
+		return nil, nil //nolint:nilnil
+	}
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if !t.Confirmed {
+		return nil, nil //nolint:nilnil
+	}
+
+	pending, err := token.CreateMFAPending(login)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	logger.AddField(ctx, "mfa", "pending")
+
+	return &models.AuthResponse{MFAToken: pending}, nil
+}
+
+// VerifyMFA is the second step of /api/auth: pending token and TOTP or
+// recovery code are exchanged for access/refresh pair.
+func (a *auth) VerifyMFA(ctx context.Context, rq *models.MFAVerifyRequest) (*models.AuthResponse, error) {
+	claims, err := token.CheckMFAPending(rq.MFAToken)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, models.ErrInvalidToken
+	}
+	login := claims.Subject
+
+	if err := a.throttle.Check(ctx, login, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if err := a.checkSecondFactor(ctx, login, rq.Code); err != nil {
+		if errors.Is(err, models.ErrInvalidOTP) {
+			a.throttle.Fail(ctx, login, rq.ClientIP)
+		}
+		logger.AddError(ctx, err)
+
+		return nil, err
+	}
+	a.throttle.Reset(ctx, login)
+
+	return a.issueTokens(ctx, login)
+}
+
+// EnrollMFA generates a new TOTP secret. It is not used for login until confirmed.
+func (a *auth) EnrollMFA(ctx context.Context) (*models.MFAEnrollResponse, error) {
+	claims := token.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, models.ErrInvalidToken
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	if err := a.repo.SaveTOTP(ctx, claims.Subject, secret); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return &models.MFAEnrollResponse{Secret: secret, URI: totp.URI(totpIssuer, claims.Subject, secret)}, nil
+}
+
+// ConfirmMFA enables TOTP after the first valid code. Recovery codes are
+// returned only here, the server keeps hashes.
+func (a *auth) ConfirmMFA(ctx context.Context, rq *models.MFACodeRequest) (*models.MFARecoveryCodes, error) {
+	claims := token.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, models.ErrInvalidToken
+	}
+	login := claims.Subject
+
+	if err := a.throttle.Check(ctx, login, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	t, err := a.repo.GetTOTP(ctx, login)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if t.Confirmed {
+		logger.AddError(ctx, models.ErrMFAEnabled)
+
+		return nil, models.ErrMFAEnabled
+	}
+	step, ok := totp.Validate(t.Secret, rq.Code, a.now(), totpSkew)
+	if !ok {
+		a.throttle.Fail(ctx, login, rq.ClientIP)
+		logger.AddError(ctx, models.ErrInvalidOTP)
+
+		return nil, models.ErrInvalidOTP
+	}
+
+	codes, err := totp.RecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, errors.Join(models.ErrGeneric, err)
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, totp.HashRecoveryCode(c))
+	}
+	if err := a.repo.ConfirmTOTP(ctx, login, t.Secret, step, hashes); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return &models.MFARecoveryCodes{Codes: codes}, nil
+}
+
+// DisableMFA requires a valid TOTP or recovery code, the access token alone is not enough.
+func (a *auth) DisableMFA(ctx context.Context, rq *models.MFACodeRequest) error {
+	claims := token.ClaimsFromContext(ctx)
+	if claims == nil {
+		return models.ErrInvalidToken
+	}
+	login := claims.Subject
+
+	if err := a.throttle.Check(ctx, login, rq.ClientIP); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+	if err := a.checkSecondFactor(ctx, login, rq.Code); err != nil {
+		if errors.Is(err, models.ErrInvalidOTP) {
+			a.throttle.Fail(ctx, login, rq.ClientIP)
+		}
+		logger.AddError(ctx, err)
+
+		return err
+	}
+	if err := a.repo.DeleteTOTP(ctx, login); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// checkSecondFactor accepts 6 digit TOTP code once per time step, anything else
+// is treated as a recovery code.
+func (a *auth) checkSecondFactor(ctx context.Context, login string, code string) error {
+	t, err := a.repo.GetTOTP(ctx, login)
+	if errors.Is(err, models.ErrNoRows) || (err == nil && !t.Confirmed) {
+		return models.ErrInvalidOTP
+	}
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return a.repo.UseRecoveryCode(ctx, login, totp.HashRecoveryCode(code)) //nolint:wrapcheck
+	}
+	step, ok := totp.Validate(t.Secret, code, a.now(), totpSkew)
+	if !ok || step <= t.LastStep {
+		return models.ErrInvalidOTP
+	}
+
+	return a.repo.UseTOTPStep(ctx, login, step) //nolint:wrapcheck
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/cxbelka/winter_2025/internal/token"
+	"github.com/cxbelka/winter_2025/internal/totp"
+)
+
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+func Test_MFALogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockauthRepo(ctrl)
+	hasher := NewMockPasswordHasher(ctrl)
+	throttle := NewMockloginThrottle(ctrl)
+
+	rq := &models.AuthReqest{Username: "u1", Password: "p1", ClientIP: "10.0.0.1"}
+	throttle.EXPECT().Check(ctx, "u1", "10.0.0.1").Return(nil)
+	mock.EXPECT().CheckLogin(ctx, "u1").Return("$argon2id$h1", nil)
+	hasher.EXPECT().Verify("p1", "$argon2id$h1").Return(true, nil)
+	hasher.EXPECT().NeedsRehash("$argon2id$h1").Return(false)
+	mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: testSecret, Confirmed: true}, nil)
+	// ни refresh токена, ни сброса блокировки до второго фактора
+
+	resp, err := NewAuth(mock, nil, nil, hasher, throttle, CredentialsPolicy{}).Authorize(ctx, rq)
+	require.NoError(t, err)
+	require.Empty(t, resp.Token)
+	require.Empty(t, resp.RefreshToken)
+
+	claims, err := token.CheckMFAPending(resp.MFAToken)
+	require.NoError(t, err)
+	require.Equal(t, "u1", claims.Subject)
+	_, err = token.Check(resp.MFAToken)
+	require.Error(t, err)
+}
+
+func Test_VerifyMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	step := totp.Step(now)
+	code, err := totp.Code(testSecret, now)
+	require.NoError(t, err)
+	pending, err := token.CreateMFAPending("u1")
+	require.NoError(t, err)
+	access, err := token.Create("u1")
+	require.NoError(t, err)
+
+	type _tc struct {
+		rq   *models.MFAVerifyRequest
+		at   time.Duration // смещение фальшивых часов
+		err  error
+		init func(*_tc) *auth
+	}
+	testCases := map[string]_tc{
+		"totp": {
+			rq: &models.MFAVerifyRequest{MFAToken: pending, Code: code},
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, "u1", "").Return(nil)
+				mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: testSecret, Confirmed: true, LastStep: step - 5}, nil)
+				mock.EXPECT().UseTOTPStep(ctx, "u1", step).Return(nil)
+				throttle.EXPECT().Reset(ctx, "u1")
+				mock.EXPECT().ListRoles(ctx, "u1").Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, "u1", gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, nil, throttle, CredentialsPolicy{})
+			},
+		},
+		"replayed_code": { // код уже использован в этом окне
+			rq:  &models.MFAVerifyRequest{MFAToken: pending, Code: code},
+			err: models.ErrInvalidOTP,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, "u1", "").Return(nil)
+				mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: testSecret, Confirmed: true, LastStep: step}, nil)
+				throttle.EXPECT().Fail(ctx, "u1", "")
+				return NewAuth(mock, nil, nil, nil, throttle, CredentialsPolicy{})
+			},
+		},
+		"expired_code": {
+			rq:  &models.MFAVerifyRequest{MFAToken: pending, Code: code},
+			at:  2 * totp.Period,
+			err: models.ErrInvalidOTP,
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, "u1", "").Return(nil)
+				mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: testSecret, Confirmed: true}, nil)
+				throttle.EXPECT().Fail(ctx, "u1", "")
+				return NewAuth(mock, nil, nil, nil, throttle, CredentialsPolicy{})
+			},
+		},
+		"recovery_code": {
+			rq: &models.MFAVerifyRequest{MFAToken: pending, Code: "ABCDE-fghij"},
+			init: func(t *_tc) *auth {
+				mock := NewMockauthRepo(ctrl)
+				refresh := NewMockrefreshRepo(ctrl)
+
+				mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: testSecret, Confirmed: true}, nil)
+				mock.EXPECT().UseRecoveryCode(ctx, "u1", totp.HashRecoveryCode("abcdefghij")).Return(nil)
+				mock.EXPECT().ListRoles(ctx, "u1").Return(nil, nil)
+				refresh.EXPECT().SaveRefresh(ctx, "u1", gomock.Any(), gomock.Any(), token.RefreshTTL()).Return(nil)
+				return NewAuth(mock, refresh, nil, nil, permissiveThrottle(ctrl), CredentialsPolicy{})
+			},
+		},
+		"access_token": { // обычный токен не заменяет pending
+			rq:  &models.MFAVerifyRequest{MFAToken: access, Code: code},
+			err: models.ErrInvalidToken,
+			init: func(t *_tc) *auth {
+				return NewAuth(nil, nil, nil, nil, nil, CredentialsPolicy{})
+			},
+		},
+		"locked": {
+			rq:  &models.MFAVerifyRequest{MFAToken: pending, Code: code},
+			err: models.ErrTooManyAttempts,
+			init: func(t *_tc) *auth {
+				throttle := NewMockloginThrottle(ctrl)
+
+				throttle.EXPECT().Check(ctx, "u1", "").
+					Return(&models.RetryAfterError{Err: models.ErrTooManyAttempts, After: time.Second})
+				return NewAuth(nil, nil, nil, nil, throttle, CredentialsPolicy{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			uc := tc.init(&tc)
+			uc.now = func() time.Time { return now.Add(tc.at) }
+
+			resp, err := uc.VerifyMFA(ctx, tc.rq)
+			require.ErrorIs(t, err, tc.err)
+			if tc.err == nil {
+				require.NotEmpty(t, resp.Token)
+				require.NotEmpty(t, resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func Test_EnrollMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1700000000, 0)
+	ctx := token.ContextWithClaims(context.Background(), &token.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "u1"}})
+	mock := NewMockauthRepo(ctrl)
+	uc := NewAuth(mock, nil, nil, nil, permissiveThrottle(ctrl), CredentialsPolicy{})
+	uc.now = func() time.Time { return now }
+
+	var secret string
+	mock.EXPECT().SaveTOTP(ctx, "u1", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, s string) error {
+		secret = s
+
+		return nil
+	})
+	enroll, err := uc.EnrollMFA(ctx)
+	require.NoError(t, err)
+	require.Equal(t, secret, enroll.Secret)
+	require.Contains(t, enroll.URI, "otpauth://totp/")
+
+	// неверный код не включает 2FA
+	mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: secret}, nil)
+	_, err = uc.ConfirmMFA(ctx, &models.MFACodeRequest{Code: "12345"})
+	require.ErrorIs(t, err, models.ErrInvalidOTP)
+
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+	var hashes []string
+	mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: secret}, nil)
+	mock.EXPECT().ConfirmTOTP(ctx, "u1", secret, totp.Step(now), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ string, _ int64, h []string) error {
+			hashes = h
+
+			return nil
+		})
+	codes, err := uc.ConfirmMFA(ctx, &models.MFACodeRequest{Code: code})
+	require.NoError(t, err)
+	require.Len(t, codes.Codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+	require.Equal(t, totp.HashRecoveryCode(codes.Codes[0]), hashes[0]) // в БД только хеши
+
+	// повторное подключение требует отключения
+	mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: secret, Confirmed: true}, nil)
+	_, err = uc.ConfirmMFA(ctx, &models.MFACodeRequest{Code: code})
+	require.ErrorIs(t, err, models.ErrMFAEnabled)
+
+	// отключение по коду восстановления
+	mock.EXPECT().GetTOTP(ctx, "u1").Return(&models.TOTP{Secret: secret, Confirmed: true, LastStep: totp.Step(now)}, nil)
+	mock.EXPECT().UseRecoveryCode(ctx, "u1", hashes[1]).Return(nil)
+	mock.EXPECT().DeleteTOTP(ctx, "u1").Return(nil)
+	require.NoError(t, uc.DisableMFA(ctx, &models.MFACodeRequest{Code: codes.Codes[1]}))
+}
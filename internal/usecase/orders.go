@@ -0,0 +1,175 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=orders.go -destination=orders_mocks.go *
+
+import (
+	"context"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type ordersRepo interface {
+	PlaceOrder(ctx context.Context, buyer string, lines []models.OrderLine, promo string) (*models.Order, error)
+	Checkout(ctx context.Context, buyer string, promo string) (*models.Order, error)
+	Gift(ctx context.Context, buyer string, recipient string, item string, message string, promo string) (*models.Order, error)
+	GetCart(ctx context.Context, login string) (*models.Cart, error)
+	AddToCart(ctx context.Context, login string, item string, qty int) error
+	RemoveFromCart(ctx context.Context, login string, item string) error
+	ListOrders(ctx context.Context, login string, status string) ([]models.Order, error)
+	SetOrderStatus(ctx context.Context, id int64, status string, from []string) (*models.Order, error)
+	CancelOrder(ctx context.Context, id int64, login string, from []string, since time.Time) (*models.Order, error)
+}
+
+type orders struct {
+	repo   ordersRepo
+	wishes wishes
+
+	cancelWindow time.Duration
+	now          func() time.Time
+}
+
+func NewOrders(repo ordersRepo, wishes wishes, cancelWindow time.Duration) *orders { //nolint:revive
+	return &orders{repo: repo, wishes: wishes, cancelWindow: cancelWindow, now: time.Now}
+}
+
+// PlaceOrder buys all lines or nothing.
+func (o *orders) PlaceOrder(ctx context.Context, user string, rq *models.OrderRequest) (*models.Order, error) {
+	order, err := o.repo.PlaceOrder(ctx, user, rq.Lines, promoCode(rq.Promo))
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	o.changed(ctx, order)
+
+	return order, nil
+}
+
+func (o *orders) Checkout(ctx context.Context, user string, promo string) (*models.Order, error) {
+	order, err := o.repo.Checkout(ctx, user, promoCode(promo))
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	o.changed(ctx, order)
+
+	return order, nil
+}
+
+// Gift is paid by user, the item goes to the recipient's inventory.
+func (o *orders) Gift(ctx context.Context, user string, rq *models.GiftRequest) (*models.Order, error) {
+	order, err := o.repo.Gift(ctx, user, rq.To, rq.Item, rq.Message, promoCode(rq.Promo))
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	o.changed(ctx, order)
+
+	return order, nil
+}
+
+func (o *orders) Cart(ctx context.Context, user string) (*models.Cart, error) {
+	cart, err := o.repo.GetCart(ctx, user)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return cart, nil
+}
+
+// AddToCart does not check stock: availability is checked at checkout.
+func (o *orders) AddToCart(ctx context.Context, user string, line *models.OrderLine) error {
+	if err := o.repo.AddToCart(ctx, user, line.Item, line.Quantity); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (o *orders) RemoveFromCart(ctx context.Context, user string, item string) error {
+	if err := o.repo.RemoveFromCart(ctx, user, item); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (o *orders) ListOrders(ctx context.Context, user string) ([]models.Order, error) {
+	return o.list(ctx, user, "")
+}
+
+// AllOrders is the office queue, status filter is optional.
+func (o *orders) AllOrders(ctx context.Context, status string) ([]models.Order, error) {
+	return o.list(ctx, "", status)
+}
+
+func (o *orders) list(ctx context.Context, user string, status string) ([]models.Order, error) {
+	list, err := o.repo.ListOrders(ctx, user, status)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if list == nil {
+		list = []models.Order{}
+	}
+
+	return list, nil
+}
+
+// SetStatus advances order along models.OrderTransitions.
+func (o *orders) SetStatus(ctx context.Context, id int64, status string) (*models.Order, error) {
+	from, ok := models.OrderTransitions[status]
+	if !ok {
+		return nil, models.ErrOrderStatus
+	}
+	order, err := o.repo.SetOrderStatus(ctx, id, status, from)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return order, nil
+}
+
+// Cancel refunds user's own order placed within the cancel window.
+func (o *orders) Cancel(ctx context.Context, user string, id int64) (*models.Order, error) {
+	return o.cancel(ctx, id, user, models.UserCancellable, o.now().Add(-o.cancelWindow))
+}
+
+// AdminCancel refunds any order not cancelled yet, regardless of its age.
+func (o *orders) AdminCancel(ctx context.Context, id int64) (*models.Order, error) {
+	return o.cancel(ctx, id, "", models.AdminCancellable, time.Time{})
+}
+
+func (o *orders) cancel(ctx context.Context, id int64, user string, from []string, since time.Time) (*models.Order, error) {
+	order, err := o.repo.CancelOrder(ctx, id, user, from, since)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	o.changed(ctx, order)
+
+	return order, nil
+}
+
+// changed reports buyer balance and stock of ordered items to wishlists.
+func (o *orders) changed(ctx context.Context, order *models.Order) {
+	items := make([]string, 0, len(order.Lines))
+	for _, l := range order.Lines {
+		items = append(items, l.Item)
+	}
+	o.wishes.Changed(ctx, []string{order.User}, items)
+}
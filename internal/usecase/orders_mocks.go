@@ -0,0 +1,176 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: orders.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=orders.go -destination=orders_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/cxbelka/winter_2025/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockordersRepo is a mock of ordersRepo interface.
+type MockordersRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockordersRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockordersRepoMockRecorder is the mock recorder for MockordersRepo.
+type MockordersRepoMockRecorder struct {
+	mock *MockordersRepo
+}
+
+// NewMockordersRepo creates a new mock instance.
+func NewMockordersRepo(ctrl *gomock.Controller) *MockordersRepo {
+	mock := &MockordersRepo{ctrl: ctrl}
+	mock.recorder = &MockordersRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockordersRepo) EXPECT() *MockordersRepoMockRecorder {
+	return m.recorder
+}
+
+// AddToCart mocks base method.
+func (m *MockordersRepo) AddToCart(ctx context.Context, login, item string, qty int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToCart", ctx, login, item, qty)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToCart indicates an expected call of AddToCart.
+func (mr *MockordersRepoMockRecorder) AddToCart(ctx, login, item, qty any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToCart", reflect.TypeOf((*MockordersRepo)(nil).AddToCart), ctx, login, item, qty)
+}
+
+// CancelOrder mocks base method.
+func (m *MockordersRepo) CancelOrder(ctx context.Context, id int64, login string, from []string, since time.Time) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, id, login, from, since)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockordersRepoMockRecorder) CancelOrder(ctx, id, login, from, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockordersRepo)(nil).CancelOrder), ctx, id, login, from, since)
+}
+
+// Checkout mocks base method.
+func (m *MockordersRepo) Checkout(ctx context.Context, buyer, promo string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, buyer, promo)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockordersRepoMockRecorder) Checkout(ctx, buyer, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockordersRepo)(nil).Checkout), ctx, buyer, promo)
+}
+
+// GetCart mocks base method.
+func (m *MockordersRepo) GetCart(ctx context.Context, login string) (*models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", ctx, login)
+	ret0, _ := ret[0].(*models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockordersRepoMockRecorder) GetCart(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockordersRepo)(nil).GetCart), ctx, login)
+}
+
+// Gift mocks base method.
+func (m *MockordersRepo) Gift(ctx context.Context, buyer, recipient, item, message, promo string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Gift", ctx, buyer, recipient, item, message, promo)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Gift indicates an expected call of Gift.
+func (mr *MockordersRepoMockRecorder) Gift(ctx, buyer, recipient, item, message, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gift", reflect.TypeOf((*MockordersRepo)(nil).Gift), ctx, buyer, recipient, item, message, promo)
+}
+
+// ListOrders mocks base method.
+func (m *MockordersRepo) ListOrders(ctx context.Context, login, status string) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, login, status)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockordersRepoMockRecorder) ListOrders(ctx, login, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockordersRepo)(nil).ListOrders), ctx, login, status)
+}
+
+// PlaceOrder mocks base method.
+func (m *MockordersRepo) PlaceOrder(ctx context.Context, buyer string, lines []models.OrderLine, promo string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceOrder", ctx, buyer, lines, promo)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceOrder indicates an expected call of PlaceOrder.
+func (mr *MockordersRepoMockRecorder) PlaceOrder(ctx, buyer, lines, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceOrder", reflect.TypeOf((*MockordersRepo)(nil).PlaceOrder), ctx, buyer, lines, promo)
+}
+
+// RemoveFromCart mocks base method.
+func (m *MockordersRepo) RemoveFromCart(ctx context.Context, login, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromCart", ctx, login, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromCart indicates an expected call of RemoveFromCart.
+func (mr *MockordersRepoMockRecorder) RemoveFromCart(ctx, login, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromCart", reflect.TypeOf((*MockordersRepo)(nil).RemoveFromCart), ctx, login, item)
+}
+
+// SetOrderStatus mocks base method.
+func (m *MockordersRepo) SetOrderStatus(ctx context.Context, id int64, status string, from []string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderStatus", ctx, id, status, from)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrderStatus indicates an expected call of SetOrderStatus.
+func (mr *MockordersRepoMockRecorder) SetOrderStatus(ctx, id, status, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderStatus", reflect.TypeOf((*MockordersRepo)(nil).SetOrderStatus), ctx, id, status, from)
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Orders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockordersRepo(ctrl)
+	wishes := NewMockwishes(ctrl)
+	uc := NewOrders(mock, wishes, time.Hour)
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	lines := []models.OrderLine{{Item: "socks", Quantity: 10}, {Item: "cup", Quantity: 1}}
+	order := &models.Order{
+		User:  "u1",
+		Lines: []models.OrderLine{{Item: "socks", Quantity: 10, Price: 10}, {Item: "cup", Quantity: 1, Price: 20}},
+		Total: 120,
+	}
+	mock.EXPECT().PlaceOrder(ctx, "u1", lines, "CUP10").Return(order, nil)
+	// оплата и списание остатков перепроверяют списки желаний
+	wishes.EXPECT().Changed(gomock.Any(), []string{"u1"}, []string{"socks", "cup"})
+	resp, err := uc.PlaceOrder(ctx, "u1", &models.OrderRequest{Lines: lines, Promo: "cup10"})
+	require.NoError(t, err)
+	require.Equal(t, order, resp)
+
+	// ошибка строки не теряется
+	lineErr := &models.LineError{Item: "cup", Err: models.ErrOutOfStock}
+	mock.EXPECT().PlaceOrder(ctx, "u1", lines, "").Return(nil, lineErr)
+	_, err = uc.PlaceOrder(ctx, "u1", &models.OrderRequest{Lines: lines})
+	require.ErrorIs(t, err, models.ErrOutOfStock)
+	require.ErrorAs(t, err, &lineErr)
+
+	// подарок: промокод нормализуется, получатель и сообщение передаются как есть
+	gift := &models.Order{User: "u1", Recipient: "u2", Message: "happy birthday", Total: 20}
+	mock.EXPECT().Gift(ctx, "u1", "u2", "cup", "happy birthday", "CUP10").Return(gift, nil)
+	wishes.EXPECT().Changed(gomock.Any(), []string{"u1"}, []string{})
+	resp, err = uc.Gift(ctx, "u1", &models.GiftRequest{To: "u2", Item: "cup", Message: "happy birthday", Promo: " cup10 "})
+	require.NoError(t, err)
+	require.Equal(t, gift, resp)
+
+	mock.EXPECT().Gift(ctx, "u1", "gone", "cup", "", "").Return(nil, models.ErrUserDeactivated)
+	_, err = uc.Gift(ctx, "u1", &models.GiftRequest{To: "gone", Item: "cup"})
+	require.ErrorIs(t, err, models.ErrUserDeactivated)
+
+	mock.EXPECT().AddToCart(ctx, "u1", "socks", 2).Return(nil)
+	require.NoError(t, uc.AddToCart(ctx, "u1", &models.OrderLine{Item: "socks", Quantity: 2}))
+
+	mock.EXPECT().GetCart(ctx, "u1").Return(&models.Cart{Lines: []models.OrderLine{}}, nil)
+	cart, err := uc.Cart(ctx, "u1")
+	require.NoError(t, err)
+	require.Empty(t, cart.Lines)
+
+	mock.EXPECT().RemoveFromCart(ctx, "u1", "cup").Return(models.ErrItemNotFound)
+	require.ErrorIs(t, uc.RemoveFromCart(ctx, "u1", "cup"), models.ErrItemNotFound)
+
+	mock.EXPECT().Checkout(ctx, "u1", "").Return(nil, models.ErrCartEmpty)
+	_, err = uc.Checkout(ctx, "u1", "")
+	require.ErrorIs(t, err, models.ErrCartEmpty)
+
+	// список пользователя никогда не null
+	mock.EXPECT().ListOrders(ctx, "u1", "").Return(nil, nil)
+	list, err := uc.ListOrders(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, []models.Order{}, list)
+
+	mock.EXPECT().ListOrders(ctx, "", models.OrderPlaced).Return([]models.Order{*order}, nil)
+	list, err = uc.AllOrders(ctx, models.OrderPlaced)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	mock.EXPECT().SetOrderStatus(ctx, int64(1), models.OrderDelivered, []string{models.OrderPlaced, models.OrderReady}).
+		Return(&models.Order{ID: 1, Status: models.OrderDelivered}, nil)
+	o, err := uc.SetStatus(ctx, 1, models.OrderDelivered)
+	require.NoError(t, err)
+	require.Equal(t, models.OrderDelivered, o.Status)
+
+	// в placed и cancelled обычной сменой статуса не попасть
+	_, err = uc.SetStatus(ctx, 1, models.OrderPlaced)
+	require.ErrorIs(t, err, models.ErrOrderStatus)
+	_, err = uc.SetStatus(ctx, 1, models.OrderCancelled)
+	require.ErrorIs(t, err, models.ErrOrderStatus)
+
+	// пользователь отменяет только свои недавние заказы до выдачи
+	mock.EXPECT().CancelOrder(ctx, int64(1), "u1", models.UserCancellable, now.Add(-time.Hour)).
+		Return(nil, models.ErrCancelExpired)
+	_, err = uc.Cancel(ctx, "u1", 1)
+	require.ErrorIs(t, err, models.ErrCancelExpired)
+
+	mock.EXPECT().CancelOrder(ctx, int64(1), "", models.AdminCancellable, time.Time{}).
+		Return(&models.Order{ID: 1, User: "u1", Status: models.OrderCancelled}, nil)
+	wishes.EXPECT().Changed(gomock.Any(), []string{"u1"}, []string{})
+	o, err = uc.AdminCancel(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, models.OrderCancelled, o.Status)
+}
@@ -0,0 +1,86 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=promos.go -destination=promos_mocks.go *
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type promosRepo interface {
+	ListPromos(ctx context.Context) ([]models.Promo, error)
+	CreatePromo(ctx context.Context, promo *models.Promo) error
+	DisablePromo(ctx context.Context, code string) error
+}
+
+type promos struct {
+	repo promosRepo
+
+	now func() time.Time
+}
+
+func NewPromos(repo promosRepo) *promos { //nolint:revive
+	return &promos{repo: repo, now: time.Now}
+}
+
+// promoCode normalizes user input: codes are case insensitive.
+func promoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p *promos) ListPromos(ctx context.Context) ([]models.Promo, error) {
+	list, err := p.repo.ListPromos(ctx)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if list == nil {
+		list = []models.Promo{}
+	}
+
+	return list, nil
+}
+
+// CreatePromo: omitted From means the code is valid right away.
+func (p *promos) CreatePromo(ctx context.Context, rq *models.CreatePromoRequest) (*models.Promo, error) {
+	promo := &models.Promo{
+		Code:           promoCode(rq.Code),
+		Kind:           rq.Kind,
+		Amount:         rq.Amount,
+		Items:          rq.Items,
+		From:           p.now(),
+		Until:          rq.Until,
+		MaxUses:        rq.MaxUses,
+		MaxUsesPerUser: rq.MaxUsesPerUser,
+	}
+	if rq.From != nil {
+		promo.From = *rq.From
+	}
+	if (promo.Kind == models.PromoPercent && promo.Amount > 100) ||
+		(promo.Until != nil && !promo.Until.After(promo.From)) {
+		return nil, models.ErrInvalidPromo
+	}
+
+	if err := p.repo.CreatePromo(ctx, promo); err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return promo, nil
+}
+
+func (p *promos) DisablePromo(ctx context.Context, code string) error {
+	if err := p.repo.DisablePromo(ctx, promoCode(code)); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
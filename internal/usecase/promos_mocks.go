@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: promos.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=promos.go -destination=promos_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/cxbelka/winter_2025/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockpromosRepo is a mock of promosRepo interface.
+type MockpromosRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockpromosRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockpromosRepoMockRecorder is the mock recorder for MockpromosRepo.
+type MockpromosRepoMockRecorder struct {
+	mock *MockpromosRepo
+}
+
+// NewMockpromosRepo creates a new mock instance.
+func NewMockpromosRepo(ctrl *gomock.Controller) *MockpromosRepo {
+	mock := &MockpromosRepo{ctrl: ctrl}
+	mock.recorder = &MockpromosRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpromosRepo) EXPECT() *MockpromosRepoMockRecorder {
+	return m.recorder
+}
+
+// CreatePromo mocks base method.
+func (m *MockpromosRepo) CreatePromo(ctx context.Context, promo *models.Promo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromo", ctx, promo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePromo indicates an expected call of CreatePromo.
+func (mr *MockpromosRepoMockRecorder) CreatePromo(ctx, promo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromo", reflect.TypeOf((*MockpromosRepo)(nil).CreatePromo), ctx, promo)
+}
+
+// DisablePromo mocks base method.
+func (m *MockpromosRepo) DisablePromo(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisablePromo", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisablePromo indicates an expected call of DisablePromo.
+func (mr *MockpromosRepoMockRecorder) DisablePromo(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisablePromo", reflect.TypeOf((*MockpromosRepo)(nil).DisablePromo), ctx, code)
+}
+
+// ListPromos mocks base method.
+func (m *MockpromosRepo) ListPromos(ctx context.Context) ([]models.Promo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromos", ctx)
+	ret0, _ := ret[0].([]models.Promo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromos indicates an expected call of ListPromos.
+func (mr *MockpromosRepoMockRecorder) ListPromos(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromos", reflect.TypeOf((*MockpromosRepo)(nil).ListPromos), ctx)
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_CreatePromo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	week := now.Add(7 * 24 * time.Hour)
+
+	testCases := map[string]struct {
+		rq  *models.CreatePromoRequest
+		err error
+
+		init func(*MockpromosRepo)
+	}{
+		"percent": {
+			rq: &models.CreatePromoRequest{Code: "hoody20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, Until: &week},
+			init: func(m *MockpromosRepo) {
+				m.EXPECT().CreatePromo(ctx, &models.Promo{
+					Code: "HOODY20", Kind: models.PromoPercent, Amount: 20, Items: []string{"hoody"}, From: now, Until: &week,
+				}).Return(nil)
+			},
+		},
+		"percent_over_100": {
+			rq:  &models.CreatePromoRequest{Code: "free", Kind: models.PromoPercent, Amount: 120},
+			err: models.ErrInvalidPromo,
+		},
+		"fixed_over_100": {
+			rq: &models.CreatePromoRequest{Code: "big", Kind: models.PromoFixed, Amount: 120},
+			init: func(m *MockpromosRepo) {
+				m.EXPECT().CreatePromo(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		"ends_before_start": {
+			rq:  &models.CreatePromoRequest{Code: "x", Kind: models.PromoFixed, Amount: 1, From: &week, Until: &now},
+			err: models.ErrInvalidPromo,
+		},
+		"exists": {
+			rq:  &models.CreatePromoRequest{Code: "x", Kind: models.PromoFixed, Amount: 1},
+			err: models.ErrPromoExists,
+			init: func(m *MockpromosRepo) {
+				m.EXPECT().CreatePromo(ctx, gomock.Any()).Return(models.ErrPromoExists)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockpromosRepo(ctrl)
+			if tc.init != nil {
+				tc.init(mock)
+			}
+			uc := NewPromos(mock)
+			uc.now = func() time.Time { return now }
+
+			_, err := uc.CreatePromo(ctx, tc.rq)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
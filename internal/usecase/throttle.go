@@ -0,0 +1,103 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=throttle.go -destination=throttle_mocks.go *
+
+import (
+	"context"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+const maxBackoffShift = 30
+
+type throttleRepo interface {
+	LockedFor(ctx context.Context, keys []string) (time.Duration, error)
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LockoutPolicy: after Threshold failures within Window the key is locked for
+// Backoff, every next failure doubles the lock up to Max.
+type LockoutPolicy struct {
+	LoginThreshold int
+	IPThreshold    int
+	Backoff        time.Duration
+	Max            time.Duration
+	Window         time.Duration
+}
+
+type throttle struct {
+	repo   throttleRepo
+	policy LockoutPolicy
+}
+
+func NewThrottle(repo throttleRepo, policy LockoutPolicy) *throttle { //nolint:revive
+	return &throttle{repo: repo, policy: policy}
+}
+
+// Check returns models.RetryAfterError if login or client address is locked.
+func (t *throttle) Check(ctx context.Context, login string, ip string) error {
+	wait, err := t.repo.LockedFor(ctx, attemptKeys(login, ip))
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if wait > 0 {
+		return &models.RetryAfterError{Err: models.ErrTooManyAttempts, After: wait}
+	}
+
+	return nil
+}
+
+// Fail registers failed attempt for login and client address. Errors are only logged:
+// a broken counter must not turn wrong password into internal error.
+func (t *throttle) Fail(ctx context.Context, login string, ip string) {
+	thresholds := []int{t.policy.LoginThreshold, t.policy.IPThreshold}
+	for i, key := range attemptKeys(login, ip) {
+		failures, err := t.repo.RegisterFailure(ctx, key, t.policy.Window)
+		if err == nil {
+			if d := t.lockFor(failures, thresholds[i]); d > 0 {
+				err = t.repo.Lock(ctx, key, d)
+			}
+		}
+		if err != nil {
+			logger.AddField(ctx, "throttle_error", err.Error())
+		}
+	}
+}
+
+// Reset forgets login failures after successful login. Address counter is kept
+// so that one valid account can't be used to reset it during credential stuffing.
+func (t *throttle) Reset(ctx context.Context, login string) {
+	if err := t.repo.Reset(ctx, loginKey(login)); err != nil {
+		logger.AddField(ctx, "throttle_error", err.Error())
+	}
+}
+
+func (t *throttle) lockFor(failures int, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	shift := min(failures-threshold, maxBackoffShift)
+	d := t.policy.Backoff << shift
+	if d > t.policy.Max || d <= 0 {
+		d = t.policy.Max
+	}
+
+	return d
+}
+
+func loginKey(login string) string {
+	return "login:" + login
+}
+
+func attemptKeys(login string, ip string) []string {
+	keys := []string{loginKey(login)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+
+	return keys
+}
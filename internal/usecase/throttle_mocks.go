@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: throttle.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=throttle.go -destination=throttle_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockthrottleRepo is a mock of throttleRepo interface.
+type MockthrottleRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockthrottleRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockthrottleRepoMockRecorder is the mock recorder for MockthrottleRepo.
+type MockthrottleRepoMockRecorder struct {
+	mock *MockthrottleRepo
+}
+
+// NewMockthrottleRepo creates a new mock instance.
+func NewMockthrottleRepo(ctrl *gomock.Controller) *MockthrottleRepo {
+	mock := &MockthrottleRepo{ctrl: ctrl}
+	mock.recorder = &MockthrottleRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockthrottleRepo) EXPECT() *MockthrottleRepoMockRecorder {
+	return m.recorder
+}
+
+// Lock mocks base method.
+func (m *MockthrottleRepo) Lock(ctx context.Context, key string, d time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockthrottleRepoMockRecorder) Lock(ctx, key, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockthrottleRepo)(nil).Lock), ctx, key, d)
+}
+
+// LockedFor mocks base method.
+func (m *MockthrottleRepo) LockedFor(ctx context.Context, keys []string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockedFor", ctx, keys)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockedFor indicates an expected call of LockedFor.
+func (mr *MockthrottleRepoMockRecorder) LockedFor(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockedFor", reflect.TypeOf((*MockthrottleRepo)(nil).LockedFor), ctx, keys)
+}
+
+// RegisterFailure mocks base method.
+func (m *MockthrottleRepo) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailure", ctx, key, window)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterFailure indicates an expected call of RegisterFailure.
+func (mr *MockthrottleRepoMockRecorder) RegisterFailure(ctx, key, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailure", reflect.TypeOf((*MockthrottleRepo)(nil).RegisterFailure), ctx, key, window)
+}
+
+// Reset mocks base method.
+func (m *MockthrottleRepo) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockthrottleRepoMockRecorder) Reset(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockthrottleRepo)(nil).Reset), ctx, key)
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testPolicy = LockoutPolicy{
+	LoginThreshold: 3,
+	IPThreshold:    10,
+	Backoff:        time.Second,
+	Max:            time.Minute,
+	Window:         15 * time.Minute,
+}
+
+func Test_ThrottleCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		login string
+		ip    string
+		keys  []string
+		wait  time.Duration
+		dbErr error
+		err   error
+	}
+	testCases := map[string]_tc{
+		"free": {
+			login: "u1", ip: "10.0.0.1",
+			keys: []string{"login:u1", "ip:10.0.0.1"},
+		},
+		"locked": {
+			login: "u1", ip: "10.0.0.1",
+			keys: []string{"login:u1", "ip:10.0.0.1"},
+			wait: time.Minute,
+			err:  models.ErrTooManyAttempts,
+		},
+		"no_ip": {
+			login: "u1",
+			keys:  []string{"login:u1"},
+		},
+		"db_issue": {
+			login: "u1",
+			keys:  []string{"login:u1"},
+			dbErr: models.ErrGeneric,
+			err:   models.ErrGeneric,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			repo := NewMockthrottleRepo(ctrl)
+			repo.EXPECT().LockedFor(ctx, tc.keys).Return(tc.wait, tc.dbErr)
+
+			err := NewThrottle(repo, testPolicy).Check(ctx, tc.login, tc.ip)
+			require.ErrorIs(t, err, tc.err)
+
+			var retry *models.RetryAfterError
+			if errors.As(err, &retry) {
+				require.Equal(t, tc.wait, retry.After)
+			}
+		})
+	}
+}
+
+func Test_ThrottleFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	type _tc struct {
+		loginFailures int
+		ipFailures    int
+		init          func(*MockthrottleRepo)
+	}
+	testCases := map[string]_tc{
+		"below_threshold": {
+			loginFailures: 2,
+			ipFailures:    2,
+		},
+		"first_lock": {
+			loginFailures: 3,
+			ipFailures:    3,
+			init: func(repo *MockthrottleRepo) {
+				repo.EXPECT().Lock(ctx, "login:u1", time.Second).Return(nil)
+			},
+		},
+		"backoff_doubles": {
+			loginFailures: 5,
+			ipFailures:    5,
+			init: func(repo *MockthrottleRepo) {
+				repo.EXPECT().Lock(ctx, "login:u1", 4*time.Second).Return(nil)
+			},
+		},
+		"backoff_capped": {
+			loginFailures: 100,
+			ipFailures:    100,
+			init: func(repo *MockthrottleRepo) {
+				repo.EXPECT().Lock(ctx, "login:u1", time.Minute).Return(nil)
+				repo.EXPECT().Lock(ctx, "ip:10.0.0.1", time.Minute).Return(nil)
+			},
+		},
+		"ip_lock": { // перебор логинов с одного адреса
+			loginFailures: 1,
+			ipFailures:    10,
+			init: func(repo *MockthrottleRepo) {
+				repo.EXPECT().Lock(ctx, "ip:10.0.0.1", time.Second).Return(nil)
+			},
+		},
+		"lock_issue": { // ошибка только логируется
+			loginFailures: 3,
+			ipFailures:    1,
+			init: func(repo *MockthrottleRepo) {
+				repo.EXPECT().Lock(ctx, "login:u1", time.Second).Return(models.ErrGeneric)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			repo := NewMockthrottleRepo(ctrl)
+			repo.EXPECT().RegisterFailure(ctx, "login:u1", testPolicy.Window).Return(tc.loginFailures, nil)
+			repo.EXPECT().RegisterFailure(ctx, "ip:10.0.0.1", testPolicy.Window).Return(tc.ipFailures, nil)
+			if tc.init != nil {
+				tc.init(repo)
+			}
+
+			NewThrottle(repo, testPolicy).Fail(ctx, "u1", "10.0.0.1")
+		})
+	}
+}
+
+func Test_ThrottleReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	repo := NewMockthrottleRepo(ctrl)
+	repo.EXPECT().Reset(ctx, "login:u1").Return(nil)
+
+	NewThrottle(repo, testPolicy).Reset(ctx, "u1")
+}
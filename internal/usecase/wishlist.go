@@ -0,0 +1,135 @@
+package usecase
+
+//go:generate mockgen -package usecase -source=wishlist.go -destination=wishlist_mocks.go *
+
+import (
+	"context"
+
+	"github.com/cxbelka/winter_2025/internal/logger"
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+type wishlistRepo interface {
+	ListWishlist(ctx context.Context, login string) ([]models.WishlistItem, error)
+	AddToWishlist(ctx context.Context, login string, item string) error
+	RemoveFromWishlist(ctx context.Context, login string, item string) error
+	EvaluateWishlist(ctx context.Context, logins []string, items []string) (int, error)
+	EvaluateAllWishlists(ctx context.Context) (int, error)
+	ListNotifications(ctx context.Context, login string) ([]models.Notification, error)
+	ReadNotifications(ctx context.Context, login string) error
+}
+
+// wishes is notified by operations that change balances or stock.
+type wishes interface {
+	Changed(ctx context.Context, logins []string, items []string)
+}
+
+// wishChange is a pending evaluation: wishlists of the users and wishlists containing the items.
+type wishChange struct {
+	logins []string
+	items  []string
+}
+
+type wishlist struct {
+	repo wishlistRepo
+
+	queue chan wishChange
+}
+
+// NewWishlist: queue is the number of changes waiting for evaluation,
+// further changes are dropped until the evaluator catches up.
+func NewWishlist(repo wishlistRepo, queue int) *wishlist { //nolint:revive
+	return &wishlist{repo: repo, queue: make(chan wishChange, queue)}
+}
+
+func (w *wishlist) List(ctx context.Context, user string) ([]models.WishlistItem, error) {
+	list, err := w.repo.ListWishlist(ctx, user)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if list == nil {
+		list = []models.WishlistItem{}
+	}
+
+	return list, nil
+}
+
+func (w *wishlist) Add(ctx context.Context, user string, item string) error {
+	if err := w.repo.AddToWishlist(ctx, user, item); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (w *wishlist) Remove(ctx context.Context, user string, item string) error {
+	if err := w.repo.RemoveFromWishlist(ctx, user, item); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (w *wishlist) Notifications(ctx context.Context, user string) ([]models.Notification, error) {
+	list, err := w.repo.ListNotifications(ctx, user)
+	if err != nil {
+		logger.AddError(ctx, err)
+
+		return nil, err //nolint:wrapcheck
+	}
+	if list == nil {
+		list = []models.Notification{}
+	}
+
+	return list, nil
+}
+
+func (w *wishlist) ReadNotifications(ctx context.Context, user string) error {
+	if err := w.repo.ReadNotifications(ctx, user); err != nil {
+		logger.AddError(ctx, err)
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// Changed queues evaluation without blocking the operation. When the queue is full
+// the change is dropped and the notification waits for the next Sweep: states are
+// compared with the last evaluated ones, so nothing is lost, only delayed.
+// The change is queued after commit, the evaluator must see the new balance and stock.
+func (w *wishlist) Changed(ctx context.Context, logins []string, items []string) {
+	onCommit(ctx, func() {
+		select {
+		case w.queue <- wishChange{logins: logins, items: items}:
+		default:
+		}
+	})
+}
+
+// Evaluate waits for the next change and creates notifications for it.
+// Scheduled price changes have no event and are picked up by Sweep.
+func (w *wishlist) Evaluate(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case c := <-w.queue:
+		_, err := w.repo.EvaluateWishlist(ctx, c.logins, c.items)
+
+		return err //nolint:wrapcheck
+	}
+}
+
+// Sweep evaluates all wishlists, it is run periodically to catch up changes
+// that were dropped or never queued.
+func (w *wishlist) Sweep(ctx context.Context) error {
+	_, err := w.repo.EvaluateAllWishlists(ctx)
+
+	return err //nolint:wrapcheck
+}
@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: wishlist.go
+//
+// Generated by this command:
+//
+//	mockgen -package usecase -source=wishlist.go -destination=wishlist_mocks.go *
+//
+
+// Package usecase is a generated GoMock package.
+package usecase
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/cxbelka/winter_2025/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockwishlistRepo is a mock of wishlistRepo interface.
+type MockwishlistRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockwishlistRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockwishlistRepoMockRecorder is the mock recorder for MockwishlistRepo.
+type MockwishlistRepoMockRecorder struct {
+	mock *MockwishlistRepo
+}
+
+// NewMockwishlistRepo creates a new mock instance.
+func NewMockwishlistRepo(ctrl *gomock.Controller) *MockwishlistRepo {
+	mock := &MockwishlistRepo{ctrl: ctrl}
+	mock.recorder = &MockwishlistRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockwishlistRepo) EXPECT() *MockwishlistRepoMockRecorder {
+	return m.recorder
+}
+
+// AddToWishlist mocks base method.
+func (m *MockwishlistRepo) AddToWishlist(ctx context.Context, login, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToWishlist", ctx, login, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToWishlist indicates an expected call of AddToWishlist.
+func (mr *MockwishlistRepoMockRecorder) AddToWishlist(ctx, login, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToWishlist", reflect.TypeOf((*MockwishlistRepo)(nil).AddToWishlist), ctx, login, item)
+}
+
+// EvaluateAllWishlists mocks base method.
+func (m *MockwishlistRepo) EvaluateAllWishlists(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvaluateAllWishlists", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvaluateAllWishlists indicates an expected call of EvaluateAllWishlists.
+func (mr *MockwishlistRepoMockRecorder) EvaluateAllWishlists(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvaluateAllWishlists", reflect.TypeOf((*MockwishlistRepo)(nil).EvaluateAllWishlists), ctx)
+}
+
+// EvaluateWishlist mocks base method.
+func (m *MockwishlistRepo) EvaluateWishlist(ctx context.Context, logins, items []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvaluateWishlist", ctx, logins, items)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvaluateWishlist indicates an expected call of EvaluateWishlist.
+func (mr *MockwishlistRepoMockRecorder) EvaluateWishlist(ctx, logins, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvaluateWishlist", reflect.TypeOf((*MockwishlistRepo)(nil).EvaluateWishlist), ctx, logins, items)
+}
+
+// ListNotifications mocks base method.
+func (m *MockwishlistRepo) ListNotifications(ctx context.Context, login string) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, login)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockwishlistRepoMockRecorder) ListNotifications(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockwishlistRepo)(nil).ListNotifications), ctx, login)
+}
+
+// ListWishlist mocks base method.
+func (m *MockwishlistRepo) ListWishlist(ctx context.Context, login string) ([]models.WishlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWishlist", ctx, login)
+	ret0, _ := ret[0].([]models.WishlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWishlist indicates an expected call of ListWishlist.
+func (mr *MockwishlistRepoMockRecorder) ListWishlist(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWishlist", reflect.TypeOf((*MockwishlistRepo)(nil).ListWishlist), ctx, login)
+}
+
+// ReadNotifications mocks base method.
+func (m *MockwishlistRepo) ReadNotifications(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadNotifications", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadNotifications indicates an expected call of ReadNotifications.
+func (mr *MockwishlistRepoMockRecorder) ReadNotifications(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadNotifications", reflect.TypeOf((*MockwishlistRepo)(nil).ReadNotifications), ctx, login)
+}
+
+// RemoveFromWishlist mocks base method.
+func (m *MockwishlistRepo) RemoveFromWishlist(ctx context.Context, login, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromWishlist", ctx, login, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromWishlist indicates an expected call of RemoveFromWishlist.
+func (mr *MockwishlistRepoMockRecorder) RemoveFromWishlist(ctx, login, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromWishlist", reflect.TypeOf((*MockwishlistRepo)(nil).RemoveFromWishlist), ctx, login, item)
+}
+
+// Mockwishes is a mock of wishes interface.
+type Mockwishes struct {
+	ctrl     *gomock.Controller
+	recorder *MockwishesMockRecorder
+	isgomock struct{}
+}
+
+// MockwishesMockRecorder is the mock recorder for Mockwishes.
+type MockwishesMockRecorder struct {
+	mock *Mockwishes
+}
+
+// NewMockwishes creates a new mock instance.
+func NewMockwishes(ctrl *gomock.Controller) *Mockwishes {
+	mock := &Mockwishes{ctrl: ctrl}
+	mock.recorder = &MockwishesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockwishes) EXPECT() *MockwishesMockRecorder {
+	return m.recorder
+}
+
+// Changed mocks base method.
+func (m *Mockwishes) Changed(ctx context.Context, logins, items []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Changed", ctx, logins, items)
+}
+
+// Changed indicates an expected call of Changed.
+func (mr *MockwishesMockRecorder) Changed(ctx, logins, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changed", reflect.TypeOf((*Mockwishes)(nil).Changed), ctx, logins, items)
+}
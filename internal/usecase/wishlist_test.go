@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cxbelka/winter_2025/internal/models"
+)
+
+func Test_Wishlist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := NewMockwishlistRepo(ctrl)
+	uc := NewWishlist(mock, 1)
+
+	mock.EXPECT().ListWishlist(ctx, "u1").Return(nil, nil)
+	list, err := uc.List(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, []models.WishlistItem{}, list)
+
+	mock.EXPECT().AddToWishlist(ctx, "u1", "unicorn").Return(models.ErrItemNotFound)
+	require.ErrorIs(t, uc.Add(ctx, "u1", "unicorn"), models.ErrItemNotFound)
+
+	mock.EXPECT().ListNotifications(ctx, "u1").Return(nil, nil)
+	notes, err := uc.Notifications(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, []models.Notification{}, notes)
+
+	// изменение не блокирует операцию: при полной очереди оно отбрасывается
+	uc.Changed(ctx, []string{"u1", "u2"}, nil)
+	uc.Changed(ctx, nil, []string{"cup"})
+
+	mock.EXPECT().EvaluateWishlist(ctx, []string{"u1", "u2"}, nil).Return(1, nil)
+	require.NoError(t, uc.Evaluate(ctx))
+
+	mock.EXPECT().EvaluateWishlist(ctx, nil, []string{"cup"}).Return(0, models.ErrGeneric)
+	uc.Changed(ctx, nil, []string{"cup"})
+	require.ErrorIs(t, uc.Evaluate(ctx), models.ErrGeneric)
+
+	// полная проверка подхватывает отброшенные изменения и плановые цены
+	mock.EXPECT().EvaluateAllWishlists(ctx).Return(2, nil)
+	require.NoError(t, uc.Sweep(ctx))
+
+	mock.EXPECT().EvaluateAllWishlists(ctx).Return(0, models.ErrGeneric)
+	require.ErrorIs(t, uc.Sweep(ctx), models.ErrGeneric)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, uc.Evaluate(cancelled), context.Canceled)
+}
@@ -0,0 +1,12 @@
+-- пароли хранятся в самоописываемом формате `$<алгоритм>$<параметры>$<соль>$<хеш>`,
+-- старые несолёные SHA512 переводятся в `$sha512$<hex>` и перехешируются при следующем входе
+DO $migration$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = 'merch_shop' AND table_name = 'auth' AND column_name = 'password') = 'bytea'
+    THEN
+        ALTER TABLE merch_shop.auth
+            ALTER COLUMN password TYPE text USING '$sha512$' || encode(password, 'hex');
+    END IF;
+END
+$migration$;
@@ -0,0 +1,12 @@
+-- хранится только sha256 от выданного токена
+CREATE TABLE IF NOT EXISTS merch_shop.refresh_tokens (
+    hash text PRIMARY KEY,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    family uuid NOT NULL, -- цепочка ротаций от одного логина
+    created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp DEFAULT NULL, -- обменян на новый
+    revoked_at timestamp DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_refresh_tokens_login
+    ON merch_shop.refresh_tokens USING hash (login);
@@ -0,0 +1,13 @@
+-- отозванные access токены, строки бесполезны после expires_at и удаляются при синхронизации
+CREATE TABLE IF NOT EXISTS merch_shop.revoked_tokens (
+    jti uuid PRIMARY KEY,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+-- все токены пользователя выпущенные до revoked_at недействительны
+CREATE TABLE IF NOT EXISTS merch_shop.revoked_sessions (
+    login text PRIMARY KEY REFERENCES merch_shop.auth (login),
+    revoked_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL
+);
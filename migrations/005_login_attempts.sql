@@ -0,0 +1,7 @@
+-- неудачные попытки входа по логину (login:<name>) и по адресу клиента (ip:<addr>)
+CREATE TABLE IF NOT EXISTS merch_shop.login_attempts (
+    key text PRIMARY KEY,
+    failures integer NOT NULL,
+    last_failure timestamptz NOT NULL,
+    locked_until timestamptz DEFAULT NULL
+);
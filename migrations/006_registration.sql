@@ -0,0 +1,16 @@
+-- одноразовые коды приглашения, хранится sha256 кода:
+-- INSERT INTO merch_shop.invites (code_hash) VALUES (encode(sha256(convert_to('<код>', 'UTF8')), 'hex'));
+CREATE TABLE IF NOT EXISTS merch_shop.invites (
+    code_hash text PRIMARY KEY,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at timestamptz DEFAULT NULL,
+    used_by text DEFAULT NULL,
+    used_at timestamptz DEFAULT NULL
+);
+
+-- логины сотрудников из HR, которым разрешена регистрация:
+-- COPY merch_shop.registration_allowlist (login) FROM '/path/to/hr.csv' WITH (FORMAT csv);
+CREATE TABLE IF NOT EXISTS merch_shop.registration_allowlist (
+    login text PRIMARY KEY,
+    created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
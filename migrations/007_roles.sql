@@ -0,0 +1,7 @@
+-- роли пользователей, попадают в JWT при выдаче токена
+CREATE TABLE IF NOT EXISTS merch_shop.user_roles (
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    role text CONSTRAINT known_role CHECK (role IN ('admin', 'manager')) NOT NULL,
+    granted_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (login, role)
+);
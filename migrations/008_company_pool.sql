@@ -0,0 +1,4 @@
+-- служебный аккаунт компании: сюда возвращаются монеты деактивированных пользователей.
+-- логин не проходит валидацию alphanum, войти под ним или перевести на него нельзя.
+INSERT INTO merch_shop.auth (login, password, balance) VALUES ('company-pool', '!', 0)
+    ON CONFLICT (login) DO NOTHING;
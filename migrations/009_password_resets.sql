@@ -0,0 +1,10 @@
+-- одноразовые коды сброса пароля, выдаются администратором. хранится только sha256 кода
+CREATE TABLE IF NOT EXISTS merch_shop.password_resets (
+    code_hash text PRIMARY KEY,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_password_resets_login
+    ON merch_shop.password_resets USING hash (login);
@@ -0,0 +1,17 @@
+-- TOTP второй фактор. секрет нужен для вычисления кода, поэтому хранится как есть.
+-- last_step - последний принятый шаг времени, повторно тот же код не принимается
+CREATE TABLE IF NOT EXISTS merch_shop.totp_secrets (
+    login text PRIMARY KEY REFERENCES merch_shop.auth (login),
+    secret text NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    confirmed_at timestamptz DEFAULT NULL,
+    last_step bigint DEFAULT 0 NOT NULL
+);
+
+-- одноразовые коды восстановления, хранится только sha256
+CREATE TABLE IF NOT EXISTS merch_shop.mfa_recovery_codes (
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    code_hash text NOT NULL,
+    used_at timestamptz DEFAULT NULL,
+    PRIMARY KEY (login, code_hash)
+);
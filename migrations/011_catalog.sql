@@ -0,0 +1,6 @@
+-- каталог редактируется через API: цена обязательна и неотрицательна,
+-- снятый с продажи товар помечается deleted_at и остаётся в истории покупок
+UPDATE merch_shop.items SET price = 0 WHERE price IS NULL;
+ALTER TABLE merch_shop.items ALTER COLUMN price SET NOT NULL;
+ALTER TABLE merch_shop.items DROP CONSTRAINT IF EXISTS non_negative_price;
+ALTER TABLE merch_shop.items ADD CONSTRAINT non_negative_price CHECK (price >= 0);
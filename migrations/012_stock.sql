@@ -0,0 +1,11 @@
+-- ограниченные партии: stock - остаток, max_per_user - сколько штук можно купить одному пользователю.
+-- NULL означает без ограничений
+ALTER TABLE merch_shop.items ADD COLUMN IF NOT EXISTS stock integer DEFAULT NULL;
+ALTER TABLE merch_shop.items ADD COLUMN IF NOT EXISTS max_per_user integer DEFAULT NULL;
+ALTER TABLE merch_shop.items DROP CONSTRAINT IF EXISTS non_negative_stock;
+ALTER TABLE merch_shop.items ADD CONSTRAINT non_negative_stock CHECK (stock >= 0);
+ALTER TABLE merch_shop.items DROP CONSTRAINT IF EXISTS positive_max_per_user;
+ALTER TABLE merch_shop.items ADD CONSTRAINT positive_max_per_user CHECK (max_per_user > 0);
+
+CREATE INDEX IF NOT EXISTS idx_merch_shop_purchases_user_item
+    ON merch_shop.purchases USING btree (name, item);
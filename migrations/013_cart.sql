@@ -0,0 +1,8 @@
+-- корзина пользователя, при оформлении превращается в заказ целиком или не оформляется
+CREATE TABLE IF NOT EXISTS merch_shop.carts (
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    item text REFERENCES merch_shop.items (name) NOT NULL,
+    quantity integer CONSTRAINT positive_quantity CHECK (quantity > 0) NOT NULL,
+    added_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (login, item)
+);
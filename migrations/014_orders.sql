@@ -0,0 +1,18 @@
+-- заказы: товар физический и выдаётся офисом. покупки до появления заказов остаются без order_id
+CREATE TABLE IF NOT EXISTS merch_shop.orders (
+    id bigserial PRIMARY KEY,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    status text CONSTRAINT known_status
+        CHECK (status IN ('placed', 'ready', 'delivered', 'cancelled')) DEFAULT 'placed' NOT NULL,
+    total integer DEFAULT 0 NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_orders_login
+    ON merch_shop.orders USING btree (login, created_at);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_orders_status
+    ON merch_shop.orders USING btree (status, created_at);
+
+ALTER TABLE merch_shop.purchases ADD COLUMN IF NOT EXISTS order_id bigint REFERENCES merch_shop.orders (id);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_purchases_order
+    ON merch_shop.purchases USING btree (order_id);
@@ -0,0 +1,2 @@
+-- возврат: покупка остаётся в истории заказа, но не попадает в инвентарь и лимиты
+ALTER TABLE merch_shop.purchases ADD COLUMN IF NOT EXISTS refunded_at timestamptz;
@@ -0,0 +1,33 @@
+-- история цен. запись без valid_to действует до следующей такой же,
+-- запись с valid_to - распродажа поверх обычной цены на этот период
+CREATE TABLE IF NOT EXISTS merch_shop.item_prices (
+    id bigserial PRIMARY KEY,
+    item text REFERENCES merch_shop.items (name) NOT NULL,
+    price integer CONSTRAINT non_negative_item_price CHECK (price >= 0) NOT NULL,
+    valid_from timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    valid_to timestamptz,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT valid_period CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_item_prices_item
+    ON merch_shop.item_prices USING btree (item, valid_from);
+
+-- товары, заведённые до появления истории
+INSERT INTO merch_shop.item_prices (item, price)
+    SELECT i.name, i.price FROM merch_shop.items AS i
+    WHERE NOT EXISTS (SELECT 1 FROM merch_shop.item_prices AS p WHERE p.item = i.name);
+
+-- цена на момент at: действующая распродажа, иначе последняя вступившая в силу цена,
+-- иначе items.price
+CREATE OR REPLACE FUNCTION merch_shop.item_price(item_name text, at timestamptz) RETURNS integer
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(
+        (SELECT p.price FROM merch_shop.item_prices AS p
+            WHERE p.item = item_name AND p.valid_from <= at AND p.valid_to > at
+            ORDER BY p.valid_from DESC, p.id DESC LIMIT 1),
+        (SELECT p.price FROM merch_shop.item_prices AS p
+            WHERE p.item = item_name AND p.valid_from <= at AND p.valid_to IS NULL
+            ORDER BY p.valid_from DESC, p.id DESC LIMIT 1),
+        (SELECT i.price FROM merch_shop.items AS i WHERE i.name = item_name)
+    )
+$$;
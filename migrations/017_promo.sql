@@ -0,0 +1,29 @@
+-- промокоды: percent - скидка в процентах, fixed - в монетах с каждой штуки (не больше цены).
+-- items NULL - на все товары. лимиты считаются по применениям к заказам, отмена заказа возвращает применение
+CREATE TABLE IF NOT EXISTS merch_shop.promo_codes (
+    code text PRIMARY KEY,
+    kind text CONSTRAINT known_kind CHECK (kind IN ('percent', 'fixed')) NOT NULL,
+    amount integer NOT NULL,
+    items text[],
+    valid_from timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    valid_to timestamptz,
+    max_uses integer CONSTRAINT positive_max_uses CHECK (max_uses > 0),
+    max_uses_per_user integer CONSTRAINT positive_max_uses_per_user CHECK (max_uses_per_user > 0),
+    disabled_at timestamptz,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT valid_amount CHECK (amount > 0 AND (kind <> 'percent' OR amount <= 100)),
+    CONSTRAINT valid_promo_period CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE TABLE IF NOT EXISTS merch_shop.promo_redemptions (
+    order_id bigint PRIMARY KEY REFERENCES merch_shop.orders (id),
+    code text REFERENCES merch_shop.promo_codes (code) NOT NULL,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_promo_redemptions_code
+    ON merch_shop.promo_redemptions USING btree (code, login);
+
+-- sum - фактически списано за штуку, discount - скидка с этой штуки
+ALTER TABLE merch_shop.purchases ADD COLUMN IF NOT EXISTS promo text REFERENCES merch_shop.promo_codes (code);
+ALTER TABLE merch_shop.purchases ADD COLUMN IF NOT EXISTS discount integer DEFAULT 0 NOT NULL;
@@ -0,0 +1,7 @@
+-- подарок: заказ оплачивает login, покупки записываются на recipient и попадают в его инвентарь
+ALTER TABLE merch_shop.orders ADD COLUMN IF NOT EXISTS recipient text REFERENCES merch_shop.auth (login);
+ALTER TABLE merch_shop.orders ADD COLUMN IF NOT EXISTS message text;
+CREATE INDEX IF NOT EXISTS idx_merch_shop_orders_recipient
+    ON merch_shop.orders USING btree (recipient, created_at) WHERE recipient IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_merch_shop_orders_gifts_sent
+    ON merch_shop.orders USING btree (login, created_at) WHERE recipient IS NOT NULL;
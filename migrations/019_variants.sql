@@ -0,0 +1,40 @@
+-- товар (product) объединяет варианты: размеры и цвета одной модели. вариант - строка items со своими
+-- ценой и остатком, покупки, корзины и промокоды по-прежнему ссылаются на вариант
+CREATE TABLE IF NOT EXISTS merch_shop.products (
+    name text PRIMARY KEY,
+    category text,
+    description text DEFAULT '' NOT NULL,
+    tags text[] DEFAULT '{}' NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_products_category
+    ON merch_shop.products USING btree (category);
+
+ALTER TABLE merch_shop.items ADD COLUMN IF NOT EXISTS product text REFERENCES merch_shop.products (name);
+ALTER TABLE merch_shop.items ADD COLUMN IF NOT EXISTS size text;
+ALTER TABLE merch_shop.items ADD COLUMN IF NOT EXISTS color text;
+
+-- товары, заведённые до появления вариантов, становятся отдельными товарами
+INSERT INTO merch_shop.products (name, category)
+    SELECT name, CASE WHEN name IN ('t-shirt', 'hoody', 'pink-hoody', 'socks') THEN 'clothing' ELSE 'accessories' END
+    FROM merch_shop.items WHERE product IS NULL
+    ON CONFLICT (name) DO NOTHING;
+UPDATE merch_shop.items SET product = name WHERE product IS NULL;
+
+-- вариант без указанного товара сам является товаром. срабатывает до проверки NOT NULL,
+-- поэтому INSERT ... ON CONFLICT DO NOTHING из 001_init не ломается
+CREATE OR REPLACE FUNCTION merch_shop.item_product() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.product := COALESCE(NEW.product, NEW.name);
+    INSERT INTO merch_shop.products (name) VALUES (NEW.product) ON CONFLICT (name) DO NOTHING;
+    RETURN NEW;
+END
+$$;
+DROP TRIGGER IF EXISTS item_product ON merch_shop.items;
+CREATE TRIGGER item_product BEFORE INSERT ON merch_shop.items
+    FOR EACH ROW EXECUTE FUNCTION merch_shop.item_product();
+
+ALTER TABLE merch_shop.items ALTER COLUMN product SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS items_variant
+    ON merch_shop.items USING btree (product, COALESCE(size, ''), COALESCE(color, ''));
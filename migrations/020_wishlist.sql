@@ -0,0 +1,23 @@
+-- список желаний. affordable и available - состояние на момент последней проверки,
+-- уведомление создаётся при переходе из false в true
+CREATE TABLE IF NOT EXISTS merch_shop.wishlist (
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    item text REFERENCES merch_shop.items (name) NOT NULL,
+    affordable boolean NOT NULL,
+    available boolean NOT NULL,
+    added_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (login, item)
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_wishlist_item
+    ON merch_shop.wishlist USING btree (item);
+
+CREATE TABLE IF NOT EXISTS merch_shop.notifications (
+    id bigserial PRIMARY KEY,
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    kind text CONSTRAINT known_kind CHECK (kind IN ('affordable', 'restocked')) NOT NULL,
+    item text REFERENCES merch_shop.items (name) NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    read_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_notifications_login
+    ON merch_shop.notifications USING btree (login, created_at);
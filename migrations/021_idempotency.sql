@@ -0,0 +1,11 @@
+-- ответы на запросы с Idempotency-Key. Ключ уникален в пределах пользователя,
+-- ответ сохраняется в той же транзакции, что и сама операция
+CREATE TABLE IF NOT EXISTS merch_shop.idempotency_keys (
+    login text REFERENCES merch_shop.auth (login) NOT NULL,
+    key text NOT NULL,
+    request_hash bytea NOT NULL,
+    status integer,
+    body bytea,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (login, key)
+);
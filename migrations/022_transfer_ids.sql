@@ -0,0 +1,12 @@
+-- у перевода появляется идентификатор и необязательный комментарий отправителя
+ALTER TABLE merch_shop.transfers ADD COLUMN IF NOT EXISTS id bigserial;
+ALTER TABLE merch_shop.transfers ADD COLUMN IF NOT EXISTS memo text;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'transfers_pkey' AND conrelid = 'merch_shop.transfers'::regclass
+    ) THEN
+        ALTER TABLE merch_shop.transfers ADD PRIMARY KEY (id);
+    END IF;
+END $$;
@@ -0,0 +1,42 @@
+-- постраничная история: страница продолжается с ключа (dt, id) последней строки,
+-- поэтому hash индексы по пользователю заменены составными
+-- даты истории хранятся как timestamptz: фильтры и курсоры не зависят от часового пояса сессии,
+-- старые значения записаны по часам сессии и так же переводятся при смене типа
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'merch_shop' AND table_name = 'transfers' AND column_name = 'dt'
+            AND data_type = 'timestamp without time zone'
+    ) THEN
+        ALTER TABLE merch_shop.transfers ALTER COLUMN dt TYPE timestamptz;
+    END IF;
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'merch_shop' AND table_name = 'purchases' AND column_name = 'dt'
+            AND data_type = 'timestamp without time zone'
+    ) THEN
+        ALTER TABLE merch_shop.purchases ALTER COLUMN dt TYPE timestamptz;
+    END IF;
+END $$;
+
+ALTER TABLE merch_shop.purchases ADD COLUMN IF NOT EXISTS id bigserial;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'purchases_pkey' AND conrelid = 'merch_shop.purchases'::regclass
+    ) THEN
+        ALTER TABLE merch_shop.purchases ADD PRIMARY KEY (id);
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS merch_shop.idx_merch_shop_transfers_from;
+DROP INDEX IF EXISTS merch_shop.idx_merch_shop_transfers_to;
+DROP INDEX IF EXISTS merch_shop.idx_merch_shop_purchases_user;
+CREATE INDEX IF NOT EXISTS idx_merch_shop_transfers_src
+    ON merch_shop.transfers USING btree (src, dt, id);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_transfers_dst
+    ON merch_shop.transfers USING btree (dst, dt, id);
+CREATE INDEX IF NOT EXISTS idx_merch_shop_purchases_name
+    ON merch_shop.purchases USING btree (name, dt, id);
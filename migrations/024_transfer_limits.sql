@@ -0,0 +1,11 @@
+-- ограничения переводов, заданные администратором для пользователя.
+-- NULL - действует значение из конфигурации, 0 - без ограничения
+CREATE TABLE IF NOT EXISTS merch_shop.transfer_limits (
+    login text PRIMARY KEY REFERENCES merch_shop.auth (login),
+    max_amount integer CONSTRAINT positive_max_amount CHECK (max_amount >= 0),
+    daily integer CONSTRAINT positive_daily CHECK (daily >= 0),
+    monthly integer CONSTRAINT positive_monthly CHECK (monthly >= 0),
+    counterparty_daily integer CONSTRAINT positive_counterparty_daily CHECK (counterparty_daily >= 0),
+    hourly_count integer CONSTRAINT positive_hourly_count CHECK (hourly_count >= 0),
+    updated_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -1,6 +1,27 @@
 package migrations
 
-import _ "embed"
+import (
+	"embed"
+	"io/fs"
+)
 
-//go:embed init.sql
+//go:embed *.sql
+var scripts embed.FS
+
+// Init holds all migration scripts concatenated in file name order.
 var Init []byte
+
+func init() {
+	names, err := fs.Glob(scripts, "*.sql")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range names {
+		b, err := scripts.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		Init = append(Init, b...)
+		Init = append(Init, '\n')
+	}
+}
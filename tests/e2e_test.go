@@ -80,10 +80,12 @@ func Test_E2E(t *testing.T) {
 	httpHost := "http://localhost:" + env["SERVER_PORT"]
 
 	mainUser := struct {
-		login string
-		passw string
-		Token string `json:"token"`
+		login        string
+		passw        string
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
 	}{login: "main", passw: "main"}
+	var rotatedRefresh string
 
 	testsChain := []struct {
 		name  string
@@ -106,8 +108,8 @@ func Test_E2E(t *testing.T) {
 
 				var i int
 				err = dbConn.QueryRow(ctx,
-					`SELECT count(*) FROM merch_shop.auth WHERE login=$1 AND password=SHA512($2) AND balance=1000`,
-					mainUser.login, mainUser.passw).
+					`SELECT count(*) FROM merch_shop.auth WHERE login=$1 AND password LIKE '$argon2id$%' AND balance=1000`,
+					mainUser.login).
 					Scan(&i)
 				require.NoError(t, err)
 				require.Equal(t, 1, i)
@@ -139,6 +141,58 @@ func Test_E2E(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 			},
 		},
+		{
+			name: "refresh-rotate",
+			rq: func() *http.Request {
+				rq, _ := http.NewRequest(http.MethodPost, httpHost+"/api/auth/refresh", bytes.NewBuffer([]byte(
+					`{"refreshToken":"`+mainUser.RefreshToken+`"}`,
+				)))
+				return rq
+			},
+			check: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+
+				rotated := struct {
+					Token        string `json:"token"`
+					RefreshToken string `json:"refreshToken"`
+				}{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&rotated))
+				require.NotEmpty(t, rotated.Token)
+				require.NotEqual(t, mainUser.RefreshToken, rotated.RefreshToken)
+				rotatedRefresh = rotated.RefreshToken
+			},
+		},
+		{
+			name: "refresh-reuse", // повторное использование старого токена
+			rq: func() *http.Request {
+				rq, _ := http.NewRequest(http.MethodPost, httpHost+"/api/auth/refresh", bytes.NewBuffer([]byte(
+					`{"refreshToken":"`+mainUser.RefreshToken+`"}`,
+				)))
+				return rq
+			},
+			check: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+				var active int
+				err = dbConn.QueryRow(ctx,
+					`SELECT count(*) FROM merch_shop.refresh_tokens WHERE login=$1 AND revoked_at IS NULL`,
+					mainUser.login).Scan(&active)
+				require.NoError(t, err)
+				require.Equal(t, 0, active) // вся цепочка отозвана
+			},
+		},
+		{
+			name: "refresh-after-reuse", // выданный ротацией токен тоже отозван
+			rq: func() *http.Request {
+				rq, _ := http.NewRequest(http.MethodPost, httpHost+"/api/auth/refresh", bytes.NewBuffer([]byte(
+					`{"refreshToken":"`+rotatedRefresh+`"}`,
+				)))
+				return rq
+			},
+			check: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
 		{
 			name: "master-buy-item",
 			rq: func() *http.Request {
@@ -178,6 +232,30 @@ func Test_E2E(t *testing.T) {
 				require.Equal(t, 1100, balance) // starter's 1000 + 100 for p2p
 			},
 		},
+		{
+			name: "master-logout",
+			rq: func() *http.Request {
+				rq, _ := http.NewRequest(http.MethodPost, httpHost+"/api/auth/logout", nil)
+				rq.Header.Add("Authorization", "Bearer "+mainUser.Token)
+
+				return rq
+			},
+			check: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "master-info-after-logout",
+			rq: func() *http.Request {
+				rq, _ := http.NewRequest(http.MethodGet, httpHost+"/api/info", nil)
+				rq.Header.Add("Authorization", "Bearer "+mainUser.Token)
+
+				return rq
+			},
+			check: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
 	}
 
 	sem := make(chan struct{}, 1) // strongly disallow parallel run
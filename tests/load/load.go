@@ -201,6 +201,8 @@ func loadP2P() {
 				rq, _ := http.NewRequest(http.MethodPost, host+"/api/sendCoin", bytes.NewBufferString(
 					`{"toUser":"`+to.login+`","amount":1}`))
 				rq.Header.Add("Authorization", "Bearer "+users[i].Token)
+				// по ключу перевод можно повторить после таймаута без повторного списания
+				rq.Header.Add("Idempotency-Key", uuid.NewString())
 
 				resp, err := client.Do(rq)
 				if err != nil {